@@ -0,0 +1,169 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DisruptionRecordSpec describes a disruption command that Karpenter decided to execute
+type DisruptionRecordSpec struct {
+	// Method is the disruption method that computed the command
+	// +required
+	Method string `json:"method"`
+	// ConsolidationType is the type of consolidation that computed the command, if the method is consolidation
+	// +optional
+	ConsolidationType string `json:"consolidationType,omitempty"`
+	// Action is the action that the command takes against its candidates
+	// +kubebuilder:validation:Enum:={delete,replace}
+	// +required
+	Action string `json:"action"`
+	// Candidates are the NodeClaims that are disrupted by the command
+	// +required
+	Candidates []DisruptionCandidate `json:"candidates"`
+	// Replacements are the NodeClaims that are launched by the command before its candidates are disrupted
+	// +optional
+	Replacements []DisruptionReplacement `json:"replacements,omitempty"`
+	// EstimatedSavings is the estimated reduction in hourly price from executing the command
+	// +optional
+	EstimatedSavings string `json:"estimatedSavings,omitempty"`
+}
+
+// DisruptionCandidate describes a NodeClaim that is disrupted by a command
+type DisruptionCandidate struct {
+	// Name is the name of the NodeClaim
+	// +required
+	Name string `json:"name"`
+	// NodeName is the name of the Node that the NodeClaim launched
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// NodePool is the name of the NodePool that owns the NodeClaim
+	// +optional
+	NodePool string `json:"nodePool,omitempty"`
+	// InstanceType is the instance type of the NodeClaim
+	// +optional
+	InstanceType string `json:"instanceType,omitempty"`
+	// CapacityType is the capacity type of the NodeClaim
+	// +optional
+	CapacityType string `json:"capacityType,omitempty"`
+	// Zone is the zone of the NodeClaim
+	// +optional
+	Zone string `json:"zone,omitempty"`
+	// Price is the hourly price of the NodeClaim's offering
+	// +optional
+	Price string `json:"price,omitempty"`
+}
+
+// DisruptionReplacement describes a NodeClaim that is launched by a command
+type DisruptionReplacement struct {
+	// NodePool is the name of the NodePool that the replacement is launched from
+	// +optional
+	NodePool string `json:"nodePool,omitempty"`
+	// InstanceTypes are the instance types that the replacement can launch as
+	// +optional
+	InstanceTypes []string `json:"instanceTypes,omitempty"`
+	// Price is the estimated hourly price of the replacement
+	// +optional
+	Price string `json:"price,omitempty"`
+}
+
+// DisruptionRecordStatus defines the observed state of a disruption command as it is orchestrated
+type DisruptionRecordStatus struct {
+	// Phase is the most recent orchestration phase of the command
+	// +optional
+	Phase DisruptionPhase `json:"phase,omitempty"`
+	// PhaseTransitions records the time at which the command entered each orchestration phase
+	// +optional
+	PhaseTransitions []DisruptionPhaseTransition `json:"phaseTransitions,omitempty"`
+	// ReplacementNodeClaims are the names of the NodeClaims that were created for the command's replacements
+	// +optional
+	ReplacementNodeClaims []string `json:"replacementNodeClaims,omitempty"`
+	// Message is a human-readable description of the outcome of the command
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// DisruptionPhaseTransition is the time at which a command entered an orchestration phase
+type DisruptionPhaseTransition struct {
+	// +required
+	Phase DisruptionPhase `json:"phase"`
+	// +required
+	Time metav1.Time `json:"time"`
+}
+
+// DisruptionPhase is an orchestration phase of a disruption command
+// +kubebuilder:validation:Enum:={Launching,Queued,Terminating,Succeeded,Failed}
+type DisruptionPhase string
+
+const (
+	// DisruptionPhaseLaunching is entered when the candidates are tainted and the replacements are launched
+	DisruptionPhaseLaunching DisruptionPhase = "Launching"
+	// DisruptionPhaseQueued is entered when the command is waiting in the orchestration queue for its replacements to initialize
+	DisruptionPhaseQueued DisruptionPhase = "Queued"
+	// DisruptionPhaseTerminating is entered when the replacements are initialized and the candidates are deleted
+	DisruptionPhaseTerminating DisruptionPhase = "Terminating"
+	// DisruptionPhaseSucceeded is entered when all candidates have been deleted
+	DisruptionPhaseSucceeded DisruptionPhase = "Succeeded"
+	// DisruptionPhaseFailed is entered when the command can't be completed and is abandoned
+	DisruptionPhaseFailed DisruptionPhase = "Failed"
+)
+
+// IsFinished returns true if the command has reached a terminal phase
+func (in *DisruptionRecord) IsFinished() bool {
+	return in.Status.Phase == DisruptionPhaseSucceeded || in.Status.Phase == DisruptionPhaseFailed
+}
+
+// CompletionTime returns the time at which the command reached a terminal phase, and false if it hasn't finished
+func (in *DisruptionRecord) CompletionTime() (time.Time, bool) {
+	if !in.IsFinished() {
+		return time.Time{}, false
+	}
+	transition, _, ok := lo.FindLastIndexOf(in.Status.PhaseTransitions, func(t DisruptionPhaseTransition) bool {
+		return t.Phase == in.Status.Phase
+	})
+	if !ok {
+		return time.Time{}, false
+	}
+	return transition.Time.Time, true
+}
+
+// DisruptionRecord is an audit record of a disruption command that Karpenter executed
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=disruptionrecords,scope=Cluster,categories=karpenter
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Method",type="string",JSONPath=".spec.method",description=""
+// +kubebuilder:printcolumn:name="Action",type="string",JSONPath=".spec.action",description=""
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description=""
+// +kubebuilder:printcolumn:name="Savings",type="string",JSONPath=".spec.estimatedSavings",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+// +kubebuilder:printcolumn:name="ConsolidationType",type="string",JSONPath=".spec.consolidationType",priority=1,description=""
+type DisruptionRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DisruptionRecordSpec   `json:"spec,omitempty"`
+	Status DisruptionRecordStatus `json:"status,omitempty"`
+}
+
+// DisruptionRecordList contains a list of DisruptionRecords
+// +kubebuilder:object:root=true
+type DisruptionRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DisruptionRecord `json:"items"`
+}
@@ -0,0 +1,69 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InstanceTypeOverrideSpec describes the adjustments that are made to the instance types reported by the
+// CloudProvider before they're used for scheduling and disruption decisions
+// +kubebuilder:validation:XValidation:message="must specify at least one of price, priceAdjustment or capacity",rule="has(self.price) || has(self.priceAdjustment) || has(self.capacity)"
+type InstanceTypeOverrideSpec struct {
+	// Requirements select the instance types that the override applies to. An instance type is selected if its
+	// requirements are compatible with every requirement. Requirements on the zone and capacity type keys
+	// further restrict the offerings of selected instance types whose price is overridden.
+	// +kubebuilder:validation:XValidation:message="requirements with operator 'In' must have a value defined",rule="self.all(x, x.operator == 'In' ? x.values.size() != 0 : true)"
+	// +kubebuilder:validation:XValidation:message="requirements operator 'Gt' or 'Lt' must have a single positive integer value",rule="self.all(x, (x.operator == 'Gt' || x.operator == 'Lt') ? (x.values.size() == 1 && int(x.values[0]) >= 0) : true)"
+	// +kubebuilder:validation:MaxItems:=30
+	// +required
+	Requirements []v1.NodeSelectorRequirement `json:"requirements"`
+	// Price replaces the hourly price of the selected offerings, e.g. "0.096"
+	// +kubebuilder:validation:Pattern:=`^\d+(\.\d+)?$`
+	// +optional
+	Price *string `json:"price,omitempty"`
+	// PriceAdjustment adjusts the hourly price of the selected offerings after any price override. It's either a
+	// percentage of the price, e.g. "-20%" for a negotiated discount, or an absolute amount, e.g. "-0.01". Prices are
+	// never adjusted below zero.
+	// +kubebuilder:validation:Pattern:=`^[+-]?\d+(\.\d+)?%?$`
+	// +optional
+	PriceAdjustment *string `json:"priceAdjustment,omitempty"`
+	// Capacity sets the capacity of the selected instance types for each listed resource. Resources that the
+	// CloudProvider doesn't report, e.g. extended resources advertised by device plugins, are added to the capacity.
+	// +optional
+	Capacity v1.ResourceList `json:"capacity,omitempty"`
+}
+
+// InstanceTypeOverride adjusts the price and capacity of the instance types that are reported by the CloudProvider
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=instancetypeoverrides,scope=Cluster,categories=karpenter
+// +kubebuilder:printcolumn:name="Price",type="string",JSONPath=".spec.price",description=""
+// +kubebuilder:printcolumn:name="Adjustment",type="string",JSONPath=".spec.priceAdjustment",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+type InstanceTypeOverride struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec InstanceTypeOverrideSpec `json:"spec,omitempty"`
+}
+
+// InstanceTypeOverrideList contains a list of InstanceTypeOverrides
+// +kubebuilder:object:root=true
+type InstanceTypeOverrideList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []InstanceTypeOverride `json:"items"`
+}
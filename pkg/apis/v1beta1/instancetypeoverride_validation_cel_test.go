@@ -0,0 +1,74 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"strings"
+
+	"github.com/Pallinder/go-randomdata"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/aws/karpenter-core/pkg/apis/v1beta1"
+)
+
+var _ = Describe("InstanceTypeOverride CEL Validation", func() {
+	var override *InstanceTypeOverride
+
+	BeforeEach(func() {
+		if env.Version.Minor() < 25 {
+			Skip("CEL Validation is for 1.25>")
+		}
+		override = &InstanceTypeOverride{
+			ObjectMeta: metav1.ObjectMeta{Name: strings.ToLower(randomdata.SillyName())},
+			Spec: InstanceTypeOverrideSpec{
+				Requirements: []v1.NodeSelectorRequirement{
+					{Key: v1.LabelInstanceTypeStable, Operator: v1.NodeSelectorOpIn, Values: []string{"m5.large"}},
+				},
+				Price: lo.ToPtr("0.05"),
+			},
+		}
+	})
+	It("should succeed with a price, price adjustment and capacity", func() {
+		override.Spec.PriceAdjustment = lo.ToPtr("-20%")
+		override.Spec.Capacity = v1.ResourceList{"example.com/device": resource.MustParse("1")}
+		Expect(env.Client.Create(ctx, override)).To(Succeed())
+	})
+	It("should succeed with an absolute price adjustment", func() {
+		override.Spec.Price = nil
+		override.Spec.PriceAdjustment = lo.ToPtr("-0.01")
+		Expect(env.Client.Create(ctx, override)).To(Succeed())
+	})
+	It("should fail without a price, price adjustment or capacity", func() {
+		override.Spec.Price = nil
+		Expect(env.Client.Create(ctx, override)).ToNot(Succeed())
+	})
+	It("should fail for an invalid price", func() {
+		override.Spec.Price = lo.ToPtr("-1")
+		Expect(env.Client.Create(ctx, override)).ToNot(Succeed())
+	})
+	It("should fail for an invalid price adjustment", func() {
+		override.Spec.PriceAdjustment = lo.ToPtr("20%%")
+		Expect(env.Client.Create(ctx, override)).ToNot(Succeed())
+	})
+	It("should fail for requirements with operator 'In' and no values", func() {
+		override.Spec.Requirements = []v1.NodeSelectorRequirement{{Key: v1.LabelInstanceTypeStable, Operator: v1.NodeSelectorOpIn}}
+		Expect(env.Client.Create(ctx, override)).ToNot(Succeed())
+	})
+})
@@ -28,6 +28,8 @@ const (
 	ArchitectureArm64    = "arm64"
 	CapacityTypeSpot     = "spot"
 	CapacityTypeOnDemand = "on-demand"
+	// CapacityTypeReserved is the capacity type of offerings that launch into pre-purchased capacity reservations
+	CapacityTypeReserved = "reserved"
 )
 
 // Karpenter specific domains and labels
@@ -44,6 +46,9 @@ const (
 	ProviderCompatabilityAnnotationKey = CompatabilityGroup + "/provider"
 	ManagedByAnnotationKey             = Group + "/managed-by"
 	NodePoolHashAnnotationKey          = Group + "/nodepool-hash"
+	DisruptionCommandAnnotationKey     = Group + "/disruption-command"
+	SchedulingDecisionAnnotationKey    = Group + "/scheduling-decision"
+	HeadroomAnnotationKey              = Group + "/headroom"
 )
 
 // Karpenter specific finalizers
@@ -34,9 +34,10 @@ type NodeClaimSpec struct {
 	// Requirements are layered with GetLabels and applied to every node.
 	// +kubebuilder:validation:XValidation:message="requirements with operator 'In' must have a value defined",rule="self.all(x, x.operator == 'In' ? x.values.size() != 0 : true)"
 	// +kubebuilder:validation:XValidation:message="requirements operator 'Gt' or 'Lt' must have a single positive integer value",rule="self.all(x, (x.operator == 'Gt' || x.operator == 'Lt') ? (x.values.size() == 1 && int(x.values[0]) >= 0) : true)"
+	// +kubebuilder:validation:XValidation:message="requirements with 'minValues' must have at least that many values specified in the 'values' field",rule="self.all(x, (x.operator == 'In' && has(x.minValues)) ? x.values.size() >= x.minValues : true)"
 	// +kubebuilder:validation:MaxItems:=30
 	// +required
-	Requirements []v1.NodeSelectorRequirement `json:"requirements" hash:"ignore"`
+	Requirements []NodeSelectorRequirementWithMinValues `json:"requirements" hash:"ignore"`
 	// Resources models the resource requirements for the NodeClaim to launch
 	// +optional
 	Resources ResourceRequirements `json:"resources,omitempty" hash:"ignore"`
@@ -52,6 +53,19 @@ type NodeClaimSpec struct {
 	NodeClassRef *NodeClassReference `json:"nodeClassRef"`
 }
 
+// NodeSelectorRequirementWithMinValues is a NodeSelectorRequirement that can also require a minimum amount of
+// flexibility in the values that remain available for its key
+type NodeSelectorRequirementWithMinValues struct {
+	v1.NodeSelectorRequirement `json:",inline"`
+	// MinValues is the minimum number of unique values of the key that must remain available when choosing the instance
+	// types that a NodeClaim can launch as. For example, a minValues of 5 on node.kubernetes.io/instance-type requires
+	// that a NodeClaim can launch as at least 5 different instance types.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=50
+	// +optional
+	MinValues *int `json:"minValues,omitempty"`
+}
+
 // ResourceRequirements models the required resources for the NodeClaim to launch
 // Ths will eventually be transformed into v1.ResourceRequirements when we support resources.limits
 type ResourceRequirements struct {
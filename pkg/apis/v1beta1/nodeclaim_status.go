@@ -62,6 +62,7 @@ var (
 	Empty       apis.ConditionType = "Empty"
 	Drifted     apis.ConditionType = "Drifted"
 	Expired     apis.ConditionType = "Expired"
+	Unhealthy   apis.ConditionType = "Unhealthy"
 )
 
 func (in *NodeClaim) GetConditions() apis.Conditions {
@@ -132,13 +132,29 @@ func (in *NodeClaimSpec) validateTaintsField(taints []v1.Taint, existing map[tai
 // NodeClaim requirements only support well known labels.
 func (in *NodeClaimSpec) validateRequirements() (errs *apis.FieldError) {
 	for i, requirement := range in.Requirements {
-		if err := ValidateRequirement(requirement); err != nil {
+		if err := ValidateRequirement(requirement.NodeSelectorRequirement); err != nil {
+			errs = errs.Also(apis.ErrInvalidArrayValue(err, "requirements", i))
+		}
+		if err := validateMinValues(requirement); err != nil {
 			errs = errs.Also(apis.ErrInvalidArrayValue(err, "requirements", i))
 		}
 	}
 	return errs
 }
 
+func validateMinValues(requirement NodeSelectorRequirementWithMinValues) error {
+	if requirement.MinValues == nil {
+		return nil
+	}
+	if *requirement.MinValues < 1 {
+		return fmt.Errorf("key %s with minValues %d must have minValues greater than 0", requirement.Key, *requirement.MinValues)
+	}
+	if requirement.Operator == v1.NodeSelectorOpIn && len(requirement.Values) < *requirement.MinValues {
+		return fmt.Errorf("key %s with operator %s must have at least minValues %d values defined", requirement.Key, requirement.Operator, *requirement.MinValues)
+	}
+	return nil
+}
+
 func ValidateRequirement(requirement v1.NodeSelectorRequirement) error { //nolint:gocyclo
 	var errs error
 	if normalized, ok := NormalizedLabels[requirement.Key]; ok {
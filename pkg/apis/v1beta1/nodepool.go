@@ -15,13 +15,20 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"sort"
+	"strconv"
 
 	"github.com/mitchellh/hashstructure/v2"
+	"github.com/robfig/cron/v3"
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/clock"
+	"knative.dev/pkg/logging"
 	"knative.dev/pkg/ptr"
 )
 
@@ -35,7 +42,7 @@ type NodePoolSpec struct {
 	// +required
 	Template NodeClaimTemplate `json:"template"`
 	// Disruption contains the parameters that relate to Karpenter's disruption logic
-	// +kubebuilder:default={"consolidationPolicy": "WhenUnderutilized", "expireAfter": "720h"}
+	// +kubebuilder:default={"consolidationPolicy": "WhenUnderutilized", "expireAfter": "720h", "budgets": {{"maxUnavailable": "10%"}}}
 	// +kubebuilder:validation:XValidation:message="consolidateAfter cannot be combined with consolidationPolicy=WhenUnderutilized",rule="has(self.consolidateAfter) ? self.consolidationPolicy != 'WhenUnderutilized' || self.consolidateAfter == 'Never' : true"
 	// +kubebuilder:validation:XValidation:message="consolidateAfter must be specified with consolidationPolicy=WhenEmpty",rule="self.consolidationPolicy == 'WhenEmpty' ? has(self.consolidateAfter) : true"
 	// +optional
@@ -51,6 +58,40 @@ type NodePoolSpec struct {
 	// +kubebuilder:validation:Maximum:=100
 	// +optional
 	Weight *int32 `json:"weight,omitempty"`
+	// BinPackingStrategy is the strategy that the scheduler uses to pack pods onto the
+	// nodes of the nodepool. If left undefined, the operator-wide strategy is used.
+	// +kubebuilder:validation:Enum:={FirstFitDecreasing,BestFit,CostAware}
+	// +optional
+	BinPackingStrategy BinPackingStrategy `json:"binPackingStrategy,omitempty"`
+	// Headroom is spare capacity that is kept available on the nodes of the nodepool
+	// so that pods can schedule without waiting for nodes to launch. The headroom is
+	// scheduled like pending pods that can only schedule to the nodepool's nodes, and
+	// consolidation doesn't disrupt nodes if it would eat into the headroom.
+	// +optional
+	Headroom *Headroom `json:"headroom,omitempty"`
+}
+
+// Headroom is spare capacity that is kept available on the nodes of a NodePool.
+// If both resources and pods are set, capacity is kept available for both.
+type Headroom struct {
+	// Resources is capacity that is kept free on a single node, like 4 cpu and 16Gi of memory.
+	// +optional
+	Resources v1.ResourceList `json:"resources,omitempty"`
+	// Pods keeps capacity available for a number of pods with the same resource requests.
+	// +optional
+	Pods *HeadroomPods `json:"pods,omitempty"`
+}
+
+type HeadroomPods struct {
+	// Count is the number of pods to keep capacity available for.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=1000
+	// +required
+	Count int32 `json:"count"`
+	// Requests are the resources that each of the pods requests.
+	// +kubebuilder:validation:MinProperties:=1
+	// +required
+	Requests v1.ResourceList `json:"requests"`
 }
 
 type Disruption struct {
@@ -68,6 +109,11 @@ type Disruption struct {
 	// +kubebuilder:validation:Enum:={WhenEmpty,WhenUnderutilized}
 	// +optional
 	ConsolidationPolicy ConsolidationPolicy `json:"consolidationPolicy,omitempty"`
+	// DryRun computes the disruption decisions for the nodepool's nodes without executing them.
+	// The decisions are published as events, logs and metrics instead. If left undefined,
+	// the operator-wide setting is used.
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty" hash:"ignore"`
 	// ExpireAfter is the duration the controller will wait
 	// before terminating a node, measured from when the node is created. This
 	// is useful to implement features like eventually consistent node upgrade,
@@ -78,6 +124,55 @@ type Disruption struct {
 	// +kubebuilder:validation:Schemaless
 	// +optional
 	ExpireAfter NillableDuration `json:"expireAfter"`
+	// TerminationGracePeriod is the maximum duration the controller will wait after a node has expired
+	// before forcefully terminating it. Until then, the node is only disrupted gracefully. Once this period
+	// has elapsed, the node is drained regardless of disruption budgets, PodDisruptionBudgets and the
+	// karpenter.sh/do-not-disrupt annotation. While an expired node is draining, its pods are deleted early
+	// enough to receive their full terminationGracePeriodSeconds before the period elapses.
+	// If left undefined, expired nodes are only ever disrupted gracefully.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	TerminationGracePeriod *metav1.Duration `json:"terminationGracePeriod,omitempty"`
+	// Budgets is a list of Budgets.
+	// If there are multiple active budgets, Karpenter uses
+	// the most restrictive maxUnavailable. If left undefined,
+	// this will default to one budget with a maxUnavailable to 10%.
+	// +kubebuilder:validation:XValidation:message="'crontab' must be set with 'duration'",rule="self.all(x, has(x.crontab) == has(x.duration))"
+	// +kubebuilder:default:={{maxUnavailable: "10%"}}
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	Budgets []Budget `json:"budgets,omitempty" hash:"ignore"`
+}
+
+// Budget defines when Karpenter will restrict the
+// number of Node Claims that can be terminating simultaneously.
+type Budget struct {
+	// MaxUnavailable dictates how many NodeClaims owned by this NodePool
+	// can be terminating at once. It must be set.
+	// NodeClaims that are already being disrupted count towards this limit.
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Pattern:=`^((100|[0-9]{1,2})%|[0-9]+)$`
+	// +kubebuilder:default:="10%"
+	MaxUnavailable intstr.IntOrString `json:"maxUnavailable" hash:"ignore"`
+	// Crontab specifies when a budget begins being active,
+	// using the upstream cronjob syntax. If omitted, the budget is always active.
+	// Currently timezones are not supported.
+	// This is required if Duration is set.
+	// +kubebuilder:validation:Pattern:=`^(@(annually|yearly|monthly|weekly|daily|midnight|hourly))|((.+)\s(.+)\s(.+)\s(.+)\s(.+))$`
+	// +optional
+	Crontab *string `json:"crontab,omitempty" hash:"ignore"`
+	// Duration determines how long a Budget is active since each Crontab hit.
+	// Only minutes and hours are accepted, as cron does not work in seconds.
+	// If omitted, the budget is always active.
+	// This is required if Crontab is set.
+	// This regex has an optional 0s at the end since the duration.String() always adds
+	// a 0s at the end.
+	// +kubebuilder:validation:Pattern=`^((([0-9]+(h|m))|([0-9]+h[0-9]+m))(0s)?)$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty" hash:"ignore"`
 }
 
 type ConsolidationPolicy string
@@ -87,6 +182,20 @@ const (
 	ConsolidationPolicyWhenUnderutilized ConsolidationPolicy = "WhenUnderutilized"
 )
 
+type BinPackingStrategy string
+
+const (
+	BinPackingStrategyFirstFitDecreasing BinPackingStrategy = "FirstFitDecreasing"
+	BinPackingStrategyBestFit            BinPackingStrategy = "BestFit"
+	BinPackingStrategyCostAware          BinPackingStrategy = "CostAware"
+)
+
+var BinPackingStrategies = []BinPackingStrategy{
+	BinPackingStrategyFirstFitDecreasing,
+	BinPackingStrategyBestFit,
+	BinPackingStrategyCostAware,
+}
+
 type Limits v1.ResourceList
 
 func (l Limits) ExceededBy(resources v1.ResourceList) error {
@@ -162,3 +271,68 @@ func (pl *NodePoolList) OrderByWeight() {
 		return ptr.Int32Value(pl.Items[a].Spec.Weight) > ptr.Int32Value(pl.Items[b].Spec.Weight)
 	})
 }
+
+// GetAllowedDisruptions returns the minimum allowed disruptions across all active
+// disruption budgets for a NodePool. It returns an error if any of the budgets
+// are misconfigured, treating the budget as if it allowed no disruptions.
+func (in *NodePool) GetAllowedDisruptions(ctx context.Context, c clock.Clock, numNodes int) (int, error) {
+	minVal := math.MaxInt32
+	for i := range in.Spec.Disruption.Budgets {
+		val, err := in.Spec.Disruption.Budgets[i].GetAllowedDisruptions(c, numNodes)
+		if err != nil {
+			logging.FromContext(ctx).With("nodepool", in.Name).Errorf("parsing disruption budget, %s", err)
+			return 0, err
+		}
+		minVal = lo.Ternary(val < minVal, val, minVal)
+	}
+	return minVal, nil
+}
+
+// GetAllowedDisruptions returns an intstr.IntOrString that can be used a comparison
+// for calculating if a disruption action is allowed. It returns an error if the
+// schedule is invalid. This returns MAXINT if the budget is not active.
+func (in *Budget) GetAllowedDisruptions(c clock.Clock, numNodes int) (int, error) {
+	active, err := in.IsActive(c)
+	if err != nil {
+		return 0, err
+	}
+	if !active {
+		return math.MaxInt32, nil
+	}
+	// This will round up to the nearest whole number. Therefore, a disruption can
+	// sometimes exceed the disruption budget. This is the same as how Kubernetes
+	// handles MaxUnavailable with PDBs. Take the case with 5% disruptions, but
+	// 10 nodes. Karpenter will calculate 10*5% = 0.5, and rounding up will allow 1 disruption.
+	return intstr.GetScaledValueFromIntOrPercent(lo.ToPtr(getIntStrFromValue(in.MaxUnavailable.String())), numNodes, true)
+}
+
+// IsActive takes a clock as input and returns if a budget is active.
+// It walks back in time the duration of the budget's duration,
+// and then checks if the next hit of the schedule is before the current time.
+// If the budget has no schedule and no duration, it is always active.
+func (in *Budget) IsActive(c clock.Clock) (bool, error) {
+	if in.Crontab == nil && in.Duration == nil {
+		return true, nil
+	}
+	if in.Crontab == nil || in.Duration == nil {
+		return false, fmt.Errorf("crontab and duration must be set together")
+	}
+	schedule, err := cron.ParseStandard(lo.FromPtr(in.Crontab))
+	if err != nil {
+		return false, fmt.Errorf("invalid crontab, %w", err)
+	}
+	// Walk back in time for the duration associated with the schedule
+	checkPoint := c.Now().UTC().Add(-lo.FromPtr(in.Duration).Duration)
+	nextHit := schedule.Next(checkPoint)
+	return !nextHit.After(c.Now().UTC()), nil
+}
+
+// getIntStrFromValue converts a string value to an intstr.IntOrString, preferring
+// an integer representation when the value can be parsed as one.
+func getIntStrFromValue(str string) intstr.IntOrString {
+	// If err is nil, we treat it as an int.
+	if intVal, err := strconv.Atoi(str); err == nil {
+		return intstr.FromInt(intVal)
+	}
+	return intstr.FromString(str)
+}
@@ -0,0 +1,157 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"math"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clock "k8s.io/utils/clock/testing"
+
+	. "github.com/aws/karpenter-core/pkg/apis/v1beta1"
+)
+
+var _ = Describe("Budgets", func() {
+	var nodePool *NodePool
+	var budgets []Budget
+	var fakeClock *clock.FakeClock
+
+	BeforeEach(func() {
+		// Set the time to the middle of the year of 2000, the best year ever
+		fakeClock = clock.NewFakeClock(time.Date(2000, time.June, 15, 12, 30, 30, 0, time.UTC))
+		budgets = []Budget{
+			{
+				MaxUnavailable: intstr.FromInt(10),
+				Crontab:        lo.ToPtr("* * * * *"),
+				Duration:       lo.ToPtr(metav1.Duration{Duration: lo.Must(time.ParseDuration("1h"))}),
+			},
+			{
+				MaxUnavailable: intstr.FromString("100%"),
+				Crontab:        lo.ToPtr("* * * * *"),
+				Duration:       lo.ToPtr(metav1.Duration{Duration: lo.Must(time.ParseDuration("1h"))}),
+			},
+			{
+				MaxUnavailable: intstr.FromString("50%"),
+				Crontab:        lo.ToPtr("* * * * *"),
+				Duration:       lo.ToPtr(metav1.Duration{Duration: lo.Must(time.ParseDuration("1h"))}),
+			},
+		}
+		nodePool = &NodePool{
+			Spec: NodePoolSpec{
+				Disruption: Disruption{
+					Budgets: budgets,
+				},
+			},
+		}
+	})
+
+	Context("GetAllowedDisruptions", func() {
+		It("should return zero values if a schedule is invalid", func() {
+			budgets[0].Crontab = lo.ToPtr("@wrongly")
+			val, err := nodePool.GetAllowedDisruptions(ctx, fakeClock, 100)
+			Expect(err).ToNot(BeNil())
+			Expect(val).To(BeNumerically("==", 0))
+		})
+		It("should return MaxInt32 when a budget is inactive", func() {
+			budgets[0].Crontab = lo.ToPtr("@yearly")
+			budgets[0].Duration = lo.ToPtr(metav1.Duration{Duration: lo.Must(time.ParseDuration("1h"))})
+			val, err := budgets[0].GetAllowedDisruptions(fakeClock, 100)
+			Expect(err).To(BeNil())
+			Expect(val).To(BeNumerically("==", math.MaxInt32))
+		})
+		It("should return MaxInt32 when there are no budgets", func() {
+			nodePool.Spec.Disruption.Budgets = nil
+			val, err := nodePool.GetAllowedDisruptions(ctx, fakeClock, 100)
+			Expect(err).To(BeNil())
+			Expect(val).To(BeNumerically("==", math.MaxInt32))
+		})
+		It("should ignore budgets that are inactive", func() {
+			for i := range budgets {
+				budgets[i].Crontab = lo.ToPtr("@yearly")
+			}
+			val, err := nodePool.GetAllowedDisruptions(ctx, fakeClock, 100)
+			Expect(err).To(BeNil())
+			Expect(val).To(BeNumerically("==", math.MaxInt32))
+		})
+		It("should return the minimum allowed disruptions across all active budgets", func() {
+			val, err := nodePool.GetAllowedDisruptions(ctx, fakeClock, 100)
+			Expect(err).To(BeNil())
+			Expect(val).To(BeNumerically("==", 10))
+		})
+		It("should round up percentages", func() {
+			budgets[2].MaxUnavailable = intstr.FromString("5%")
+			val, err := nodePool.GetAllowedDisruptions(ctx, fakeClock, 10)
+			Expect(err).To(BeNil())
+			Expect(val).To(BeNumerically("==", 1))
+		})
+		It("should treat a string integer as an integer", func() {
+			budgets[0].MaxUnavailable = intstr.FromString("3")
+			val, err := nodePool.GetAllowedDisruptions(ctx, fakeClock, 100)
+			Expect(err).To(BeNil())
+			Expect(val).To(BeNumerically("==", 3))
+		})
+	})
+	Context("IsActive", func() {
+		It("should always consider a schedule and time in UTC", func() {
+			// Set the time to start of June 2000 in a time zone 1 hour ahead of UTC
+			fakeClock = clock.NewFakeClock(time.Date(2000, time.June, 0, 0, 0, 0, 0, time.FixedZone("fake-zone", 3600)))
+			budgets[0].Crontab = lo.ToPtr("@daily")
+			budgets[0].Duration = lo.ToPtr(metav1.Duration{Duration: lo.Must(time.ParseDuration("30m"))})
+			// IsActive should use UTC, not the location of the clock that's inputted.
+			active, err := budgets[0].IsActive(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(active).To(BeFalse())
+		})
+		It("should return that a schedule is active", func() {
+			active, err := budgets[0].IsActive(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(active).To(BeTrue())
+		})
+		It("should return that a schedule is inactive", func() {
+			budgets[0].Crontab = lo.ToPtr("@yearly")
+			active, err := budgets[0].IsActive(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(active).To(BeFalse())
+		})
+		It("should return that a schedule is active when the schedule hit is in the middle of the duration", func() {
+			// Set the date to the start of the year 1000, the best year ever
+			fakeClock = clock.NewFakeClock(time.Date(1000, time.January, 1, 12, 0, 0, 0, time.UTC))
+			budgets[0].Crontab = lo.ToPtr("@yearly")
+			budgets[0].Duration = lo.ToPtr(metav1.Duration{Duration: lo.Must(time.ParseDuration("24h"))})
+			active, err := budgets[0].IsActive(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(active).To(BeTrue())
+		})
+		It("should return that a schedule is active when the duration is longer than the recurrence", func() {
+			budgets[0].Crontab = lo.ToPtr("@daily")
+			budgets[0].Duration = lo.ToPtr(metav1.Duration{Duration: lo.Must(time.ParseDuration("48h"))})
+			active, err := budgets[0].IsActive(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(active).To(BeTrue())
+		})
+		It("should always be active when there is no schedule and no duration", func() {
+			budgets[0].Crontab = nil
+			budgets[0].Duration = nil
+			active, err := budgets[0].IsActive(fakeClock)
+			Expect(err).To(BeNil())
+			Expect(active).To(BeTrue())
+		})
+	})
+})
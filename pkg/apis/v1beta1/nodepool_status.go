@@ -16,6 +16,7 @@ package v1beta1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
 )
 
 // NodePoolStatus defines the observed state of NodePool
@@ -23,4 +24,25 @@ type NodePoolStatus struct {
 	// Resources is the list of resources that have been provisioned.
 	// +optional
 	Resources v1.ResourceList `json:"resources,omitempty"`
+	// Conditions contains signals for the state of the NodePool
+	// +optional
+	Conditions apis.Conditions `json:"conditions,omitempty"`
+}
+
+func (in *NodePool) StatusConditions() apis.ConditionManager {
+	return apis.NewLivingConditionSet(
+		Launchable,
+	).Manage(in)
+}
+
+// Launchable is false when the CloudProvider rejects the NodePool's NodeClaims because of their configuration or
+// the CloudProvider's permissions, so launches won't succeed until the NodePool, its NodeClass or the permissions change
+var Launchable apis.ConditionType = "Launchable"
+
+func (in *NodePool) GetConditions() apis.Conditions {
+	return in.Status.Conditions
+}
+
+func (in *NodePool) SetConditions(conditions apis.Conditions) {
+	in.Status.Conditions = conditions
 }
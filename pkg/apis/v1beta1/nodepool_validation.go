@@ -17,12 +17,18 @@ package v1beta1
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"time"
 
+	"github.com/robfig/cron/v3"
+	"github.com/samber/lo"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
+var maxUnavailableRegex = regexp.MustCompile(`^((100|[0-9]{1,2})%|[0-9]+)$`)
+
 func (in *NodePool) SupportedVerbs() []admissionregistrationv1.OperationType {
 	return []admissionregistrationv1.OperationType{
 		admissionregistrationv1.Create,
@@ -51,9 +57,17 @@ func (in *NodePoolSpec) validate() (errs *apis.FieldError) {
 	return errs.Also(
 		in.Template.validate().ViaField("template"),
 		in.Disruption.validate().ViaField("deprovisioning"),
+		in.validateBinPackingStrategy(),
 	)
 }
 
+func (in *NodePoolSpec) validateBinPackingStrategy() (errs *apis.FieldError) {
+	if in.BinPackingStrategy != "" && !lo.Contains(BinPackingStrategies, in.BinPackingStrategy) {
+		errs = errs.Also(apis.ErrInvalidValue(in.BinPackingStrategy, "binPackingStrategy", fmt.Sprintf("must be one of %v", BinPackingStrategies)))
+	}
+	return errs
+}
+
 func (in *NodeClaimTemplate) validate() (errs *apis.FieldError) {
 	if len(in.Spec.Resources.Requests) > 0 {
 		errs = errs.Also(apis.ErrDisallowedFields("resources.requests"))
@@ -99,11 +113,39 @@ func (in *Disruption) validate() (errs *apis.FieldError) {
 	if in.ConsolidateAfter != nil && in.ConsolidateAfter.Duration != nil && *in.ConsolidateAfter.Duration < 0 {
 		return errs.Also(apis.ErrInvalidValue("cannot be negative", "consolidationTTL"))
 	}
+	if in.TerminationGracePeriod != nil && in.TerminationGracePeriod.Duration < 0 {
+		return errs.Also(apis.ErrInvalidValue("cannot be negative", "terminationGracePeriod"))
+	}
 	if in.ConsolidateAfter != nil && in.ConsolidateAfter.Duration != nil && in.ConsolidationPolicy == ConsolidationPolicyWhenUnderutilized {
 		return errs.Also(apis.ErrGeneric("consolidateAfter cannot be combined with consolidationPolicy=WhenUnderutilized"))
 	}
 	if in.ConsolidateAfter == nil && in.ConsolidationPolicy == ConsolidationPolicyWhenEmpty {
 		return errs.Also(apis.ErrGeneric("consolidateAfter must be specified with consolidationPolicy=WhenEmpty"))
 	}
+	for i := range in.Budgets {
+		errs = errs.Also(in.Budgets[i].validate().ViaFieldIndex("budgets", i))
+	}
+	return errs
+}
+
+func (in *Budget) validate() (errs *apis.FieldError) {
+	if !maxUnavailableRegex.MatchString(in.MaxUnavailable.String()) {
+		errs = errs.Also(apis.ErrInvalidValue(in.MaxUnavailable.String(), "maxUnavailable", "must be a non-negative integer or a percentage between 0% and 100%"))
+	}
+	if (in.Crontab == nil) != (in.Duration == nil) {
+		errs = errs.Also(apis.ErrGeneric("'crontab' must be set with 'duration'"))
+	}
+	if in.Crontab != nil {
+		if _, err := cron.ParseStandard(*in.Crontab); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(*in.Crontab, "crontab", err.Error()))
+		}
+	}
+	if in.Duration != nil {
+		if in.Duration.Duration <= 0 {
+			errs = errs.Also(apis.ErrInvalidValue(in.Duration.Duration.String(), "duration", "must be positive"))
+		} else if in.Duration.Duration%time.Minute != 0 {
+			errs = errs.Also(apis.ErrInvalidValue(in.Duration.Duration.String(), "duration", "must be a whole number of minutes"))
+		}
+	}
 	return errs
 }
@@ -26,6 +26,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/ptr"
 
@@ -63,6 +64,14 @@ var _ = Describe("Webhook/Validation", func() {
 			nodePool.Spec.Disruption.ExpireAfter.Duration = lo.ToPtr(lo.Must(time.ParseDuration("30s")))
 			Expect(nodePool.Validate(ctx)).To(Succeed())
 		})
+		It("should fail on negative terminationGracePeriod", func() {
+			nodePool.Spec.Disruption.TerminationGracePeriod = &metav1.Duration{Duration: lo.Must(time.ParseDuration("-1s"))}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+		It("should succeed on a valid terminationGracePeriod", func() {
+			nodePool.Spec.Disruption.TerminationGracePeriod = &metav1.Duration{Duration: lo.Must(time.ParseDuration("1h"))}
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
 		It("should fail on negative consolidateAfter", func() {
 			nodePool.Spec.Disruption.ConsolidateAfter = &NillableDuration{Duration: lo.ToPtr(lo.Must(time.ParseDuration("-1s")))}
 			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
@@ -85,6 +94,81 @@ var _ = Describe("Webhook/Validation", func() {
 			nodePool.Spec.Disruption.ConsolidationPolicy = ConsolidationPolicyWhenUnderutilized
 			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
 		})
+		It("should succeed with a budget with an integer maxUnavailable", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{MaxUnavailable: intstr.FromInt(5)}}
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		It("should succeed with a budget with a percentage maxUnavailable", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{MaxUnavailable: intstr.FromString("10%")}}
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		It("should fail with a budget with a negative maxUnavailable", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{MaxUnavailable: intstr.FromInt(-1)}}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+		It("should fail with a budget with a percentage maxUnavailable above 100%", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{MaxUnavailable: intstr.FromString("101%")}}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+		It("should succeed with a budget with a crontab and duration", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				MaxUnavailable: intstr.FromInt(0),
+				Crontab:        lo.ToPtr("0 9 * * 1-5"),
+				Duration:       &metav1.Duration{Duration: 8 * time.Hour},
+			}}
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		It("should succeed with a budget with a crontab macro", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				MaxUnavailable: intstr.FromInt(0),
+				Crontab:        lo.ToPtr("@daily"),
+				Duration:       &metav1.Duration{Duration: time.Hour},
+			}}
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		It("should fail with a budget with a crontab but no duration", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				MaxUnavailable: intstr.FromInt(0),
+				Crontab:        lo.ToPtr("@daily"),
+			}}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+		It("should fail with a budget with a duration but no crontab", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				MaxUnavailable: intstr.FromInt(0),
+				Duration:       &metav1.Duration{Duration: time.Hour},
+			}}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+		It("should fail with a budget with an invalid crontab", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				MaxUnavailable: intstr.FromInt(0),
+				Crontab:        lo.ToPtr("* * * *"),
+				Duration:       &metav1.Duration{Duration: time.Hour},
+			}}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+		It("should fail with a budget with a duration in seconds", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				MaxUnavailable: intstr.FromInt(0),
+				Crontab:        lo.ToPtr("@daily"),
+				Duration:       &metav1.Duration{Duration: 90 * time.Second},
+			}}
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
+	})
+	Context("BinPackingStrategy", func() {
+		It("should succeed without a bin-packing strategy", func() {
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		It("should succeed with a valid bin-packing strategy", func() {
+			nodePool.Spec.BinPackingStrategy = BinPackingStrategyCostAware
+			Expect(nodePool.Validate(ctx)).To(Succeed())
+		})
+		It("should fail with an unknown bin-packing strategy", func() {
+			nodePool.Spec.BinPackingStrategy = "WorstFit"
+			Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+		})
 	})
 	Context("Limits", func() {
 		It("should allow undefined limits", func() {
@@ -216,70 +300,70 @@ var _ = Describe("Webhook/Validation", func() {
 		})
 		Context("Requirements", func() {
 			It("should fail for the karpenter.sh/nodepool label", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: NodePoolLabelKey, Operator: v1.NodeSelectorOpIn, Values: []string{randomdata.SillyName()}},
+				nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: NodePoolLabelKey, Operator: v1.NodeSelectorOpIn, Values: []string{randomdata.SillyName()}}},
 				}
 				Expect(nodePool.Validate(ctx)).ToNot(Succeed())
 			})
 			It("should allow supported ops", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test"}},
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpGt, Values: []string{"1"}},
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpLt, Values: []string{"1"}},
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpNotIn},
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpExists},
+				nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test"}}},
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpGt, Values: []string{"1"}}},
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpLt, Values: []string{"1"}}},
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpNotIn}},
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpExists}},
 				}
 				Expect(nodePool.Validate(ctx)).To(Succeed())
 			})
 			It("should fail for unsupported ops", func() {
 				for _, op := range []v1.NodeSelectorOperator{"unknown"} {
-					nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-						{Key: v1.LabelTopologyZone, Operator: op, Values: []string{"test"}},
+					nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+						{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: op, Values: []string{"test"}}},
 					}
 					Expect(nodePool.Validate(ctx)).ToNot(Succeed())
 				}
 			})
 			It("should fail for restricted domains", func() {
 				for label := range RestrictedLabelDomains {
-					nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-						{Key: label + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test"}},
+					nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+						{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: label + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test"}}},
 					}
 					Expect(nodePool.Validate(ctx)).ToNot(Succeed())
 				}
 			})
 			It("should allow restricted domains exceptions", func() {
 				for label := range LabelDomainExceptions {
-					nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-						{Key: label + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test"}},
+					nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+						{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: label + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test"}}},
 					}
 					Expect(nodePool.Validate(ctx)).To(Succeed())
 				}
 			})
 			It("should allow restricted subdomains exceptions", func() {
 				for label := range LabelDomainExceptions {
-					nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-						{Key: "subdomain." + label + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test"}},
+					nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+						{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "subdomain." + label + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test"}}},
 					}
 					Expect(nodePool.Validate(ctx)).To(Succeed())
 				}
 			})
 			It("should allow well known label exceptions", func() {
 				for label := range WellKnownLabels.Difference(sets.New(NodePoolLabelKey)) {
-					nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-						{Key: label, Operator: v1.NodeSelectorOpIn, Values: []string{"test"}},
+					nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+						{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: label, Operator: v1.NodeSelectorOpIn, Values: []string{"test"}}},
 					}
 					Expect(nodePool.Validate(ctx)).To(Succeed())
 				}
 			})
 			It("should allow non-empty set after removing overlapped value", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test", "foo"}},
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpNotIn, Values: []string{"test", "bar"}},
+				nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test", "foo"}}},
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpNotIn, Values: []string{"test", "bar"}}},
 				}
 				Expect(nodePool.Validate(ctx)).To(Succeed())
 			})
 			It("should allow empty requirements", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{}
+				nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{}
 				Expect(nodePool.Validate(ctx)).To(Succeed())
 			})
 			It("should fail with invalid GT or LT values", func() {
@@ -293,10 +377,29 @@ var _ = Describe("Webhook/Validation", func() {
 					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpLt, Values: []string{"a"}},
 					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpLt, Values: []string{"-1"}},
 				} {
-					nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{requirement}
+					nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: requirement}}
 					Expect(nodePool.Validate(ctx)).ToNot(Succeed())
 				}
 			})
+			It("should allow minValues that are satisfied by the values", func() {
+				nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: v1.NodeSelectorOpIn, Values: []string{"instance-type-1", "instance-type-2"}}, MinValues: lo.ToPtr(2)},
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpExists}, MinValues: lo.ToPtr(2)},
+				}
+				Expect(nodePool.Validate(ctx)).To(Succeed())
+			})
+			It("should fail with minValues that are greater than the number of values", func() {
+				nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: v1.NodeSelectorOpIn, Values: []string{"instance-type-1", "instance-type-2"}}, MinValues: lo.ToPtr(3)},
+				}
+				Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+			})
+			It("should fail with non-positive minValues", func() {
+				nodePool.Spec.Template.Spec.Requirements = []NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpExists}, MinValues: lo.ToPtr(0)},
+				}
+				Expect(nodePool.Validate(ctx)).ToNot(Succeed())
+			})
 		})
 		Context("KubeletConfiguration", func() {
 			It("should fail on kubeReserved with invalid keys", func() {
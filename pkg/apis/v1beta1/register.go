@@ -33,6 +33,10 @@ var (
 			&NodePoolList{},
 			&NodeClaim{},
 			&NodeClaimList{},
+			&DisruptionRecord{},
+			&DisruptionRecordList{},
+			&InstanceTypeOverride{},
+			&InstanceTypeOverrideList{},
 		)
 		metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 		return nil
@@ -26,6 +26,32 @@ import (
 	timex "time"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Budget) DeepCopyInto(out *Budget) {
+	*out = *in
+	out.MaxUnavailable = in.MaxUnavailable
+	if in.Crontab != nil {
+		in, out := &in.Crontab, &out.Crontab
+		*out = new(string)
+		**out = **in
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Budget.
+func (in *Budget) DeepCopy() *Budget {
+	if in == nil {
+		return nil
+	}
+	out := new(Budget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Disruption) DeepCopyInto(out *Disruption) {
 	*out = *in
@@ -34,7 +60,24 @@ func (in *Disruption) DeepCopyInto(out *Disruption) {
 		*out = new(NillableDuration)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
 	in.ExpireAfter.DeepCopyInto(&out.ExpireAfter)
+	if in.TerminationGracePeriod != nil {
+		in, out := &in.TerminationGracePeriod, &out.TerminationGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Budgets != nil {
+		in, out := &in.Budgets, &out.Budgets
+		*out = make([]Budget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Disruption.
@@ -47,6 +90,316 @@ func (in *Disruption) DeepCopy() *Disruption {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionCandidate) DeepCopyInto(out *DisruptionCandidate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionCandidate.
+func (in *DisruptionCandidate) DeepCopy() *DisruptionCandidate {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionCandidate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionPhaseTransition) DeepCopyInto(out *DisruptionPhaseTransition) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionPhaseTransition.
+func (in *DisruptionPhaseTransition) DeepCopy() *DisruptionPhaseTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionPhaseTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionRecord) DeepCopyInto(out *DisruptionRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionRecord.
+func (in *DisruptionRecord) DeepCopy() *DisruptionRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DisruptionRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionRecordList) DeepCopyInto(out *DisruptionRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DisruptionRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionRecordList.
+func (in *DisruptionRecordList) DeepCopy() *DisruptionRecordList {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DisruptionRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionRecordSpec) DeepCopyInto(out *DisruptionRecordSpec) {
+	*out = *in
+	if in.Candidates != nil {
+		in, out := &in.Candidates, &out.Candidates
+		*out = make([]DisruptionCandidate, len(*in))
+		copy(*out, *in)
+	}
+	if in.Replacements != nil {
+		in, out := &in.Replacements, &out.Replacements
+		*out = make([]DisruptionReplacement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionRecordSpec.
+func (in *DisruptionRecordSpec) DeepCopy() *DisruptionRecordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionRecordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionRecordStatus) DeepCopyInto(out *DisruptionRecordStatus) {
+	*out = *in
+	if in.PhaseTransitions != nil {
+		in, out := &in.PhaseTransitions, &out.PhaseTransitions
+		*out = make([]DisruptionPhaseTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReplacementNodeClaims != nil {
+		in, out := &in.ReplacementNodeClaims, &out.ReplacementNodeClaims
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionRecordStatus.
+func (in *DisruptionRecordStatus) DeepCopy() *DisruptionRecordStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionRecordStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionReplacement) DeepCopyInto(out *DisruptionReplacement) {
+	*out = *in
+	if in.InstanceTypes != nil {
+		in, out := &in.InstanceTypes, &out.InstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionReplacement.
+func (in *DisruptionReplacement) DeepCopy() *DisruptionReplacement {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionReplacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Headroom) DeepCopyInto(out *Headroom) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = new(HeadroomPods)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Headroom.
+func (in *Headroom) DeepCopy() *Headroom {
+	if in == nil {
+		return nil
+	}
+	out := new(Headroom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeadroomPods) DeepCopyInto(out *HeadroomPods) {
+	*out = *in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeadroomPods.
+func (in *HeadroomPods) DeepCopy() *HeadroomPods {
+	if in == nil {
+		return nil
+	}
+	out := new(HeadroomPods)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceTypeOverride) DeepCopyInto(out *InstanceTypeOverride) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceTypeOverride.
+func (in *InstanceTypeOverride) DeepCopy() *InstanceTypeOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceTypeOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InstanceTypeOverride) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceTypeOverrideList) DeepCopyInto(out *InstanceTypeOverrideList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]InstanceTypeOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceTypeOverrideList.
+func (in *InstanceTypeOverrideList) DeepCopy() *InstanceTypeOverrideList {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceTypeOverrideList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InstanceTypeOverrideList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceTypeOverrideSpec) DeepCopyInto(out *InstanceTypeOverrideSpec) {
+	*out = *in
+	if in.Requirements != nil {
+		in, out := &in.Requirements, &out.Requirements
+		*out = make([]v1.NodeSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Price != nil {
+		in, out := &in.Price, &out.Price
+		*out = new(string)
+		**out = **in
+	}
+	if in.PriceAdjustment != nil {
+		in, out := &in.PriceAdjustment, &out.PriceAdjustment
+		*out = new(string)
+		**out = **in
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceTypeOverrideSpec.
+func (in *InstanceTypeOverrideSpec) DeepCopy() *InstanceTypeOverrideSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceTypeOverrideSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeletConfiguration) DeepCopyInto(out *KubeletConfiguration) {
 	*out = *in
@@ -251,7 +604,7 @@ func (in *NodeClaimSpec) DeepCopyInto(out *NodeClaimSpec) {
 	}
 	if in.Requirements != nil {
 		in, out := &in.Requirements, &out.Requirements
-		*out = make([]v1.NodeSelectorRequirement, len(*in))
+		*out = make([]NodeSelectorRequirementWithMinValues, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -423,6 +776,11 @@ func (in *NodePoolSpec) DeepCopyInto(out *NodePoolSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Headroom != nil {
+		in, out := &in.Headroom, &out.Headroom
+		*out = new(Headroom)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolSpec.
@@ -445,6 +803,13 @@ func (in *NodePoolStatus) DeepCopyInto(out *NodePoolStatus) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(apis.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolStatus.
@@ -457,6 +822,27 @@ func (in *NodePoolStatus) DeepCopy() *NodePoolStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSelectorRequirementWithMinValues) DeepCopyInto(out *NodeSelectorRequirementWithMinValues) {
+	*out = *in
+	in.NodeSelectorRequirement.DeepCopyInto(&out.NodeSelectorRequirement)
+	if in.MinValues != nil {
+		in, out := &in.MinValues, &out.MinValues
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSelectorRequirementWithMinValues.
+func (in *NodeSelectorRequirementWithMinValues) DeepCopy() *NodeSelectorRequirementWithMinValues {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSelectorRequirementWithMinValues)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectMeta) DeepCopyInto(out *ObjectMeta) {
 	*out = *in
@@ -0,0 +1,217 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+	"github.com/aws/karpenter-core/pkg/metrics"
+)
+
+const (
+	// DefaultInstanceTypesTTL is the time that instance types are cached for when no TTL is configured. Instance types
+	// are cached for a bounded time even when neither the NodePool nor NodeClass change, since the offerings of an
+	// instance type can change at any time.
+	DefaultInstanceTypesTTL = time.Minute
+	// getInstanceTypesTimeout bounds each call to the cloudprovider. Calls are shared by every concurrent caller for the
+	// NodePool, so they aren't bound to the context of the caller that started them.
+	getInstanceTypesTimeout = time.Minute
+
+	metricLabelProvider = "provider"
+)
+
+var (
+	instanceTypesCacheHitsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "cloudprovider",
+			Name:      "instance_types_cache_hits_total",
+			Help:      "Total number of GetInstanceTypes calls that were served from the cache, including calls that were coalesced with an in-flight call. Labeled by provider and nodepool.",
+		},
+		[]string{
+			metricLabelProvider,
+			metrics.NodePoolLabel,
+		},
+	)
+	instanceTypesCacheMissesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "cloudprovider",
+			Name:      "instance_types_cache_misses_total",
+			Help:      "Total number of GetInstanceTypes calls that were passed through to the cloudprovider. Labeled by provider and nodepool.",
+		},
+		[]string{
+			metricLabelProvider,
+			metrics.NodePoolLabel,
+		},
+	)
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(instanceTypesCacheHitsCounter, instanceTypesCacheMissesCounter)
+}
+
+// CloudProvider is a CloudProvider that caches instance types, and whose cached instance types can be invalidated
+type CloudProvider interface {
+	cloudprovider.CloudProvider
+	// Invalidate removes the cached instance types of a NodePool so that they're retrieved from the cloudprovider on
+	// the next call. CloudProviders should call this when the instance types of a NodePool change without a change to
+	// the NodePool or its NodeClass, e.g. when pricing is updated.
+	Invalidate(nodePoolName string)
+	// InvalidateAll removes the cached instance types of every NodePool
+	InvalidateAll()
+}
+
+// decorator implements CloudProvider
+var _ CloudProvider = (*decorator)(nil)
+
+type decorator struct {
+	cloudprovider.CloudProvider
+
+	kubeClient client.Client
+	cache      *gocache.Cache
+	group      singleflight.Group
+}
+
+// Decorate returns a new `CloudProvider` instance that will delegate all method calls to the argument,
+// `cloudProvider`, and cache the instance types returned by GetInstanceTypes for each NodePool. Cached instance types
+// are keyed by the hash of the NodePool and the generation of its NodeClass, so they are refreshed as soon as either
+// changes, and expire after `ttl`. Concurrent calls for the same NodePool are coalesced into a single call, which
+// isn't cancelled when the context of any one caller is.
+//
+// Instance types returned from the cache are shared between callers and must not be mutated.
+//
+// If `cloudProvider` implements `BatchCreator`, the returned `CloudProvider` does too.
+func Decorate(cloudProvider cloudprovider.CloudProvider, kubeClient client.Client, ttl time.Duration) CloudProvider {
+	if ttl <= 0 {
+		ttl = DefaultInstanceTypesTTL
+	}
+	d := &decorator{
+		CloudProvider: cloudProvider,
+		kubeClient:    kubeClient,
+		// Expired entries are replaced when the NodePool's instance types are next retrieved, so we don't need a
+		// janitor goroutine to clean them up
+		cache: gocache.New(ttl, 0),
+	}
+	if batchCreator, ok := cloudProvider.(cloudprovider.BatchCreator); ok {
+		return &batchCreatorDecorator{decorator: d, batchCreator: batchCreator}
+	}
+	return d
+}
+
+// batchCreatorDecorator implements CloudProvider and BatchCreator
+var _ cloudprovider.BatchCreator = (*batchCreatorDecorator)(nil)
+
+type batchCreatorDecorator struct {
+	*decorator
+	batchCreator cloudprovider.BatchCreator
+}
+
+func (d *batchCreatorDecorator) CreateBatch(ctx context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	return d.batchCreator.CreateBatch(ctx, nodeClaims)
+}
+
+func (d *decorator) GetInstanceTypes(ctx context.Context, nodePool *v1beta1.NodePool) ([]*cloudprovider.InstanceType, error) {
+	key, err := d.cacheKey(ctx, nodePool)
+	if err != nil {
+		// We can't tell whether cached instance types are still valid for the NodeClass, so we don't use the cache
+		logging.FromContext(ctx).With("nodepool", nodePool.Name).Debugf("bypassing instance types cache, %s", err)
+		instanceTypesCacheMissesCounter.With(d.labels(nodePool)).Inc()
+		return d.CloudProvider.GetInstanceTypes(ctx, nodePool)
+	}
+	if instanceTypes, ok := d.cache.Get(key); ok {
+		instanceTypesCacheHitsCounter.With(d.labels(nodePool)).Inc()
+		return instanceTypes.([]*cloudprovider.InstanceType), nil
+	}
+	results := d.group.DoChan(key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), getInstanceTypesTimeout)
+		defer cancel()
+		instanceTypes, err := d.CloudProvider.GetInstanceTypes(ctx, nodePool)
+		if err != nil {
+			return nil, err
+		}
+		// Only the latest version of the NodePool and its NodeClass is used, so drop any entries for older versions
+		d.Invalidate(nodePool.Name)
+		d.cache.SetDefault(key, instanceTypes)
+		return instanceTypes, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		if result.Shared {
+			instanceTypesCacheHitsCounter.With(d.labels(nodePool)).Inc()
+		} else {
+			instanceTypesCacheMissesCounter.With(d.labels(nodePool)).Inc()
+		}
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.([]*cloudprovider.InstanceType), nil
+	}
+}
+
+func (d *decorator) Invalidate(nodePoolName string) {
+	for key := range d.cache.Items() {
+		if strings.HasPrefix(key, nodePoolName+"/") {
+			d.cache.Delete(key)
+		}
+	}
+}
+
+func (d *decorator) InvalidateAll() {
+	d.cache.Flush()
+}
+
+// cacheKey returns the key that the instance types of a NodePool are cached under. The key changes whenever the
+// NodePool's template or its NodeClass changes. If the NodeClassRef doesn't specify an apiVersion and kind, the
+// NodeClass can't be resolved, and cached instance types are only refreshed when they expire.
+func (d *decorator) cacheKey(ctx context.Context, nodePool *v1beta1.NodePool) (string, error) {
+	ref := nodePool.Spec.Template.Spec.NodeClassRef
+	if ref == nil || ref.APIVersion == "" || ref.Kind == "" {
+		return fmt.Sprintf("%s/%s", nodePool.Name, nodePool.Hash()), nil
+	}
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return "", fmt.Errorf("parsing nodeclass apiVersion, %w", err)
+	}
+	nodeClass := &metav1.PartialObjectMetadata{}
+	nodeClass.SetGroupVersionKind(gv.WithKind(ref.Kind))
+	if err := d.kubeClient.Get(ctx, client.ObjectKey{Name: ref.Name}, nodeClass); err != nil {
+		return "", fmt.Errorf("getting nodeclass, %w", err)
+	}
+	return fmt.Sprintf("%s/%s/%d", nodePool.Name, nodePool.Hash(), nodeClass.Generation), nil
+}
+
+func (d *decorator) labels(nodePool *v1beta1.NodePool) prometheus.Labels {
+	return prometheus.Labels{
+		metricLabelProvider:   d.Name(),
+		metrics.NodePoolLabel: nodePool.Name,
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+	"github.com/aws/karpenter-core/pkg/cloudprovider/cache"
+	"github.com/aws/karpenter-core/pkg/cloudprovider/fake"
+	"github.com/aws/karpenter-core/pkg/test"
+	. "github.com/aws/karpenter-core/pkg/test/expectations"
+)
+
+var ctx context.Context
+var nodeClassGVK = schema.GroupVersionKind{Group: "test.karpenter.sh", Version: "v1", Kind: "TestNodeClass"}
+
+func TestCache(t *testing.T) {
+	ctx = context.Background()
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CloudProvider Cache Suite")
+}
+
+var _ = Describe("Cache", func() {
+	var kubeClient client.Client
+	var cloudProvider *countingCloudProvider
+	var decorator cache.CloudProvider
+	var nodeClass *unstructured.Unstructured
+	var nodePool *v1beta1.NodePool
+
+	BeforeEach(func() {
+		nodeClass = &unstructured.Unstructured{}
+		nodeClass.SetGroupVersionKind(nodeClassGVK)
+		nodeClass.SetName("default")
+		nodeClass.SetGeneration(1)
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(nodeClassGVK, meta.RESTScopeRoot)
+		kubeClient = fakeclient.NewClientBuilder().WithRESTMapper(mapper).WithObjects(nodeClass).Build()
+
+		cloudProvider = &countingCloudProvider{CloudProvider: fake.NewCloudProvider()}
+		cloudProvider.CloudProvider.(*fake.CloudProvider).InstanceTypes = fake.InstanceTypes(5)
+		decorator = cache.Decorate(cloudProvider, kubeClient, time.Hour)
+		nodePool = test.NodePool(v1beta1.NodePool{
+			Spec: v1beta1.NodePoolSpec{
+				Template: v1beta1.NodeClaimTemplate{
+					Spec: v1beta1.NodeClaimSpec{
+						NodeClassRef: &v1beta1.NodeClassReference{
+							APIVersion: nodeClassGVK.GroupVersion().String(),
+							Kind:       nodeClassGVK.Kind,
+							Name:       "default",
+						},
+					},
+				},
+			},
+		})
+	})
+
+	It("should return the instance types of the cloudprovider", func() {
+		instanceTypes, err := decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instanceTypes).To(HaveLen(5))
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 1))
+	})
+	It("should serve repeated calls from the cache", func() {
+		for i := 0; i < 3; i++ {
+			_, err := decorator.GetInstanceTypes(ctx, nodePool)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 1))
+		m, found := FindMetricWithLabelValues("karpenter_cloudprovider_instance_types_cache_hits_total", map[string]string{"nodepool": nodePool.Name})
+		Expect(found).To(BeTrue())
+		Expect(m.GetCounter().GetValue()).To(BeNumerically("==", 2))
+		m, found = FindMetricWithLabelValues("karpenter_cloudprovider_instance_types_cache_misses_total", map[string]string{"nodepool": nodePool.Name})
+		Expect(found).To(BeTrue())
+		Expect(m.GetCounter().GetValue()).To(BeNumerically("==", 1))
+	})
+	It("should cache instance types separately for each nodepool", func() {
+		other := test.NodePool(*nodePool.DeepCopy())
+		other.Name = "other"
+		_, err := decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = decorator.GetInstanceTypes(ctx, other)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 2))
+	})
+	It("should refresh instance types when the nodepool changes", func() {
+		_, err := decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		nodePool.Spec.Template.Labels = map[string]string{"test-key": "test-value"}
+		_, err = decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 2))
+	})
+	It("should refresh instance types when the nodeclass generation changes", func() {
+		_, err := decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		nodeClass.SetGeneration(2)
+		Expect(kubeClient.Update(ctx, nodeClass)).To(Succeed())
+		_, err = decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 2))
+	})
+	It("should bypass the cache when the nodeclass can't be found", func() {
+		nodePool.Spec.Template.Spec.NodeClassRef.Name = "missing"
+		for i := 0; i < 2; i++ {
+			_, err := decorator.GetInstanceTypes(ctx, nodePool)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 2))
+	})
+	It("should cache by nodepool hash when the nodeclassref doesn't specify a kind", func() {
+		nodePool.Spec.Template.Spec.NodeClassRef = &v1beta1.NodeClassReference{Name: "default"}
+		for i := 0; i < 2; i++ {
+			_, err := decorator.GetInstanceTypes(ctx, nodePool)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 1))
+	})
+	It("should expire cached instance types after the ttl", func() {
+		decorator = cache.Decorate(cloudProvider, kubeClient, 10*time.Millisecond)
+		_, err := decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(func(g Gomega) {
+			_, err := decorator.GetInstanceTypes(ctx, nodePool)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 2))
+		}).Should(Succeed())
+	})
+	It("should not cache errors", func() {
+		cloudProvider.err = fmt.Errorf("test error")
+		_, err := decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).To(HaveOccurred())
+		cloudProvider.err = nil
+		instanceTypes, err := decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instanceTypes).To(HaveLen(5))
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 2))
+	})
+	It("should coalesce concurrent calls for the same nodepool", func() {
+		cloudProvider.block = make(chan struct{})
+		wg := sync.WaitGroup{}
+		results := make([][]*cloudprovider.InstanceType, 5)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				instanceTypes, err := decorator.GetInstanceTypes(ctx, nodePool)
+				Expect(err).ToNot(HaveOccurred())
+				results[i] = instanceTypes
+			}(i)
+		}
+		// Give every goroutine a chance to join the in-flight call before releasing it
+		Eventually(cloudProvider.calls.Load).Should(BeNumerically("==", 1))
+		time.Sleep(100 * time.Millisecond)
+		close(cloudProvider.block)
+		wg.Wait()
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 1))
+		for _, instanceTypes := range results {
+			Expect(instanceTypes).To(HaveLen(5))
+		}
+	})
+	It("should not fail coalesced calls when the caller that started the call is cancelled", func() {
+		cloudProvider.block = make(chan struct{})
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		wg := sync.WaitGroup{}
+		wg.Add(2)
+		go func() {
+			defer GinkgoRecover()
+			defer wg.Done()
+			_, err := decorator.GetInstanceTypes(cancelledCtx, nodePool)
+			Expect(err).To(MatchError(context.Canceled))
+		}()
+		Eventually(cloudProvider.calls.Load).Should(BeNumerically("==", 1))
+		go func() {
+			defer GinkgoRecover()
+			defer wg.Done()
+			instanceTypes, err := decorator.GetInstanceTypes(ctx, nodePool)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instanceTypes).To(HaveLen(5))
+		}()
+		// Give the second goroutine a chance to join the in-flight call before cancelling the first
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+		close(cloudProvider.block)
+		wg.Wait()
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 1))
+		Expect(cloudProvider.ctxErr.Load()).To(BeNil())
+	})
+	It("should refresh instance types of a nodepool after it's invalidated", func() {
+		other := test.NodePool(*nodePool.DeepCopy())
+		other.Name = "other"
+		_, err := decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = decorator.GetInstanceTypes(ctx, other)
+		Expect(err).ToNot(HaveOccurred())
+		decorator.Invalidate(nodePool.Name)
+		_, err = decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = decorator.GetInstanceTypes(ctx, other)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 3))
+	})
+	It("should refresh instance types of every nodepool after they're all invalidated", func() {
+		other := test.NodePool(*nodePool.DeepCopy())
+		other.Name = "other"
+		for _, np := range []*v1beta1.NodePool{nodePool, other} {
+			_, err := decorator.GetInstanceTypes(ctx, np)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		decorator.InvalidateAll()
+		for _, np := range []*v1beta1.NodePool{nodePool, other} {
+			_, err := decorator.GetInstanceTypes(ctx, np)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 4))
+	})
+	It("should delegate other calls to the cloudprovider", func() {
+		Expect(decorator.Name()).To(Equal("fake"))
+		nodeClaims, err := decorator.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodeClaims).To(BeEmpty())
+		Expect(lo.Must(decorator.IsDrifted(ctx, test.NodeClaim()))).To(BeNil())
+	})
+	It("should implement BatchCreator when the decorated CloudProvider does", func() {
+		batchDecorator := cache.Decorate(&batchCloudProvider{CloudProvider: cloudProvider}, kubeClient, time.Hour)
+		batchCreator, ok := batchDecorator.(cloudprovider.BatchCreator)
+		Expect(ok).To(BeTrue())
+		created, errs := batchCreator.CreateBatch(ctx, []*v1beta1.NodeClaim{test.NodeClaim(), test.NodeClaim()})
+		Expect(created).To(HaveLen(2))
+		Expect(errs).To(HaveLen(2))
+
+		// instance types are still cached
+		for i := 0; i < 2; i++ {
+			_, err := batchDecorator.GetInstanceTypes(ctx, nodePool)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(cloudProvider.calls.Load()).To(BeNumerically("==", 1))
+	})
+	It("should not implement BatchCreator when the decorated CloudProvider doesn't", func() {
+		_, ok := decorator.(cloudprovider.BatchCreator)
+		Expect(ok).To(BeFalse())
+	})
+})
+
+// countingCloudProvider counts calls to GetInstanceTypes and can block them until released
+type countingCloudProvider struct {
+	cloudprovider.CloudProvider
+
+	calls atomic.Int32
+	block chan struct{}
+	err   error
+	// ctxErr is the error of the context that GetInstanceTypes was last called with, once the call is released
+	ctxErr atomic.Pointer[error]
+}
+
+func (c *countingCloudProvider) GetInstanceTypes(ctx context.Context, nodePool *v1beta1.NodePool) ([]*cloudprovider.InstanceType, error) {
+	c.calls.Add(1)
+	if c.block != nil {
+		<-c.block
+	}
+	if err := ctx.Err(); err != nil {
+		c.ctxErr.Store(&err)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.CloudProvider.GetInstanceTypes(ctx, nodePool)
+}
+
+type batchCloudProvider struct {
+	cloudprovider.CloudProvider
+}
+
+func (c *batchCloudProvider) CreateBatch(_ context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	return nodeClaims, make([]error, len(nodeClaims))
+}
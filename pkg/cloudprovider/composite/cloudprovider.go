@@ -0,0 +1,242 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+)
+
+// Route assigns the NodeClaims of a NodeClass kind and a providerID scheme to a CloudProvider
+type Route struct {
+	// NodeClassKind is the kind of the NodeClass that is managed by the CloudProvider
+	NodeClassKind string
+	// NodeClassAPIVersion is the API version of the NodeClass that is managed by the CloudProvider. If set, it must
+	// match the API version of a NodeClassRef whenever the NodeClassRef sets one.
+	NodeClassAPIVersion string
+	// ProviderIDScheme is the scheme of the providerIDs of the instances launched by the CloudProvider,
+	// e.g. "aws" for providerIDs in the form aws:///us-west-2a/i-0123456789
+	ProviderIDScheme string
+	// CloudProvider is the CloudProvider that calls are routed to
+	CloudProvider cloudprovider.CloudProvider
+}
+
+// composite implements CloudProvider
+var _ cloudprovider.CloudProvider = (*composite)(nil)
+
+type composite struct {
+	routes []Route
+}
+
+// New returns a new `CloudProvider` instance that routes each method call to one of the CloudProviders in `routes`.
+// Calls that launch or inspect the configuration of a NodeClaim (Create, GetInstanceTypes and IsDrifted) are routed by
+// the NodeClassRef of the NodeClaim or NodePool. Calls that operate on launched instances (Get and Delete) are routed by
+// the scheme of the providerID. List returns the NodeClaims of every CloudProvider.
+//
+// Decorate each CloudProvider with metrics.Decorate before passing it in, rather than decorating the composite, so that
+// published metrics are labeled with the name of the CloudProvider that served the call.
+//
+// If any of the CloudProviders implements `BatchCreator`, the returned `CloudProvider` does too. NodeClaims in a batch
+// that are routed to a CloudProvider that doesn't implement it are created one at a time.
+func New(routes ...Route) (cloudprovider.CloudProvider, error) {
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("at least one route must be specified")
+	}
+	for i, route := range routes {
+		if route.CloudProvider == nil {
+			return nil, fmt.Errorf("route for NodeClass kind %q has no CloudProvider", route.NodeClassKind)
+		}
+		if route.NodeClassKind == "" || route.ProviderIDScheme == "" {
+			return nil, fmt.Errorf("route for CloudProvider %q must specify a NodeClass kind and providerID scheme", route.CloudProvider.Name())
+		}
+		for _, other := range routes[:i] {
+			if other.NodeClassKind == route.NodeClassKind &&
+				(other.NodeClassAPIVersion == "" || route.NodeClassAPIVersion == "" || other.NodeClassAPIVersion == route.NodeClassAPIVersion) {
+				return nil, fmt.Errorf("NodeClass kind %q is routed to multiple CloudProviders", route.NodeClassKind)
+			}
+			if other.ProviderIDScheme == route.ProviderIDScheme {
+				return nil, fmt.Errorf("providerID scheme %q is routed to multiple CloudProviders", route.ProviderIDScheme)
+			}
+		}
+	}
+	c := &composite{routes: routes}
+	if lo.SomeBy(routes, func(r Route) bool { _, ok := r.CloudProvider.(cloudprovider.BatchCreator); return ok }) {
+		return &batchCreatorComposite{composite: c}, nil
+	}
+	return c, nil
+}
+
+// batchCreatorComposite implements CloudProvider and BatchCreator
+var _ cloudprovider.BatchCreator = (*batchCreatorComposite)(nil)
+
+type batchCreatorComposite struct {
+	*composite
+}
+
+// CreateBatch splits the NodeClaims by the CloudProvider that they're routed to, and launches each group through a
+// single call to that CloudProvider
+func (c *batchCreatorComposite) CreateBatch(ctx context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	created := make([]*v1beta1.NodeClaim, len(nodeClaims))
+	errs := make([]error, len(nodeClaims))
+	// the indices of the NodeClaims that are routed to each route
+	batches := make([][]int, len(c.routes))
+	for i, nodeClaim := range nodeClaims {
+		route, err := c.routeForNodeClassRef(nodeClaim.Spec.NodeClassRef)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		batches[route] = append(batches[route], i)
+	}
+	for route, indices := range batches {
+		if len(indices) == 0 {
+			continue
+		}
+		cloudProvider := c.routes[route].CloudProvider
+		batchCreator, ok := cloudProvider.(cloudprovider.BatchCreator)
+		if !ok {
+			for _, i := range indices {
+				created[i], errs[i] = cloudProvider.Create(ctx, nodeClaims[i])
+			}
+			continue
+		}
+		batchCreated, batchErrs := batchCreator.CreateBatch(ctx, lo.Map(indices, func(i int, _ int) *v1beta1.NodeClaim { return nodeClaims[i] }))
+		for j, i := range indices {
+			created[i], errs[i] = batchCreated[j], batchErrs[j]
+		}
+	}
+	return created, errs
+}
+
+func (c *composite) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
+	cloudProvider, err := c.forNodeClassRef(nodeClaim.Spec.NodeClassRef)
+	if err != nil {
+		return nil, err
+	}
+	return cloudProvider.Create(ctx, nodeClaim)
+}
+
+func (c *composite) Delete(ctx context.Context, nodeClaim *v1beta1.NodeClaim) error {
+	// NodeClaims that haven't resolved a providerID yet can still be routed by their NodeClass
+	if nodeClaim.Status.ProviderID == "" {
+		cloudProvider, err := c.forNodeClassRef(nodeClaim.Spec.NodeClassRef)
+		if err != nil {
+			return err
+		}
+		return cloudProvider.Delete(ctx, nodeClaim)
+	}
+	cloudProvider, err := c.forProviderID(nodeClaim.Status.ProviderID)
+	if err != nil {
+		return err
+	}
+	return cloudProvider.Delete(ctx, nodeClaim)
+}
+
+func (c *composite) Get(ctx context.Context, providerID string) (*v1beta1.NodeClaim, error) {
+	cloudProvider, err := c.forProviderID(providerID)
+	if err != nil {
+		return nil, err
+	}
+	return cloudProvider.Get(ctx, providerID)
+}
+
+// List returns the NodeClaims of every CloudProvider. An error is returned if any CloudProvider fails to list its
+// NodeClaims, since callers may treat NodeClaims that are missing from the list as terminated.
+func (c *composite) List(ctx context.Context) ([]*v1beta1.NodeClaim, error) {
+	var nodeClaims []*v1beta1.NodeClaim
+	var errs error
+	for _, route := range c.routes {
+		n, err := route.CloudProvider.List(ctx)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("listing nodeclaims for cloudprovider %q, %w", route.CloudProvider.Name(), err))
+			continue
+		}
+		nodeClaims = append(nodeClaims, n...)
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return nodeClaims, nil
+}
+
+func (c *composite) GetInstanceTypes(ctx context.Context, nodePool *v1beta1.NodePool) ([]*cloudprovider.InstanceType, error) {
+	cloudProvider, err := c.forNodeClassRef(nodePool.Spec.Template.Spec.NodeClassRef)
+	if err != nil {
+		return nil, err
+	}
+	return cloudProvider.GetInstanceTypes(ctx, nodePool)
+}
+
+func (c *composite) IsDrifted(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*cloudprovider.Drift, error) {
+	cloudProvider, err := c.forNodeClassRef(nodeClaim.Spec.NodeClassRef)
+	if err != nil {
+		return nil, err
+	}
+	return cloudProvider.IsDrifted(ctx, nodeClaim)
+}
+
+// Name returns the names of the routed CloudProvider implementations
+func (c *composite) Name() string {
+	return strings.Join(lo.Map(c.routes, func(r Route, _ int) string { return r.CloudProvider.Name() }), ",")
+}
+
+func (c *composite) forNodeClassRef(ref *v1beta1.NodeClassReference) (cloudprovider.CloudProvider, error) {
+	route, err := c.routeForNodeClassRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	return c.routes[route].CloudProvider, nil
+}
+
+// routeForNodeClassRef returns the index of the route that the NodeClassRef is routed to
+func (c *composite) routeForNodeClassRef(ref *v1beta1.NodeClassReference) (int, error) {
+	if ref == nil {
+		return 0, fmt.Errorf("routing to cloudprovider, nodeClassRef is not set")
+	}
+	// Routes can't overlap, so at most one route matches the NodeClassRef
+	_, route, ok := lo.FindIndexOf(c.routes, func(r Route) bool {
+		return r.NodeClassKind == ref.Kind && (ref.APIVersion == "" || r.NodeClassAPIVersion == "" || r.NodeClassAPIVersion == ref.APIVersion)
+	})
+	if !ok {
+		return 0, fmt.Errorf("routing to cloudprovider, no cloudprovider is registered for NodeClass %s", nodeClassRefString(ref))
+	}
+	return route, nil
+}
+
+func (c *composite) forProviderID(providerID string) (cloudprovider.CloudProvider, error) {
+	scheme, _, ok := strings.Cut(providerID, "://")
+	if !ok {
+		return nil, fmt.Errorf("routing to cloudprovider, parsing scheme of providerID %q", providerID)
+	}
+	route, ok := lo.Find(c.routes, func(r Route) bool { return r.ProviderIDScheme == scheme })
+	if !ok {
+		return nil, fmt.Errorf("routing to cloudprovider, no cloudprovider is registered for providerID scheme %q", scheme)
+	}
+	return route.CloudProvider, nil
+}
+
+func nodeClassRefString(ref *v1beta1.NodeClassReference) string {
+	if ref.APIVersion == "" {
+		return fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+	}
+	return fmt.Sprintf("%s/%s (%s)", ref.Kind, ref.Name, ref.APIVersion)
+}
@@ -0,0 +1,217 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+	"github.com/aws/karpenter-core/pkg/cloudprovider/composite"
+	"github.com/aws/karpenter-core/pkg/cloudprovider/fake"
+	"github.com/aws/karpenter-core/pkg/test"
+)
+
+var ctx context.Context
+var cloudBurst *namedCloudProvider
+var onPrem *namedCloudProvider
+var cloudProvider cloudprovider.CloudProvider
+
+func TestComposite(t *testing.T) {
+	ctx = context.Background()
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CloudProvider Composite Suite")
+}
+
+var _ = BeforeEach(func() {
+	cloudBurst = &namedCloudProvider{CloudProvider: fake.NewCloudProvider(), name: "cloud"}
+	onPrem = &namedCloudProvider{CloudProvider: fake.NewCloudProvider(), name: "metal"}
+	cloudBurst.InstanceTypes = fake.InstanceTypes(2)
+	onPrem.InstanceTypes = fake.InstanceTypes(5)
+	onPrem.Drifted = "rack-moved"
+	cloudProvider = lo.Must(composite.New(
+		composite.Route{NodeClassKind: "CloudNodeClass", ProviderIDScheme: "fake", CloudProvider: cloudBurst},
+		composite.Route{NodeClassKind: "MetalNodeClass", NodeClassAPIVersion: "metal.sh/v1", ProviderIDScheme: "metal", CloudProvider: onPrem},
+	))
+})
+
+var _ = Describe("Composite", func() {
+	var nodePool *v1beta1.NodePool
+	var nodeClaim *v1beta1.NodeClaim
+	BeforeEach(func() {
+		nodePool = test.NodePool(v1beta1.NodePool{
+			Spec: v1beta1.NodePoolSpec{
+				Template: v1beta1.NodeClaimTemplate{
+					Spec: v1beta1.NodeClaimSpec{
+						NodeClassRef: &v1beta1.NodeClassReference{Kind: "MetalNodeClass", Name: "default"},
+					},
+				},
+			},
+		})
+		nodeClaim = test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+			},
+			Spec: v1beta1.NodeClaimSpec{
+				NodeClassRef: &v1beta1.NodeClassReference{Kind: "MetalNodeClass", APIVersion: "metal.sh/v1", Name: "default"},
+			},
+		})
+	})
+	It("should fail to construct when routes overlap", func() {
+		_, err := composite.New(
+			composite.Route{NodeClassKind: "CloudNodeClass", ProviderIDScheme: "fake", CloudProvider: cloudBurst},
+			composite.Route{NodeClassKind: "CloudNodeClass", NodeClassAPIVersion: "cloud.sh/v1", ProviderIDScheme: "cloud", CloudProvider: onPrem},
+		)
+		Expect(err).To(HaveOccurred())
+		_, err = composite.New(
+			composite.Route{NodeClassKind: "CloudNodeClass", ProviderIDScheme: "fake", CloudProvider: cloudBurst},
+			composite.Route{NodeClassKind: "MetalNodeClass", ProviderIDScheme: "fake", CloudProvider: onPrem},
+		)
+		Expect(err).To(HaveOccurred())
+	})
+	It("should route Create by NodeClassRef", func() {
+		_, err := cloudProvider.Create(ctx, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(onPrem.CreateCalls).To(HaveLen(1))
+		Expect(cloudBurst.CreateCalls).To(HaveLen(0))
+	})
+	It("should route GetInstanceTypes by the NodeClassRef of the NodePool", func() {
+		instanceTypes, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instanceTypes).To(HaveLen(5))
+
+		nodePool.Spec.Template.Spec.NodeClassRef.Kind = "CloudNodeClass"
+		instanceTypes, err = cloudProvider.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instanceTypes).To(HaveLen(2))
+	})
+	It("should route IsDrifted by NodeClassRef", func() {
+		drift, err := cloudProvider.IsDrifted(ctx, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drift.Reason).To(Equal(cloudprovider.DriftReason("rack-moved")))
+	})
+	It("should fail to route when no CloudProvider matches the NodeClassRef", func() {
+		nodeClaim.Spec.NodeClassRef.APIVersion = "metal.sh/v2"
+		_, err := cloudProvider.Create(ctx, nodeClaim)
+		Expect(err).To(HaveOccurred())
+
+		nodeClaim.Spec.NodeClassRef = &v1beta1.NodeClassReference{Kind: "UnknownNodeClass", Name: "default"}
+		_, err = cloudProvider.Create(ctx, nodeClaim)
+		Expect(err).To(HaveOccurred())
+		Expect(onPrem.CreateCalls).To(HaveLen(0))
+		Expect(cloudBurst.CreateCalls).To(HaveLen(0))
+	})
+	It("should route Get and Delete by providerID scheme", func() {
+		nodeClaim.Status.ProviderID = "metal:///rack-1/host-1"
+		onPrem.CreatedNodeClaims[nodeClaim.Status.ProviderID] = nodeClaim
+
+		retrieved, err := cloudProvider.Get(ctx, nodeClaim.Status.ProviderID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(retrieved.Name).To(Equal(nodeClaim.Name))
+
+		Expect(cloudProvider.Delete(ctx, nodeClaim)).To(Succeed())
+		Expect(onPrem.DeleteCalls).To(HaveLen(1))
+		Expect(cloudBurst.DeleteCalls).To(HaveLen(0))
+	})
+	It("should return NodeClaimNotFoundErrors from the routed CloudProvider", func() {
+		_, err := cloudProvider.Get(ctx, test.RandomProviderID())
+		Expect(cloudprovider.IsNodeClaimNotFoundError(err)).To(BeTrue())
+	})
+	It("should fail to route Get when no CloudProvider matches the providerID scheme", func() {
+		_, err := cloudProvider.Get(ctx, "unknown:///instance")
+		Expect(err).To(HaveOccurred())
+		Expect(cloudprovider.IsNodeClaimNotFoundError(err)).To(BeFalse())
+	})
+	It("should list NodeClaims from every CloudProvider", func() {
+		cloudNodeClaim := test.NodeClaim()
+		cloudNodeClaim.Status.ProviderID = test.RandomProviderID()
+		cloudBurst.CreatedNodeClaims[cloudNodeClaim.Status.ProviderID] = cloudNodeClaim
+		nodeClaim.Status.ProviderID = "metal:///rack-1/host-1"
+		onPrem.CreatedNodeClaims[nodeClaim.Status.ProviderID] = nodeClaim
+
+		nodeClaims, err := cloudProvider.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lo.Map(nodeClaims, func(n *v1beta1.NodeClaim, _ int) string { return n.Status.ProviderID })).To(ConsistOf(
+			cloudNodeClaim.Status.ProviderID, nodeClaim.Status.ProviderID,
+		))
+	})
+	It("should return the names of every CloudProvider", func() {
+		Expect(cloudProvider.Name()).To(Equal("cloud,metal"))
+	})
+	It("should not implement BatchCreator when none of the routed CloudProviders do", func() {
+		_, ok := cloudProvider.(cloudprovider.BatchCreator)
+		Expect(ok).To(BeFalse())
+	})
+	It("should implement BatchCreator and route each NodeClaim of a batch by NodeClassRef when any routed CloudProvider does", func() {
+		batchOnPrem := &batchCloudProvider{namedCloudProvider: onPrem}
+		cloudProvider = lo.Must(composite.New(
+			composite.Route{NodeClassKind: "CloudNodeClass", ProviderIDScheme: "fake", CloudProvider: cloudBurst},
+			composite.Route{NodeClassKind: "MetalNodeClass", NodeClassAPIVersion: "metal.sh/v1", ProviderIDScheme: "metal", CloudProvider: batchOnPrem},
+		))
+		batchCreator, ok := cloudProvider.(cloudprovider.BatchCreator)
+		Expect(ok).To(BeTrue())
+
+		cloudNodeClaim := nodeClaim.DeepCopy()
+		cloudNodeClaim.Spec.NodeClassRef = &v1beta1.NodeClassReference{Kind: "CloudNodeClass", Name: "default"}
+		unknownNodeClaim := nodeClaim.DeepCopy()
+		unknownNodeClaim.Spec.NodeClassRef = &v1beta1.NodeClassReference{Kind: "UnknownNodeClass", Name: "default"}
+		created, errs := batchCreator.CreateBatch(ctx, []*v1beta1.NodeClaim{nodeClaim, cloudNodeClaim, unknownNodeClaim, nodeClaim.DeepCopy()})
+		Expect(created).To(HaveLen(4))
+		Expect(errs).To(HaveLen(4))
+		for _, i := range []int{0, 1, 3} {
+			Expect(errs[i]).ToNot(HaveOccurred())
+			Expect(created[i]).ToNot(BeNil())
+		}
+		Expect(errs[2]).To(HaveOccurred())
+		Expect(created[2]).To(BeNil())
+
+		// the NodeClaims that are routed to the BatchCreator are launched together
+		Expect(batchOnPrem.batches).To(HaveLen(1))
+		Expect(batchOnPrem.batches[0]).To(HaveLen(2))
+		Expect(onPrem.CreateCalls).To(HaveLen(2))
+		Expect(cloudBurst.CreateCalls).To(HaveLen(1))
+	})
+})
+
+type namedCloudProvider struct {
+	*fake.CloudProvider
+	name string
+}
+
+func (c *namedCloudProvider) Name() string {
+	return c.name
+}
+
+type batchCloudProvider struct {
+	*namedCloudProvider
+	batches [][]*v1beta1.NodeClaim
+}
+
+func (c *batchCloudProvider) CreateBatch(ctx context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	c.batches = append(c.batches, nodeClaims)
+	created := make([]*v1beta1.NodeClaim, len(nodeClaims))
+	errs := make([]error, len(nodeClaims))
+	for i, nodeClaim := range nodeClaims {
+		created[i], errs[i] = c.Create(ctx, nodeClaim)
+	}
+	return created, errs
+}
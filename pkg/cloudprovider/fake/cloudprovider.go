@@ -50,7 +50,10 @@ type CloudProvider struct {
 	DeleteCalls        []*v1beta1.NodeClaim
 
 	CreatedNodeClaims map[string]*v1beta1.NodeClaim
-	Drifted           cloudprovider.DriftReason
+	// Drifted is the reason of the drift returned by IsDrifted. NodeClaims aren't drifted if it's empty.
+	Drifted cloudprovider.DriftReason
+	// DriftDetails are the details of the drift returned by IsDrifted when Drifted is set
+	DriftDetails cloudprovider.Drift
 }
 
 func NewCloudProvider() *CloudProvider {
@@ -75,6 +78,7 @@ func (c *CloudProvider) Reset() {
 	c.NextCreateErr = nil
 	c.DeleteCalls = []*v1beta1.NodeClaim{}
 	c.Drifted = "drifted"
+	c.DriftDetails = cloudprovider.Drift{}
 }
 
 func (c *CloudProvider) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
@@ -91,13 +95,17 @@ func (c *CloudProvider) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim
 	if len(c.CreateCalls) > c.AllowedCreateCalls {
 		return &v1beta1.NodeClaim{}, fmt.Errorf("erroring as number of AllowedCreateCalls has been exceeded")
 	}
-	reqs := scheduling.NewNodeSelectorRequirements(nodeClaim.Spec.Requirements...)
+	reqs := scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaim.Spec.Requirements...)
 	np := &v1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: nodeClaim.Labels[v1beta1.NodePoolLabelKey]}}
 	instanceTypes := lo.Filter(lo.Must(c.GetInstanceTypes(ctx, np)), func(i *cloudprovider.InstanceType, _ int) bool {
 		return reqs.Compatible(i.Requirements, scheduling.AllowUndefinedWellKnownLabels) == nil &&
 			len(i.Offerings.Requirements(reqs).Available()) > 0 &&
 			resources.Fits(nodeClaim.Spec.Resources.Requests, i.Allocatable())
 	})
+	// The compatible instance types must satisfy the minValues of the requirements
+	if _, err := cloudprovider.InstanceTypes(instanceTypes).SatisfiesMinValues(reqs); err != nil {
+		return &v1beta1.NodeClaim{}, fmt.Errorf("validating minValues, %w", err)
+	}
 	// Order instance types so that we get the cheapest instance types of the available offerings
 	sort.Slice(instanceTypes, func(i, j int) bool {
 		iOfferings := instanceTypes[i].Offerings.Available().Requirements(reqs)
@@ -224,11 +232,16 @@ func (c *CloudProvider) Delete(_ context.Context, nc *v1beta1.NodeClaim) error {
 	return cloudprovider.NewNodeClaimNotFoundError(fmt.Errorf("no nodeclaim exists with provider id '%s'", nc.Status.ProviderID))
 }
 
-func (c *CloudProvider) IsDrifted(context.Context, *v1beta1.NodeClaim) (cloudprovider.DriftReason, error) {
+func (c *CloudProvider) IsDrifted(context.Context, *v1beta1.NodeClaim) (*cloudprovider.Drift, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return c.Drifted, nil
+	if c.Drifted == "" {
+		return nil, nil
+	}
+	drift := c.DriftDetails
+	drift.Reason = c.Drifted
+	return &drift, nil
 }
 
 // Name returns the CloudProvider implementation name.
@@ -0,0 +1,181 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+)
+
+// DefaultTimeout is the deadline applied to each call to the remote CloudProvider when none is configured
+const DefaultTimeout = 30 * time.Second
+
+var _ cloudprovider.CloudProvider = (*CloudProvider)(nil)
+
+// CloudProvider implements the CloudProvider interface by calling a CloudProvider served over gRPC
+type CloudProvider struct {
+	conn    grpc.ClientConnInterface
+	timeout time.Duration
+	name    string
+}
+
+// NewCloudProvider performs the protocol version handshake with the server and returns a CloudProvider that calls it.
+// Each call is bounded by the timeout, in addition to any deadline already set on its context.
+func NewCloudProvider(ctx context.Context, conn grpc.ClientConnInterface, timeout time.Duration) (*CloudProvider, error) {
+	c := &CloudProvider{
+		conn:    conn,
+		timeout: lo.Ternary(timeout > 0, timeout, DefaultTimeout),
+	}
+	resp := &HandshakeResponse{}
+	if err := c.invoke(ctx, handshakeMethod, &HandshakeRequest{ProtocolVersion: ProtocolVersion}, resp); err != nil {
+		return nil, fmt.Errorf("performing handshake, %w", err)
+	}
+	if resp.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("performing handshake, unsupported protocol version %q, expected %q", resp.ProtocolVersion, ProtocolVersion)
+	}
+	c.name = resp.Name
+	return c, nil
+}
+
+func (c *CloudProvider) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
+	resp := &CreateResponse{}
+	if err := c.invoke(ctx, createMethod, &CreateRequest{NodeClaim: nodeClaim}, resp); err != nil {
+		return nil, err
+	}
+	return resp.NodeClaim, nil
+}
+
+func (c *CloudProvider) Delete(ctx context.Context, nodeClaim *v1beta1.NodeClaim) error {
+	return c.invoke(ctx, deleteMethod, &DeleteRequest{NodeClaim: nodeClaim}, &DeleteResponse{})
+}
+
+func (c *CloudProvider) Get(ctx context.Context, providerID string) (*v1beta1.NodeClaim, error) {
+	resp := &GetResponse{}
+	if err := c.invoke(ctx, getMethod, &GetRequest{ProviderID: providerID}, resp); err != nil {
+		return nil, err
+	}
+	return resp.NodeClaim, nil
+}
+
+func (c *CloudProvider) List(ctx context.Context) ([]*v1beta1.NodeClaim, error) {
+	resp := &ListResponse{}
+	if err := c.invoke(ctx, listMethod, &ListRequest{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.NodeClaims, nil
+}
+
+func (c *CloudProvider) GetInstanceTypes(ctx context.Context, nodePool *v1beta1.NodePool) ([]*cloudprovider.InstanceType, error) {
+	resp := &GetInstanceTypesResponse{}
+	if err := c.invoke(ctx, getInstanceTypesMethod, &GetInstanceTypesRequest{NodePool: nodePool}, resp); err != nil {
+		return nil, err
+	}
+	return lo.Map(resp.InstanceTypes, func(it InstanceType, _ int) *cloudprovider.InstanceType { return it.toInstanceType() }), nil
+}
+
+func (c *CloudProvider) IsDrifted(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*cloudprovider.Drift, error) {
+	resp := &IsDriftedResponse{}
+	if err := c.invoke(ctx, isDriftedMethod, &IsDriftedRequest{NodeClaim: nodeClaim}, resp); err != nil {
+		return nil, err
+	}
+	if resp.Drift == nil {
+		return nil, nil
+	}
+	return lo.ToPtr(cloudprovider.Drift(*resp.Drift)), nil
+}
+
+// Name returns the name of the remote CloudProvider implementation, as reported during the handshake
+func (c *CloudProvider) Name() string {
+	return c.name
+}
+
+func (c *CloudProvider) invoke(ctx context.Context, method string, req, resp interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var trailer metadata.MD
+	if err := c.conn.Invoke(ctx, fullMethodName(method), req, resp, grpc.ForceCodec(codec{}), grpc.Trailer(&trailer), grpc.MaxCallRecvMsgSize(MaxMessageSize)); err != nil {
+		return fromStatus(err, trailer)
+	}
+	return nil
+}
+
+// fromStatus converts a gRPC status returned by the server back into the typed CloudProvider error that caused it.
+// Only statuses that carry an error type trailer are converted, since gRPC returns the same codes for its own failures.
+func fromStatus(err error, trailer metadata.MD) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch errorType(trailer) {
+	case nodeClaimNotFoundErrorType:
+		return cloudprovider.NewNodeClaimNotFoundError(errors.New(s.Message()))
+	case insufficientCapacityErrorType, quotaExceededErrorType:
+		var offerings []UnavailableOffering
+		if values := trailer.Get(unavailableOfferingsTrailerKey); len(values) > 0 {
+			if err := json.Unmarshal([]byte(values[0]), &offerings); err != nil {
+				return fmt.Errorf("unmarshaling unavailable offerings, %w", err)
+			}
+		}
+		unavailableOfferings := lo.Map(offerings, func(o UnavailableOffering, _ int) cloudprovider.UnavailableOffering {
+			return cloudprovider.UnavailableOffering(o)
+		})
+		if errorType(trailer) == quotaExceededErrorType {
+			return cloudprovider.NewQuotaExceededError(errors.New(s.Message()), unavailableOfferings...)
+		}
+		return cloudprovider.NewInsufficientCapacityError(errors.New(s.Message()), unavailableOfferings...)
+	case nodeClassNotReadyErrorType:
+		return cloudprovider.NewNodeClassNotReadyError(errors.New(s.Message()))
+	case throttledErrorType:
+		var retryAfter time.Duration
+		if values := trailer.Get(retryAfterTrailerKey); len(values) > 0 {
+			if retryAfter, err = time.ParseDuration(values[0]); err != nil {
+				return fmt.Errorf("parsing retry after, %w", err)
+			}
+		}
+		return cloudprovider.NewThrottledError(errors.New(s.Message()), retryAfter)
+	case invalidConfigurationErrorType:
+		return cloudprovider.NewInvalidConfigurationError(errors.New(s.Message()))
+	case unauthorizedErrorType:
+		return cloudprovider.NewUnauthorizedError(errors.New(s.Message()))
+	}
+	switch s.Code() {
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%s, %w", s.Message(), context.DeadlineExceeded)
+	case codes.Canceled:
+		return fmt.Errorf("%s, %w", s.Message(), context.Canceled)
+	default:
+		return err
+	}
+}
+
+func errorType(trailer metadata.MD) string {
+	if values := trailer.Get(errorTypeTrailerKey); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,278 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpc implements the CloudProvider interface over gRPC so that CloudProviders can run out-of-process.
+//
+// The protocol is a single gRPC service whose methods mirror the CloudProvider interface. Messages are encoded as
+// JSON using the Kubernetes API types, so a server can be implemented in any language that can speak gRPC with a
+// custom codec. Go implementations can wrap any cloudprovider.CloudProvider with NewServer.
+//
+// Typed CloudProvider errors are mapped to gRPC status codes:
+//   - NodeClaimNotFoundError: NotFound
+//   - InsufficientCapacityError: ResourceExhausted, with the unavailable offerings JSON encoded in the
+//     "karpenter-unavailable-offerings" trailer
+//   - NodeClassNotReadyError: FailedPrecondition
+//   - QuotaExceededError: ResourceExhausted, with the unavailable offerings encoded as for an InsufficientCapacityError
+//     and the "karpenter-error-type" trailer set to "QuotaExceeded"
+//   - ThrottledError: Unavailable, with the "karpenter-error-type" trailer set to "Throttled" and the retry delay
+//     encoded as a duration string in the "karpenter-retry-after" trailer
+//   - InvalidConfigurationError: InvalidArgument
+//   - UnauthorizedError: PermissionDenied (Unauthenticated is also accepted by clients)
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+	"github.com/aws/karpenter-core/pkg/scheduling"
+)
+
+const (
+	// ProtocolVersion is the version of the protocol implemented by this package. Clients and servers must agree on
+	// the protocol version during the handshake before any other method is called.
+	ProtocolVersion = "v1"
+	// ServiceName is the fully qualified name of the gRPC service
+	ServiceName = "karpenter.sh.cloudprovider.v1.CloudProvider"
+	// CodecName is the gRPC content-subtype used to encode messages
+	CodecName = "karpenter-json"
+
+	unavailableOfferingsTrailerKey = "karpenter-unavailable-offerings"
+	errorTypeTrailerKey            = "karpenter-error-type"
+	retryAfterTrailerKey           = "karpenter-retry-after"
+
+	// Typed errors are identified by the error type trailer rather than by their status code, since gRPC itself also
+	// returns codes such as ResourceExhausted and InvalidArgument
+	nodeClaimNotFoundErrorType    = "NodeClaimNotFound"
+	insufficientCapacityErrorType = "InsufficientCapacity"
+	nodeClassNotReadyErrorType    = "NodeClassNotReady"
+	quotaExceededErrorType        = "QuotaExceeded"
+	throttledErrorType            = "Throttled"
+	invalidConfigurationErrorType = "InvalidConfiguration"
+	unauthorizedErrorType         = "Unauthorized"
+
+	// MaxMessageSize is the largest response that the client accepts. Instance types and their offerings can exceed
+	// gRPC's default of 4MB for CloudProviders that support many instance types.
+	MaxMessageSize = 256 * 1024 * 1024
+)
+
+const (
+	handshakeMethod        = "Handshake"
+	createMethod           = "Create"
+	deleteMethod           = "Delete"
+	getMethod              = "Get"
+	listMethod             = "List"
+	getInstanceTypesMethod = "GetInstanceTypes"
+	isDriftedMethod        = "IsDrifted"
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// codec encodes gRPC messages as JSON
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return CodecName
+}
+
+type HandshakeRequest struct {
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+type HandshakeResponse struct {
+	ProtocolVersion string `json:"protocolVersion"`
+	// Name is the name of the CloudProvider implementation
+	Name string `json:"name"`
+}
+
+type CreateRequest struct {
+	NodeClaim *v1beta1.NodeClaim `json:"nodeClaim"`
+}
+
+type CreateResponse struct {
+	NodeClaim *v1beta1.NodeClaim `json:"nodeClaim"`
+}
+
+type DeleteRequest struct {
+	NodeClaim *v1beta1.NodeClaim `json:"nodeClaim"`
+}
+
+type DeleteResponse struct{}
+
+type GetRequest struct {
+	ProviderID string `json:"providerID"`
+}
+
+type GetResponse struct {
+	NodeClaim *v1beta1.NodeClaim `json:"nodeClaim"`
+}
+
+type ListRequest struct{}
+
+type ListResponse struct {
+	NodeClaims []*v1beta1.NodeClaim `json:"nodeClaims"`
+}
+
+type GetInstanceTypesRequest struct {
+	NodePool *v1beta1.NodePool `json:"nodePool"`
+}
+
+type GetInstanceTypesResponse struct {
+	InstanceTypes []InstanceType `json:"instanceTypes"`
+}
+
+type IsDriftedRequest struct {
+	NodeClaim *v1beta1.NodeClaim `json:"nodeClaim"`
+}
+
+type IsDriftedResponse struct {
+	// Drift is unset if the NodeClaim hasn't drifted
+	Drift *Drift `json:"drift,omitempty"`
+}
+
+// Drift is the wire representation of a cloudprovider.Drift
+type Drift struct {
+	Reason   cloudprovider.DriftReason `json:"reason"`
+	Message  string                    `json:"message,omitempty"`
+	Field    string                    `json:"field,omitempty"`
+	OldValue string                    `json:"oldValue,omitempty"`
+	NewValue string                    `json:"newValue,omitempty"`
+}
+
+// InstanceType is the wire representation of a cloudprovider.InstanceType
+type InstanceType struct {
+	Name         string                                         `json:"name"`
+	Requirements []v1beta1.NodeSelectorRequirementWithMinValues `json:"requirements"`
+	Offerings    []Offering                                     `json:"offerings"`
+	Capacity     v1.ResourceList                                `json:"capacity"`
+	Overhead     Overhead                                       `json:"overhead"`
+}
+
+// Offering is the wire representation of a cloudprovider.Offering
+type Offering struct {
+	CapacityType        string  `json:"capacityType"`
+	Zone                string  `json:"zone"`
+	Price               float64 `json:"price"`
+	Available           bool    `json:"available"`
+	ReservationCapacity int     `json:"reservationCapacity,omitempty"`
+}
+
+// Overhead is the wire representation of a cloudprovider.InstanceTypeOverhead
+type Overhead struct {
+	KubeReserved      v1.ResourceList `json:"kubeReserved,omitempty"`
+	SystemReserved    v1.ResourceList `json:"systemReserved,omitempty"`
+	EvictionThreshold v1.ResourceList `json:"evictionThreshold,omitempty"`
+}
+
+// UnavailableOffering is the wire representation of a cloudprovider.UnavailableOffering
+type UnavailableOffering struct {
+	InstanceType string `json:"instanceType"`
+	Zone         string `json:"zone"`
+	CapacityType string `json:"capacityType"`
+}
+
+func newInstanceType(it *cloudprovider.InstanceType) InstanceType {
+	instanceType := InstanceType{
+		Name:         it.Name,
+		Requirements: it.Requirements.NodeSelectorRequirements(),
+		Capacity:     it.Capacity,
+	}
+	for _, o := range it.Offerings {
+		instanceType.Offerings = append(instanceType.Offerings, Offering(o))
+	}
+	if it.Overhead != nil {
+		instanceType.Overhead = Overhead(*it.Overhead)
+	}
+	return instanceType
+}
+
+func (in InstanceType) toInstanceType() *cloudprovider.InstanceType {
+	it := &cloudprovider.InstanceType{
+		Name:         in.Name,
+		Requirements: scheduling.NewNodeSelectorRequirementsWithMinValues(in.Requirements...),
+		Capacity:     in.Capacity,
+		Overhead:     &cloudprovider.InstanceTypeOverhead{},
+	}
+	for _, o := range in.Offerings {
+		it.Offerings = append(it.Offerings, cloudprovider.Offering(o))
+	}
+	*it.Overhead = cloudprovider.InstanceTypeOverhead(in.Overhead)
+	return it
+}
+
+// cloudProviderServer is the interface that servers of the CloudProvider service implement
+type cloudProviderServer interface {
+	Handshake(context.Context, *HandshakeRequest) (*HandshakeResponse, error)
+	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	GetInstanceTypes(context.Context, *GetInstanceTypesRequest) (*GetInstanceTypesResponse, error)
+	IsDrifted(context.Context, *IsDriftedRequest) (*IsDriftedResponse, error)
+}
+
+// serviceDesc describes the CloudProvider service. It's the equivalent of the descriptor that protoc would generate
+// for a service definition.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*cloudProviderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod(handshakeMethod, cloudProviderServer.Handshake),
+		unaryMethod(createMethod, cloudProviderServer.Create),
+		unaryMethod(deleteMethod, cloudProviderServer.Delete),
+		unaryMethod(getMethod, cloudProviderServer.Get),
+		unaryMethod(listMethod, cloudProviderServer.List),
+		unaryMethod(getInstanceTypesMethod, cloudProviderServer.GetInstanceTypes),
+		unaryMethod(isDriftedMethod, cloudProviderServer.IsDrifted),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+func unaryMethod[Req, Resp any](name string, handler func(cloudProviderServer, context.Context, *Req) (*Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return handler(srv.(cloudProviderServer), ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethodName(name)}
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return handler(srv.(cloudProviderServer), ctx, req.(*Req))
+			})
+		},
+	}
+}
+
+func fullMethodName(method string) string {
+	return "/" + ServiceName + "/" + method
+}
@@ -0,0 +1,177 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/samber/lo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+)
+
+// Server serves a CloudProvider over gRPC
+type Server struct {
+	cloudProvider cloudprovider.CloudProvider
+}
+
+func NewServer(cloudProvider cloudprovider.CloudProvider) *Server {
+	return &Server{cloudProvider: cloudProvider}
+}
+
+// Register registers the CloudProvider service with a gRPC server
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	registrar.RegisterService(&serviceDesc, s)
+}
+
+func (s *Server) Handshake(_ context.Context, req *HandshakeRequest) (*HandshakeResponse, error) {
+	if req.ProtocolVersion != ProtocolVersion {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported protocol version %q, expected %q", req.ProtocolVersion, ProtocolVersion)
+	}
+	return &HandshakeResponse{ProtocolVersion: ProtocolVersion, Name: s.cloudProvider.Name()}, nil
+}
+
+func (s *Server) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
+	nodeClaim, err := s.cloudProvider.Create(ctx, req.NodeClaim)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &CreateResponse{NodeClaim: nodeClaim}, nil
+}
+
+func (s *Server) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	if err := s.cloudProvider.Delete(ctx, req.NodeClaim); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &DeleteResponse{}, nil
+}
+
+func (s *Server) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	nodeClaim, err := s.cloudProvider.Get(ctx, req.ProviderID)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &GetResponse{NodeClaim: nodeClaim}, nil
+}
+
+func (s *Server) List(ctx context.Context, _ *ListRequest) (*ListResponse, error) {
+	nodeClaims, err := s.cloudProvider.List(ctx)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &ListResponse{NodeClaims: nodeClaims}, nil
+}
+
+func (s *Server) GetInstanceTypes(ctx context.Context, req *GetInstanceTypesRequest) (*GetInstanceTypesResponse, error) {
+	instanceTypes, err := s.cloudProvider.GetInstanceTypes(ctx, req.NodePool)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &GetInstanceTypesResponse{
+		InstanceTypes: lo.Map(instanceTypes, func(it *cloudprovider.InstanceType, _ int) InstanceType { return newInstanceType(it) }),
+	}, nil
+}
+
+func (s *Server) IsDrifted(ctx context.Context, req *IsDriftedRequest) (*IsDriftedResponse, error) {
+	drift, err := s.cloudProvider.IsDrifted(ctx, req.NodeClaim)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if drift == nil {
+		return &IsDriftedResponse{}, nil
+	}
+	return &IsDriftedResponse{Drift: lo.ToPtr(Drift(*drift))}, nil
+}
+
+// toStatus converts an error returned by the CloudProvider into a gRPC status so that its type can be recovered by
+// the client. The type is sent in a trailer, since the status code alone is ambiguous. The status message is the
+// message of the underlying error, without the prefix added by its type.
+func toStatus(ctx context.Context, err error) error {
+	var nodeClaimNotFoundErr *cloudprovider.NodeClaimNotFoundError
+	var insufficientCapacityErr *cloudprovider.InsufficientCapacityError
+	var nodeClassNotReadyErr *cloudprovider.NodeClassNotReadyError
+	var quotaExceededErr *cloudprovider.QuotaExceededError
+	var throttledErr *cloudprovider.ThrottledError
+	var invalidConfigurationErr *cloudprovider.InvalidConfigurationError
+	var unauthorizedErr *cloudprovider.UnauthorizedError
+	switch {
+	case errors.As(err, &nodeClaimNotFoundErr):
+		return typedStatus(ctx, codes.NotFound, nodeClaimNotFoundErrorType, nodeClaimNotFoundErr)
+	case errors.As(err, &insufficientCapacityErr):
+		if err := setUnavailableOfferingsTrailer(ctx, insufficientCapacityErr.Offerings); err != nil {
+			return err
+		}
+		return typedStatus(ctx, codes.ResourceExhausted, insufficientCapacityErrorType, insufficientCapacityErr)
+	case errors.As(err, &quotaExceededErr):
+		if err := setUnavailableOfferingsTrailer(ctx, quotaExceededErr.Offerings); err != nil {
+			return err
+		}
+		return typedStatus(ctx, codes.ResourceExhausted, quotaExceededErrorType, quotaExceededErr)
+	case errors.As(err, &nodeClassNotReadyErr):
+		return typedStatus(ctx, codes.FailedPrecondition, nodeClassNotReadyErrorType, nodeClassNotReadyErr)
+	case errors.As(err, &throttledErr):
+		if err := grpc.SetTrailer(ctx, metadata.Pairs(retryAfterTrailerKey, throttledErr.RetryAfter.String())); err != nil {
+			return status.Errorf(codes.Internal, "setting trailer, %s", err)
+		}
+		return typedStatus(ctx, codes.Unavailable, throttledErrorType, throttledErr)
+	case errors.As(err, &invalidConfigurationErr):
+		return typedStatus(ctx, codes.InvalidArgument, invalidConfigurationErrorType, invalidConfigurationErr)
+	case errors.As(err, &unauthorizedErr):
+		return typedStatus(ctx, codes.PermissionDenied, unauthorizedErrorType, unauthorizedErr)
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
+
+// typedStatus returns a status for a typed CloudProvider error, setting the error type trailer that identifies it
+func typedStatus(ctx context.Context, code codes.Code, errorType string, err error) error {
+	if err := grpc.SetTrailer(ctx, metadata.Pairs(errorTypeTrailerKey, errorType)); err != nil {
+		return status.Errorf(codes.Internal, "setting trailer, %s", err)
+	}
+	return status.Error(code, unwrappedMessage(err))
+}
+
+func setUnavailableOfferingsTrailer(ctx context.Context, unavailableOfferings []cloudprovider.UnavailableOffering) error {
+	if len(unavailableOfferings) == 0 {
+		return nil
+	}
+	offerings, err := json.Marshal(lo.Map(unavailableOfferings, func(o cloudprovider.UnavailableOffering, _ int) UnavailableOffering {
+		return UnavailableOffering(o)
+	}))
+	if err != nil {
+		return status.Errorf(codes.Internal, "marshaling unavailable offerings, %s", err)
+	}
+	if err = grpc.SetTrailer(ctx, metadata.Pairs(unavailableOfferingsTrailerKey, string(offerings))); err != nil {
+		return status.Errorf(codes.Internal, "setting trailer, %s", err)
+	}
+	return nil
+}
+
+func unwrappedMessage(err error) string {
+	if inner := errors.Unwrap(err); inner != nil {
+		return inner.Error()
+	}
+	return err.Error()
+}
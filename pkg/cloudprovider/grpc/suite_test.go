@@ -0,0 +1,264 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+	"github.com/aws/karpenter-core/pkg/cloudprovider/fake"
+	cloudprovidergrpc "github.com/aws/karpenter-core/pkg/cloudprovider/grpc"
+	"github.com/aws/karpenter-core/pkg/test"
+)
+
+var ctx context.Context
+var fakeCloudProvider *fake.CloudProvider
+var server *grpc.Server
+var conn *grpc.ClientConn
+var cloudProvider *cloudprovidergrpc.CloudProvider
+
+func TestGRPC(t *testing.T) {
+	ctx = context.Background()
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CloudProvider gRPC Suite")
+}
+
+var _ = BeforeSuite(func() {
+	fakeCloudProvider = fake.NewCloudProvider()
+	listener := bufconn.Listen(1024 * 1024)
+	server = grpc.NewServer()
+	cloudprovidergrpc.NewServer(&slowCloudProvider{CloudProvider: fakeCloudProvider}).Register(server)
+	go func() {
+		defer GinkgoRecover()
+		Expect(server.Serve(listener)).To(Succeed())
+	}()
+	conn = lo.Must(grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	))
+	cloudProvider = lo.Must(cloudprovidergrpc.NewCloudProvider(ctx, conn, time.Second))
+})
+
+var _ = AfterSuite(func() {
+	Expect(conn.Close()).To(Succeed())
+	server.Stop()
+})
+
+var _ = BeforeEach(func() {
+	fakeCloudProvider.Reset()
+})
+
+var _ = Describe("CloudProvider", func() {
+	var nodePool *v1beta1.NodePool
+	var nodeClaim *v1beta1.NodeClaim
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		nodeClaim = test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+			},
+		})
+	})
+	It("should return the name of the remote CloudProvider from the handshake", func() {
+		Expect(cloudProvider.Name()).To(Equal("fake"))
+	})
+	It("should fail the handshake when the protocol versions don't match", func() {
+		_, err := cloudprovidergrpc.NewServer(fakeCloudProvider).Handshake(ctx, &cloudprovidergrpc.HandshakeRequest{ProtocolVersion: "v0"})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+	})
+	It("should create NodeClaims", func() {
+		created, err := cloudProvider.Create(ctx, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created.Name).To(Equal(nodeClaim.Name))
+		Expect(created.Status.ProviderID).ToNot(BeEmpty())
+		Expect(created.Labels).To(HaveKey(v1.LabelInstanceTypeStable))
+		Expect(fakeCloudProvider.CreateCalls).To(HaveLen(1))
+	})
+	It("should get NodeClaims", func() {
+		created := lo.Must(cloudProvider.Create(ctx, nodeClaim))
+		retrieved, err := cloudProvider.Get(ctx, created.Status.ProviderID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(retrieved.Status.ProviderID).To(Equal(created.Status.ProviderID))
+	})
+	It("should list NodeClaims", func() {
+		lo.Must(cloudProvider.Create(ctx, nodeClaim))
+		nodeClaims, err := cloudProvider.List(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodeClaims).To(HaveLen(1))
+	})
+	It("should delete NodeClaims", func() {
+		created := lo.Must(cloudProvider.Create(ctx, nodeClaim))
+		Expect(cloudProvider.Delete(ctx, created)).To(Succeed())
+		Expect(fakeCloudProvider.DeleteCalls).To(HaveLen(1))
+		Expect(fakeCloudProvider.CreatedNodeClaims).To(BeEmpty())
+	})
+	It("should return how NodeClaims are drifted", func() {
+		fakeCloudProvider.DriftDetails = cloudprovider.Drift{Message: "image changed", Field: "spec.image", OldValue: "image-1", NewValue: "image-2"}
+		drift, err := cloudProvider.IsDrifted(ctx, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drift).To(Equal(&cloudprovider.Drift{Reason: "drifted", Message: "image changed", Field: "spec.image", OldValue: "image-1", NewValue: "image-2"}))
+	})
+	It("should return no drift when NodeClaims aren't drifted", func() {
+		fakeCloudProvider.Drifted = ""
+		drift, err := cloudProvider.IsDrifted(ctx, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drift).To(BeNil())
+	})
+	It("should get instance types", func() {
+		fakeCloudProvider.InstanceTypes = fake.InstanceTypesAssorted()
+		instanceTypes, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instanceTypes).To(HaveLen(len(fakeCloudProvider.InstanceTypes)))
+		for i, it := range instanceTypes {
+			expected := fakeCloudProvider.InstanceTypes[i]
+			Expect(it.Name).To(Equal(expected.Name))
+			Expect(it.Offerings).To(Equal(expected.Offerings))
+			Expect(it.Requirements.Compatible(expected.Requirements)).To(Succeed())
+			Expect(it.Requirements.Keys()).To(Equal(expected.Requirements.Keys()))
+			for resourceName, quantity := range expected.Allocatable() {
+				actual := it.Allocatable()[resourceName]
+				Expect(actual.Cmp(quantity)).To(BeZero(), string(resourceName))
+			}
+		}
+	})
+	It("should get instance types that are larger than gRPC's default maximum message size", func() {
+		fakeCloudProvider.InstanceTypes = fake.InstanceTypes(5000)
+		instanceTypes, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instanceTypes).To(HaveLen(5000))
+	})
+	It("should get the remaining reservations of reserved offerings", func() {
+		fakeCloudProvider.InstanceTypes = []*cloudprovider.InstanceType{fake.NewInstanceType(fake.InstanceTypeOptions{
+			Name: "reserved-instance-type",
+			Offerings: []cloudprovider.Offering{
+				{CapacityType: v1beta1.CapacityTypeReserved, Zone: "test-zone-1", Price: 0, Available: true, ReservationCapacity: 3},
+				{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 1.0, Available: true},
+			},
+		})}
+		instanceTypes, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instanceTypes).To(HaveLen(1))
+		Expect(instanceTypes[0].Offerings).To(Equal(fakeCloudProvider.InstanceTypes[0].Offerings))
+	})
+	Context("Errors", func() {
+		It("should return a NodeClaimNotFoundError when the NodeClaim doesn't exist", func() {
+			_, err := cloudProvider.Get(ctx, test.RandomProviderID())
+			Expect(cloudprovider.IsNodeClaimNotFoundError(err)).To(BeTrue())
+			Expect(err.Error()).To(HavePrefix("nodeclaim not found, no nodeclaim exists"))
+
+			err = cloudProvider.Delete(ctx, nodeClaim)
+			Expect(cloudprovider.IsNodeClaimNotFoundError(err)).To(BeTrue())
+		})
+		It("should return an InsufficientCapacityError with the unavailable offerings", func() {
+			offering := cloudprovider.UnavailableOffering{InstanceType: "test-instance-type", Zone: "test-zone-1", CapacityType: v1beta1.CapacityTypeSpot}
+			fakeCloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(errors.New("no capacity"), offering)
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(cloudprovider.IsInsufficientCapacityError(err)).To(BeTrue())
+			Expect(err.Error()).To(Equal("insufficient capacity, no capacity"))
+			Expect(cloudprovider.UnavailableOfferingsFromError(err)).To(ConsistOf(offering))
+		})
+		It("should return a NodeClassNotReadyError when the NodeClass isn't ready", func() {
+			fakeCloudProvider.ErrorsForNodePool[nodePool.Name] = cloudprovider.NewNodeClassNotReadyError(errors.New("not ready"))
+			_, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
+			Expect(cloudprovider.IsNodeClassNotReadyError(err)).To(BeTrue())
+			Expect(err.Error()).To(Equal("NodeClassRef not ready, not ready"))
+		})
+		It("should return a QuotaExceededError with the unavailable offerings", func() {
+			offering := cloudprovider.UnavailableOffering{InstanceType: "test-instance-type", Zone: "test-zone-1", CapacityType: v1beta1.CapacityTypeOnDemand}
+			fakeCloudProvider.NextCreateErr = cloudprovider.NewQuotaExceededError(errors.New("vcpu limit reached"), offering)
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(cloudprovider.IsQuotaExceededError(err)).To(BeTrue())
+			Expect(cloudprovider.IsInsufficientCapacityError(err)).To(BeFalse())
+			Expect(err.Error()).To(Equal("quota exceeded, vcpu limit reached"))
+			Expect(cloudprovider.UnavailableOfferingsFromError(err)).To(ConsistOf(offering))
+		})
+		It("should return a ThrottledError with the retry delay", func() {
+			fakeCloudProvider.NextCreateErr = cloudprovider.NewThrottledError(errors.New("rate exceeded"), 5*time.Second)
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(cloudprovider.IsThrottledError(err)).To(BeTrue())
+			Expect(err.Error()).To(Equal("throttled, rate exceeded"))
+			var throttledErr *cloudprovider.ThrottledError
+			Expect(errors.As(err, &throttledErr)).To(BeTrue())
+			Expect(throttledErr.RetryAfter).To(Equal(5 * time.Second))
+		})
+		It("should return an InvalidConfigurationError when the configuration is invalid", func() {
+			fakeCloudProvider.NextCreateErr = cloudprovider.NewInvalidConfigurationError(errors.New("image not found"))
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(cloudprovider.IsInvalidConfigurationError(err)).To(BeTrue())
+			Expect(err.Error()).To(Equal("invalid configuration, image not found"))
+		})
+		It("should return an UnauthorizedError when the call isn't authorized", func() {
+			fakeCloudProvider.NextCreateErr = cloudprovider.NewUnauthorizedError(errors.New("access denied"))
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(cloudprovider.IsUnauthorizedError(err)).To(BeTrue())
+			Expect(err.Error()).To(Equal("unauthorized, access denied"))
+		})
+		It("should return untyped errors as-is", func() {
+			fakeCloudProvider.NextCreateErr = errors.New("unknown error")
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(err).To(HaveOccurred())
+			Expect(cloudprovider.IsNodeClaimNotFoundError(err)).To(BeFalse())
+			Expect(cloudprovider.IsInsufficientCapacityError(err)).To(BeFalse())
+			Expect(cloudprovider.IsNodeClassNotReadyError(err)).To(BeFalse())
+			Expect(status.Convert(err).Message()).To(Equal("unknown error"))
+		})
+		It("should not convert statuses that gRPC returns for its own failures into typed errors", func() {
+			// the server rejects requests larger than its default maximum message size with ResourceExhausted
+			nodeClaim.Annotations = map[string]string{"large": strings.Repeat("a", 5*1024*1024)}
+			_, err := cloudProvider.Create(ctx, nodeClaim)
+			Expect(err).To(HaveOccurred())
+			Expect(status.Code(err)).To(Equal(codes.ResourceExhausted))
+			Expect(cloudprovider.IsInsufficientCapacityError(err)).To(BeFalse())
+			Expect(cloudprovider.IsQuotaExceededError(err)).To(BeFalse())
+		})
+		It("should return an error when a call exceeds its deadline", func() {
+			_, err := cloudProvider.Get(ctx, slowProviderID)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		})
+	})
+})
+
+// slowProviderID is a provider id that the slowCloudProvider will never respond to before its context is done
+const slowProviderID = "fake:///slow"
+
+type slowCloudProvider struct {
+	*fake.CloudProvider
+}
+
+func (c *slowCloudProvider) Get(ctx context.Context, id string) (*v1beta1.NodeClaim, error) {
+	if id == slowProviderID {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return c.CloudProvider.Get(ctx, id)
+}
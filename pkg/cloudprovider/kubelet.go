@@ -0,0 +1,136 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/utils/resources"
+)
+
+// evictionSignalResources maps the eviction signals that reduce the allocatable of a node to the resource that they
+// reduce. The remaining signals (e.g. imagefs, inodes and pids) don't affect any resource that pods request.
+var evictionSignalResources = map[string]v1.ResourceName{
+	"memory.available": v1.ResourceMemory,
+	"nodefs.available": v1.ResourceEphemeralStorage,
+}
+
+// ApplyKubeletConfiguration returns the instance types with the pods capacity and overhead that kubelet computes the
+// allocatable of a node from when it's configured with the KubeletConfiguration. The values of the KubeletConfiguration
+// take precedence over the values returned by the CloudProvider, so that every CloudProvider gets the same behavior.
+// Pods are evicted once the soft eviction threshold is crossed, so the eviction threshold of a resource is the larger
+// of its hard and soft thresholds.
+// Applying the same KubeletConfiguration again is a no-op, so CloudProviders that already apply it are unaffected.
+// Instance types are copied when they are changed, so that instance types shared with other callers are never mutated.
+func ApplyKubeletConfiguration(instanceTypes []*InstanceType, kubelet *v1beta1.KubeletConfiguration) []*InstanceType {
+	if kubelet == nil || (kubelet.MaxPods == nil && kubelet.PodsPerCore == nil && len(kubelet.KubeReserved) == 0 &&
+		len(kubelet.SystemReserved) == 0 && len(kubelet.EvictionHard) == 0 && len(kubelet.EvictionSoft) == 0) {
+		return instanceTypes
+	}
+	ret := make([]*InstanceType, 0, len(instanceTypes))
+	for _, it := range instanceTypes {
+		overhead := &InstanceTypeOverhead{}
+		if it.Overhead != nil {
+			overhead.KubeReserved = it.Overhead.KubeReserved.DeepCopy()
+			overhead.SystemReserved = it.Overhead.SystemReserved.DeepCopy()
+			overhead.EvictionThreshold = it.Overhead.EvictionThreshold.DeepCopy()
+		}
+		applied := &InstanceType{
+			Name:         it.Name,
+			Requirements: it.Requirements,
+			Offerings:    it.Offerings,
+			Capacity:     it.Capacity.DeepCopy(),
+			Overhead:     overhead,
+		}
+		if pods, ok := podsCapacity(it.Capacity, kubelet); ok {
+			applied.Capacity[v1.ResourcePods] = pods
+		}
+		overhead.KubeReserved = overrideResources(overhead.KubeReserved, kubelet.KubeReserved)
+		overhead.SystemReserved = overrideResources(overhead.SystemReserved, kubelet.SystemReserved)
+		overhead.EvictionThreshold = overrideResources(overhead.EvictionThreshold, resources.MaxResources(
+			evictionThreshold(it.Capacity, kubelet.EvictionHard),
+			evictionThreshold(it.Capacity, kubelet.EvictionSoft),
+		))
+		ret = append(ret, applied)
+	}
+	return ret
+}
+
+// podsCapacity returns the number of pods that kubelet allows on the instance type. Like kubelet, podsPerCore is
+// bounded by maxPods, or by the pods capacity of the instance type if maxPods isn't set, and is disabled when zero.
+func podsCapacity(capacity v1.ResourceList, kubelet *v1beta1.KubeletConfiguration) (resource.Quantity, bool) {
+	pods, ok := capacity[v1.ResourcePods]
+	if kubelet.MaxPods != nil {
+		pods, ok = *resource.NewQuantity(int64(*kubelet.MaxPods), resource.DecimalSI), true
+	}
+	if kubelet.PodsPerCore != nil && *kubelet.PodsPerCore > 0 {
+		cpu := capacity[v1.ResourceCPU]
+		perCore := resource.NewQuantity(int64(*kubelet.PodsPerCore)*cpu.Value(), resource.DecimalSI)
+		if !ok || perCore.Cmp(pods) < 0 {
+			pods, ok = *perCore, true
+		}
+	}
+	return pods, ok
+}
+
+// evictionThreshold returns the resources reserved by the eviction thresholds, which are either a quantity or a
+// percentage of the capacity of the instance type. Thresholds that can't be parsed are ignored, which only happens if
+// they bypassed validation.
+func evictionThreshold(capacity v1.ResourceList, thresholds map[string]string) v1.ResourceList {
+	ret := v1.ResourceList{}
+	for signal, value := range thresholds {
+		resourceName, ok := evictionSignalResources[signal]
+		if !ok {
+			continue
+		}
+		var threshold resource.Quantity
+		if strings.HasSuffix(value, "%") {
+			pct, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+			if err != nil || pct < 0 || pct > 100 {
+				continue
+			}
+			c := capacity[resourceName]
+			threshold = *resource.NewQuantity(int64(math.Ceil(float64(c.Value())*pct/100)), resource.BinarySI)
+		} else {
+			q, err := resource.ParseQuantity(value)
+			if err != nil || q.Sign() < 0 {
+				continue
+			}
+			threshold = q
+		}
+		ret[resourceName] = threshold
+	}
+	return ret
+}
+
+// overrideResources returns the base resources with each of the overrides taking precedence
+func overrideResources(base, overrides v1.ResourceList) v1.ResourceList {
+	if len(overrides) == 0 {
+		return base
+	}
+	if base == nil {
+		base = v1.ResourceList{}
+	}
+	for resourceName, quantity := range overrides {
+		base[resourceName] = quantity.DeepCopy()
+	}
+	return base
+}
@@ -38,6 +38,10 @@ const (
 	NodeClaimNotFoundError    = "NodeClaimNotFoundError"
 	NodeClassNotReadyError    = "NodeClassNotReadyError"
 	InsufficientCapacityError = "InsufficientCapacityError"
+	QuotaExceededError        = "QuotaExceededError"
+	ThrottledError            = "ThrottledError"
+	InvalidConfigurationError = "InvalidConfigurationError"
+	UnauthorizedError         = "UnauthorizedError"
 )
 
 // decorator implements CloudProvider
@@ -89,10 +93,35 @@ type decorator struct {
 //
 // Do not decorate a `CloudProvider` multiple times or published metrics will contain
 // duplicated method call counts and latencies.
+//
+// If `cloudProvider` implements `BatchCreator`, the returned `CloudProvider` does too.
 func Decorate(cloudProvider cloudprovider.CloudProvider) cloudprovider.CloudProvider {
+	if batchCreator, ok := cloudProvider.(cloudprovider.BatchCreator); ok {
+		return &batchCreatorDecorator{decorator: &decorator{cloudProvider}, batchCreator: batchCreator}
+	}
 	return &decorator{cloudProvider}
 }
 
+// batchCreatorDecorator implements CloudProvider and BatchCreator
+var _ cloudprovider.BatchCreator = (*batchCreatorDecorator)(nil)
+
+type batchCreatorDecorator struct {
+	*decorator
+	batchCreator cloudprovider.BatchCreator
+}
+
+func (d *batchCreatorDecorator) CreateBatch(ctx context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	method := "CreateBatch"
+	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d.decorator, method)))()
+	created, errs := d.batchCreator.CreateBatch(ctx, nodeClaims)
+	for _, err := range errs {
+		if err != nil {
+			errorsTotalCounter.With(getLabelsMapForError(ctx, d.decorator, method, err)).Inc()
+		}
+	}
+	return created, errs
+}
+
 func (d *decorator) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
 	method := "Create"
 	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d, method)))()
@@ -143,7 +172,7 @@ func (d *decorator) GetInstanceTypes(ctx context.Context, nodePool *v1beta1.Node
 	return instanceType, err
 }
 
-func (d *decorator) IsDrifted(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (cloudprovider.DriftReason, error) {
+func (d *decorator) IsDrifted(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*cloudprovider.Drift, error) {
 	method := "IsDrifted"
 	defer metrics.Measure(methodDurationHistogramVec.With(getLabelsMapForDuration(ctx, d, method)))()
 	isDrifted, err := d.CloudProvider.IsDrifted(ctx, nodeClaim)
@@ -184,6 +213,14 @@ func GetErrorTypeLabelValue(err error) string {
 		return NodeClaimNotFoundError
 	case cloudprovider.IsNodeClassNotReadyError(err):
 		return NodeClassNotReadyError
+	case cloudprovider.IsQuotaExceededError(err):
+		return QuotaExceededError
+	case cloudprovider.IsThrottledError(err):
+		return ThrottledError
+	case cloudprovider.IsInvalidConfigurationError(err):
+		return InvalidConfigurationError
+	case cloudprovider.IsUnauthorizedError(err):
+		return UnauthorizedError
 	default:
 		return MetricLabelErrorDefaultVal
 	}
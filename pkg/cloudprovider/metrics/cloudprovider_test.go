@@ -15,19 +15,29 @@ limitations under the License.
 package metrics_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
 	"github.com/aws/karpenter-core/pkg/cloudprovider"
+	"github.com/aws/karpenter-core/pkg/cloudprovider/fake"
 	"github.com/aws/karpenter-core/pkg/cloudprovider/metrics"
+	"github.com/aws/karpenter-core/pkg/test"
 )
 
 var _ = Describe("Cloudprovider", func() {
 	var nodeClaimNotFoundErr = cloudprovider.NewNodeClaimNotFoundError(errors.New("not found"))
 	var insufficientCapacityErr = cloudprovider.NewInsufficientCapacityError(errors.New("not enough capacity"))
 	var nodeClassNotReadyErr = cloudprovider.NewNodeClassNotReadyError(errors.New("not ready"))
+	var quotaExceededErr = cloudprovider.NewQuotaExceededError(errors.New("quota exceeded"))
+	var throttledErr = cloudprovider.NewThrottledError(errors.New("rate exceeded"), time.Second)
+	var invalidConfigurationErr = cloudprovider.NewInvalidConfigurationError(errors.New("image not found"))
+	var unauthorizedErr = cloudprovider.NewUnauthorizedError(errors.New("access denied"))
 	var unknownErr = errors.New("this is an error we don't know about")
 
 	Describe("CloudProvider machine errors via GetErrorTypeLabelValue()", func() {
@@ -41,6 +51,21 @@ var _ = Describe("Cloudprovider", func() {
 			It("nodeclass not ready should be recognized", func() {
 				Expect(metrics.GetErrorTypeLabelValue(nodeClassNotReadyErr)).To(Equal(metrics.NodeClassNotReadyError))
 			})
+			It("quota exceeded should be recognized", func() {
+				Expect(metrics.GetErrorTypeLabelValue(quotaExceededErr)).To(Equal(metrics.QuotaExceededError))
+			})
+			It("throttled should be recognized", func() {
+				Expect(metrics.GetErrorTypeLabelValue(throttledErr)).To(Equal(metrics.ThrottledError))
+			})
+			It("invalid configuration should be recognized", func() {
+				Expect(metrics.GetErrorTypeLabelValue(invalidConfigurationErr)).To(Equal(metrics.InvalidConfigurationError))
+			})
+			It("unauthorized should be recognized", func() {
+				Expect(metrics.GetErrorTypeLabelValue(unauthorizedErr)).To(Equal(metrics.UnauthorizedError))
+			})
+			It("wrapped errors should be recognized", func() {
+				Expect(metrics.GetErrorTypeLabelValue(fmt.Errorf("launching nodeclaim, %w", throttledErr))).To(Equal(metrics.ThrottledError))
+			})
 		})
 		Context("when the error is unknown", func() {
 			It("should always return empty string", func() {
@@ -48,4 +73,26 @@ var _ = Describe("Cloudprovider", func() {
 			})
 		})
 	})
+	Describe("Decorate", func() {
+		It("should implement BatchCreator when the decorated CloudProvider does", func() {
+			decorated := metrics.Decorate(&batchCloudProvider{CloudProvider: fake.NewCloudProvider()})
+			batchCreator, ok := decorated.(cloudprovider.BatchCreator)
+			Expect(ok).To(BeTrue())
+			created, errs := batchCreator.CreateBatch(context.Background(), []*v1beta1.NodeClaim{test.NodeClaim(), test.NodeClaim()})
+			Expect(created).To(HaveLen(2))
+			Expect(errs).To(HaveLen(2))
+		})
+		It("should not implement BatchCreator when the decorated CloudProvider doesn't", func() {
+			_, ok := metrics.Decorate(fake.NewCloudProvider()).(cloudprovider.BatchCreator)
+			Expect(ok).To(BeFalse())
+		})
+	})
 })
+
+type batchCloudProvider struct {
+	*fake.CloudProvider
+}
+
+func (c *batchCloudProvider) CreateBatch(_ context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	return nodeClaims, make([]error, len(nodeClaims))
+}
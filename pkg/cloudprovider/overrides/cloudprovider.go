@@ -0,0 +1,202 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package overrides
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+	"github.com/aws/karpenter-core/pkg/scheduling"
+)
+
+// decorator implements CloudProvider
+var _ cloudprovider.CloudProvider = (*decorator)(nil)
+
+type decorator struct {
+	cloudprovider.CloudProvider
+
+	kubeClient client.Client
+}
+
+// Decorate returns a new `CloudProvider` instance that will delegate all method calls to the argument,
+// `cloudProvider`, and apply the InstanceTypeOverrides in the cluster to the instance types returned by
+// GetInstanceTypes, so that scheduling and disruption decisions use the adjusted prices and capacities.
+//
+// Decorate the CloudProvider that's passed to both the controllers and the cluster state. When the instance types are
+// also cached, decorate the cache rather than caching the decorator, so that changes to overrides take effect
+// immediately.
+//
+// If `cloudProvider` implements `BatchCreator`, the returned `CloudProvider` does too.
+func Decorate(cloudProvider cloudprovider.CloudProvider, kubeClient client.Client) cloudprovider.CloudProvider {
+	d := &decorator{
+		CloudProvider: cloudProvider,
+		kubeClient:    kubeClient,
+	}
+	if batchCreator, ok := cloudProvider.(cloudprovider.BatchCreator); ok {
+		return &batchCreatorDecorator{decorator: d, batchCreator: batchCreator}
+	}
+	return d
+}
+
+// batchCreatorDecorator implements CloudProvider and BatchCreator
+var _ cloudprovider.BatchCreator = (*batchCreatorDecorator)(nil)
+
+type batchCreatorDecorator struct {
+	*decorator
+	batchCreator cloudprovider.BatchCreator
+}
+
+func (d *batchCreatorDecorator) CreateBatch(ctx context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	return d.batchCreator.CreateBatch(ctx, nodeClaims)
+}
+
+func (d *decorator) GetInstanceTypes(ctx context.Context, nodePool *v1beta1.NodePool) ([]*cloudprovider.InstanceType, error) {
+	instanceTypes, err := d.CloudProvider.GetInstanceTypes(ctx, nodePool)
+	if err != nil {
+		return nil, err
+	}
+	overrideList := &v1beta1.InstanceTypeOverrideList{}
+	if err := d.kubeClient.List(ctx, overrideList); err != nil {
+		return nil, fmt.Errorf("listing instance type overrides, %w", err)
+	}
+	return Apply(ctx, instanceTypes, overrideList.Items), nil
+}
+
+// Apply returns the passed instance types with the overrides applied. Overrides are applied in order of name, so
+// when multiple overrides set the price of an offering, the last one wins and price adjustments accumulate.
+// Instance types that aren't selected by any override are returned as-is, and the others are copied, so that
+// instance types which are shared with other callers are never mutated.
+func Apply(ctx context.Context, instanceTypes []*cloudprovider.InstanceType, overrides []v1beta1.InstanceTypeOverride) []*cloudprovider.InstanceType {
+	parsed := parseOverrides(ctx, overrides)
+	if len(parsed) == 0 {
+		return instanceTypes
+	}
+	ret := make([]*cloudprovider.InstanceType, 0, len(instanceTypes))
+	for _, it := range instanceTypes {
+		overridden := it
+		for _, o := range parsed {
+			if it.Requirements.Compatible(o.requirements) != nil {
+				continue
+			}
+			if overridden == it {
+				overridden = &cloudprovider.InstanceType{
+					Name:         it.Name,
+					Requirements: it.Requirements,
+					Offerings:    append(cloudprovider.Offerings{}, it.Offerings...),
+					Capacity:     it.Capacity.DeepCopy(),
+					Overhead:     it.Overhead,
+				}
+			}
+			o.apply(overridden)
+		}
+		ret = append(ret, overridden)
+	}
+	return ret
+}
+
+type override struct {
+	requirements scheduling.Requirements
+	price        *float64
+	adjust       func(float64) float64
+	capacity     v1.ResourceList
+}
+
+// parseOverrides converts the overrides into their parsed form, ordered by name. Overrides that can't be parsed are
+// skipped, which only happens if they bypassed validation.
+func parseOverrides(ctx context.Context, overrides []v1beta1.InstanceTypeOverride) []override {
+	sorted := append([]v1beta1.InstanceTypeOverride{}, overrides...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	var parsed []override
+	for i := range sorted {
+		o, err := parseOverride(&sorted[i])
+		if err != nil {
+			logging.FromContext(ctx).With("instancetypeoverride", sorted[i].Name).Errorf("ignoring instance type override, %s", err)
+			continue
+		}
+		parsed = append(parsed, o)
+	}
+	return parsed
+}
+
+func parseOverride(itOverride *v1beta1.InstanceTypeOverride) (override, error) {
+	o := override{
+		requirements: scheduling.NewNodeSelectorRequirements(itOverride.Spec.Requirements...),
+		capacity:     itOverride.Spec.Capacity,
+	}
+	if itOverride.Spec.Price != nil {
+		price, err := strconv.ParseFloat(*itOverride.Spec.Price, 64)
+		if err != nil || price < 0 {
+			return override{}, fmt.Errorf("parsing price %q", *itOverride.Spec.Price)
+		}
+		o.price = &price
+	}
+	if itOverride.Spec.PriceAdjustment != nil {
+		adjust, err := parsePriceAdjustment(*itOverride.Spec.PriceAdjustment)
+		if err != nil {
+			return override{}, err
+		}
+		o.adjust = adjust
+	}
+	return o, nil
+}
+
+// parsePriceAdjustment returns a function that adjusts a price by either a percentage, e.g. "-20%", or an absolute
+// amount, e.g. "-0.01"
+func parsePriceAdjustment(adjustment string) (func(float64) float64, error) {
+	if percentage, ok := strings.CutSuffix(adjustment, "%"); ok {
+		value, err := strconv.ParseFloat(percentage, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing price adjustment %q", adjustment)
+		}
+		return func(price float64) float64 { return math.Max(price*(1+value/100), 0) }, nil
+	}
+	value, err := strconv.ParseFloat(adjustment, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing price adjustment %q", adjustment)
+	}
+	return func(price float64) float64 { return math.Max(price+value, 0) }, nil
+}
+
+// apply applies the override to a copy of an instance type that it selects
+func (o override) apply(it *cloudprovider.InstanceType) {
+	for i := range it.Offerings {
+		// Requirements on the zone and capacity type further restrict the offerings that are priced by the override
+		if len(cloudprovider.Offerings{it.Offerings[i]}.Requirements(o.requirements)) == 0 {
+			continue
+		}
+		if o.price != nil {
+			it.Offerings[i].Price = *o.price
+		}
+		if o.adjust != nil {
+			it.Offerings[i].Price = o.adjust(it.Offerings[i].Price)
+		}
+	}
+	if len(o.capacity) > 0 && it.Capacity == nil {
+		it.Capacity = v1.ResourceList{}
+	}
+	for name, quantity := range o.capacity {
+		it.Capacity[name] = quantity.DeepCopy()
+	}
+}
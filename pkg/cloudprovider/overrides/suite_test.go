@@ -0,0 +1,213 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package overrides_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+	"github.com/aws/karpenter-core/pkg/cloudprovider/fake"
+	"github.com/aws/karpenter-core/pkg/cloudprovider/overrides"
+	"github.com/aws/karpenter-core/pkg/operator/scheme"
+	"github.com/aws/karpenter-core/pkg/test"
+)
+
+var ctx context.Context
+
+func TestOverrides(t *testing.T) {
+	ctx = context.Background()
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CloudProvider Overrides Suite")
+}
+
+var _ = Describe("Overrides", func() {
+	var kubeClient client.Client
+	var cloudProvider *fake.CloudProvider
+	var decorator cloudprovider.CloudProvider
+	var nodePool *v1beta1.NodePool
+
+	BeforeEach(func() {
+		kubeClient = fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		cloudProvider = fake.NewCloudProvider()
+		cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: "small",
+				Offerings: []cloudprovider.Offering{
+					{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 1.0, Available: true},
+					{CapacityType: v1beta1.CapacityTypeSpot, Zone: "test-zone-1", Price: 0.5, Available: true},
+				},
+			}),
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: "large",
+				Offerings: []cloudprovider.Offering{
+					{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 4.0, Available: true},
+				},
+			}),
+		}
+		decorator = overrides.Decorate(cloudProvider, kubeClient)
+		nodePool = test.NodePool()
+	})
+
+	getInstanceType := func(name string) *cloudprovider.InstanceType {
+		instanceTypes, err := decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		it, ok := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool { return it.Name == name })
+		Expect(ok).To(BeTrue())
+		return it
+	}
+	prices := func(it *cloudprovider.InstanceType) []float64 {
+		return lo.Map(it.Offerings, func(o cloudprovider.Offering, _ int) float64 { return o.Price })
+	}
+	selectInstanceType := func(name string) []v1.NodeSelectorRequirement {
+		return []v1.NodeSelectorRequirement{{Key: v1.LabelInstanceTypeStable, Operator: v1.NodeSelectorOpIn, Values: []string{name}}}
+	}
+
+	It("should return the instance types unchanged when there are no overrides", func() {
+		instanceTypes, err := decorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instanceTypes).To(Equal(cloudProvider.InstanceTypes))
+	})
+	It("should override the price of the selected instance types", func() {
+		Expect(kubeClient.Create(ctx, test.InstanceTypeOverride(v1beta1.InstanceTypeOverride{
+			Spec: v1beta1.InstanceTypeOverrideSpec{Requirements: selectInstanceType("large"), Price: lo.ToPtr("2.5")},
+		}))).To(Succeed())
+		Expect(prices(getInstanceType("large"))).To(Equal([]float64{2.5}))
+		Expect(prices(getInstanceType("small"))).To(Equal([]float64{1.0, 0.5}))
+	})
+	It("should only override the price of offerings that match zone and capacity type requirements", func() {
+		Expect(kubeClient.Create(ctx, test.InstanceTypeOverride(v1beta1.InstanceTypeOverride{
+			Spec: v1beta1.InstanceTypeOverrideSpec{
+				Requirements: []v1.NodeSelectorRequirement{{Key: v1beta1.CapacityTypeLabelKey, Operator: v1.NodeSelectorOpIn, Values: []string{v1beta1.CapacityTypeOnDemand}}},
+				Price:        lo.ToPtr("0.1"),
+			},
+		}))).To(Succeed())
+		Expect(prices(getInstanceType("small"))).To(Equal([]float64{0.1, 0.5}))
+		Expect(prices(getInstanceType("large"))).To(Equal([]float64{0.1}))
+	})
+	It("should adjust prices by a percentage", func() {
+		Expect(kubeClient.Create(ctx, test.InstanceTypeOverride(v1beta1.InstanceTypeOverride{
+			Spec: v1beta1.InstanceTypeOverrideSpec{Requirements: selectInstanceType("small"), PriceAdjustment: lo.ToPtr("-20%")},
+		}))).To(Succeed())
+		Expect(prices(getInstanceType("small"))).To(Equal([]float64{0.8, 0.4}))
+	})
+	It("should adjust prices by an absolute amount without going below zero", func() {
+		Expect(kubeClient.Create(ctx, test.InstanceTypeOverride(v1beta1.InstanceTypeOverride{
+			Spec: v1beta1.InstanceTypeOverrideSpec{Requirements: selectInstanceType("small"), PriceAdjustment: lo.ToPtr("-0.75")},
+		}))).To(Succeed())
+		Expect(prices(getInstanceType("small"))).To(Equal([]float64{0.25, 0}))
+	})
+	It("should apply the price adjustment after the price override", func() {
+		Expect(kubeClient.Create(ctx, test.InstanceTypeOverride(v1beta1.InstanceTypeOverride{
+			Spec: v1beta1.InstanceTypeOverrideSpec{Requirements: selectInstanceType("large"), Price: lo.ToPtr("2"), PriceAdjustment: lo.ToPtr("+50%")},
+		}))).To(Succeed())
+		Expect(prices(getInstanceType("large"))).To(Equal([]float64{3}))
+	})
+	It("should apply overrides in order of name", func() {
+		for _, o := range []v1beta1.InstanceTypeOverride{
+			{ObjectMeta: metav1.ObjectMeta{Name: "c"}, Spec: v1beta1.InstanceTypeOverrideSpec{PriceAdjustment: lo.ToPtr("-50%")}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Spec: v1beta1.InstanceTypeOverrideSpec{Price: lo.ToPtr("3")}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Spec: v1beta1.InstanceTypeOverrideSpec{Price: lo.ToPtr("2")}},
+		} {
+			o.Spec.Requirements = selectInstanceType("large")
+			Expect(kubeClient.Create(ctx, test.InstanceTypeOverride(o))).To(Succeed())
+		}
+		Expect(prices(getInstanceType("large"))).To(Equal([]float64{1.5}))
+	})
+	It("should add extended resources to the capacity of the selected instance types", func() {
+		Expect(kubeClient.Create(ctx, test.InstanceTypeOverride(v1beta1.InstanceTypeOverride{
+			Spec: v1beta1.InstanceTypeOverrideSpec{
+				Requirements: selectInstanceType("large"),
+				Capacity: v1.ResourceList{
+					"example.com/device": resource.MustParse("2"),
+					v1.ResourcePods:      resource.MustParse("110"),
+				},
+			},
+		}))).To(Succeed())
+		large := getInstanceType("large")
+		Expect(large.Capacity).To(HaveKeyWithValue(v1.ResourceName("example.com/device"), resource.MustParse("2")))
+		Expect(large.Capacity).To(HaveKeyWithValue(v1.ResourcePods, resource.MustParse("110")))
+		Expect(large.Allocatable()).To(HaveKey(v1.ResourceName("example.com/device")))
+		Expect(getInstanceType("small").Capacity).ToNot(HaveKey(v1.ResourceName("example.com/device")))
+	})
+	It("should not select instance types that don't define a custom label", func() {
+		Expect(kubeClient.Create(ctx, test.InstanceTypeOverride(v1beta1.InstanceTypeOverride{
+			Spec: v1beta1.InstanceTypeOverrideSpec{
+				Requirements: []v1.NodeSelectorRequirement{{Key: "example.com/family", Operator: v1.NodeSelectorOpIn, Values: []string{"m5"}}},
+				Price:        lo.ToPtr("0"),
+			},
+		}))).To(Succeed())
+		Expect(prices(getInstanceType("small"))).To(Equal([]float64{1.0, 0.5}))
+	})
+	It("should not mutate the instance types of the cloudprovider", func() {
+		Expect(kubeClient.Create(ctx, test.InstanceTypeOverride(v1beta1.InstanceTypeOverride{
+			Spec: v1beta1.InstanceTypeOverrideSpec{
+				Requirements: selectInstanceType("small"),
+				Price:        lo.ToPtr("3"),
+				Capacity:     v1.ResourceList{"example.com/device": resource.MustParse("1")},
+			},
+		}))).To(Succeed())
+		Expect(prices(getInstanceType("small"))).To(Equal([]float64{3, 3}))
+		Expect(prices(cloudProvider.InstanceTypes[0])).To(Equal([]float64{1.0, 0.5}))
+		Expect(cloudProvider.InstanceTypes[0].Capacity).ToNot(HaveKey(v1.ResourceName("example.com/device")))
+	})
+	It("should ignore overrides that can't be parsed", func() {
+		Expect(kubeClient.Create(ctx, test.InstanceTypeOverride(v1beta1.InstanceTypeOverride{
+			Spec: v1beta1.InstanceTypeOverrideSpec{Requirements: selectInstanceType("small"), PriceAdjustment: lo.ToPtr("cheaper")},
+		}))).To(Succeed())
+		Expect(prices(getInstanceType("small"))).To(Equal([]float64{1.0, 0.5}))
+	})
+	It("should implement BatchCreator when the decorated CloudProvider does", func() {
+		batchDecorator := overrides.Decorate(&batchCloudProvider{CloudProvider: cloudProvider}, kubeClient)
+		batchCreator, ok := batchDecorator.(cloudprovider.BatchCreator)
+		Expect(ok).To(BeTrue())
+		created, errs := batchCreator.CreateBatch(ctx, []*v1beta1.NodeClaim{test.NodeClaim(), test.NodeClaim()})
+		Expect(created).To(HaveLen(2))
+		Expect(errs).To(HaveLen(2))
+
+		// overrides are still applied
+		Expect(kubeClient.Create(ctx, test.InstanceTypeOverride(v1beta1.InstanceTypeOverride{
+			Spec: v1beta1.InstanceTypeOverrideSpec{Requirements: selectInstanceType("small"), Price: lo.ToPtr("3")},
+		}))).To(Succeed())
+		instanceTypes, err := batchDecorator.GetInstanceTypes(ctx, nodePool)
+		Expect(err).ToNot(HaveOccurred())
+		small, ok := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool { return it.Name == "small" })
+		Expect(ok).To(BeTrue())
+		Expect(prices(small)).To(Equal([]float64{3, 3}))
+	})
+	It("should not implement BatchCreator when the decorated CloudProvider doesn't", func() {
+		_, ok := decorator.(cloudprovider.BatchCreator)
+		Expect(ok).To(BeFalse())
+	})
+})
+
+type batchCloudProvider struct {
+	*fake.CloudProvider
+}
+
+func (c *batchCloudProvider) CreateBatch(_ context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	return nodeClaims, make([]error, len(nodeClaims))
+}
@@ -21,17 +21,46 @@ import (
 	"math"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
 	"github.com/aws/karpenter-core/pkg/scheduling"
 	"github.com/aws/karpenter-core/pkg/utils/resources"
 )
 
+// DriftReason is the category of a Drift. CloudProviders define their own categories, e.g. "ImageDrifted".
 type DriftReason string
 
+// Drift describes how a NodeClaim has drifted from the configuration that it's expected to have
+type Drift struct {
+	// Reason is the category of the drift
+	Reason DriftReason
+	// Message is a human-readable description of the drift
+	Message string
+	// Field is the drifted field, if the drift can be attributed to a single field, e.g. "spec.amiSelectorTerms"
+	Field string
+	// OldValue is the value of the drifted field that the NodeClaim was launched with
+	OldValue string
+	// NewValue is the value of the drifted field that the NodeClaim is expected to have
+	NewValue string
+}
+
+// String returns a human-readable description of the drift that is used in status conditions and events
+func (d *Drift) String() string {
+	msg := lo.Ternary(d.Message != "", d.Message, string(d.Reason))
+	if d.Field == "" {
+		return msg
+	}
+	if d.OldValue == "" && d.NewValue == "" {
+		return fmt.Sprintf("%s, %s changed", msg, d.Field)
+	}
+	return fmt.Sprintf("%s, %s changed from %q to %q", msg, d.Field, d.OldValue, d.NewValue)
+}
+
 // CloudProvider interface is implemented by cloud providers to support provisioning.
 type CloudProvider interface {
 	// Create launches a NodeClaim with the given resource requests and requirements and returns a hydrated
@@ -48,13 +77,23 @@ type CloudProvider interface {
 	// availability, the GetInstanceTypes method should always return all instance types,
 	// even those with no offerings available.
 	GetInstanceTypes(context.Context, *v1beta1.NodePool) ([]*InstanceType, error)
-	// IsDrifted returns whether a NodeClaim has drifted from the provisioning requirements
-	// it is tied to.
-	IsDrifted(context.Context, *v1beta1.NodeClaim) (DriftReason, error)
+	// IsDrifted returns how a NodeClaim has drifted from the provisioning requirements
+	// it is tied to, or nil if it hasn't drifted.
+	IsDrifted(context.Context, *v1beta1.NodeClaim) (*Drift, error)
 	// Name returns the CloudProvider implementation name.
 	Name() string
 }
 
+// BatchCreator is an optional interface that is implemented by cloud providers that can launch multiple NodeClaims in a
+// single call, e.g. through a fleet API. When the CloudProvider implements it, NodeClaims with the same NodeClassRef
+// and requirements that are launched within a short window of each other are launched through a single CreateBatch call.
+type BatchCreator interface {
+	// CreateBatch launches the given NodeClaims and returns a hydrated NodeClaim or an error for each of them. The
+	// returned slices must be the same length as the passed NodeClaims and are matched to them by index. Exactly one
+	// of the NodeClaim and the error at each index should be set.
+	CreateBatch(context.Context, []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error)
+}
+
 type InstanceTypes []*InstanceType
 
 func (its InstanceTypes) OrderByPrice(reqs scheduling.Requirements) InstanceTypes {
@@ -76,6 +115,51 @@ func (its InstanceTypes) OrderByPrice(reqs scheduling.Requirements) InstanceType
 	return its
 }
 
+// SatisfiesMinValues returns the minimum number of instance types, taken in order, that are needed to satisfy the
+// minValues of the requirements. An error is returned if the instance types can't satisfy the minValues of every
+// requirement.
+func (its InstanceTypes) SatisfiesMinValues(requirements scheduling.Requirements) (int, error) {
+	if !requirements.HasMinValues() {
+		return 0, nil
+	}
+	valuesForKey := map[string]sets.Set[string]{}
+	for _, req := range requirements {
+		if req.MinValues != nil {
+			valuesForKey[req.Key] = sets.New[string]()
+		}
+	}
+	for i, it := range its {
+		for key, values := range valuesForKey {
+			values.Insert(it.Requirements.Get(key).Values()...)
+		}
+		if _, ok := unsatisfiedMinValues(requirements, valuesForKey); !ok {
+			return i + 1, nil
+		}
+	}
+	key, _ := unsatisfiedMinValues(requirements, valuesForKey)
+	return len(its), fmt.Errorf("minValues requirement is not met for %q", key)
+}
+
+// unsatisfiedMinValues returns the first key, in order, whose values don't satisfy the minValues of its requirement
+func unsatisfiedMinValues(requirements scheduling.Requirements, valuesForKey map[string]sets.Set[string]) (string, bool) {
+	for _, key := range sets.List(sets.KeySet(valuesForKey)) {
+		if valuesForKey[key].Len() < lo.FromPtr(requirements.Get(key).MinValues) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// Truncate orders the instance types by price and returns at most maxItems of the cheapest. An error is returned if
+// the truncated instance types no longer satisfy the minValues of the requirements.
+func (its InstanceTypes) Truncate(requirements scheduling.Requirements, maxItems int) (InstanceTypes, error) {
+	truncated := InstanceTypes(lo.Slice(its.OrderByPrice(requirements), 0, maxItems))
+	if _, err := truncated.SatisfiesMinValues(requirements); err != nil {
+		return its, fmt.Errorf("validating minValues, %w", err)
+	}
+	return truncated, nil
+}
+
 // InstanceType describes the properties of a potential node (either concrete attributes of an instance of this type
 // or supported options in the case of arrays)
 type InstanceType struct {
@@ -129,6 +213,11 @@ type Offering struct {
 	// Available is added so that Offerings can return all offerings that have ever existed for an instance type,
 	// so we can get historical pricing data for calculating savings in consolidation
 	Available bool
+	// ReservationCapacity is the number of instances that can still be launched into the capacity reservation of an
+	// offering with the reserved capacity type. Reserved offerings should be priced at zero or at their sunk cost, and
+	// should still be returned with Available set to false once their reservations are used up, so that the price of
+	// the instances that were launched into them can be determined.
+	ReservationCapacity int
 }
 
 type Offerings []Offering
@@ -178,6 +267,10 @@ func (e *NodeClaimNotFoundError) Error() string {
 	return fmt.Sprintf("nodeclaim not found, %s", e.error)
 }
 
+func (e *NodeClaimNotFoundError) Unwrap() error {
+	return e.error
+}
+
 func IsNodeClaimNotFoundError(err error) bool {
 	if err == nil {
 		return false
@@ -196,11 +289,16 @@ func IgnoreNodeClaimNotFoundError(err error) error {
 // InsufficientCapacityError is an error type returned by CloudProviders when a launch fails due to a lack of capacity from NodeClaim requirements
 type InsufficientCapacityError struct {
 	error
+	// Offerings are the offerings that the CloudProvider attempted to launch and found to be out of capacity
+	Offerings []UnavailableOffering
 }
 
-func NewInsufficientCapacityError(err error) *InsufficientCapacityError {
+// NewInsufficientCapacityError constructs an InsufficientCapacityError. CloudProviders should pass the offerings
+// that failed to launch so that they are avoided in future scheduling decisions until they expire from the cache
+func NewInsufficientCapacityError(err error, offerings ...UnavailableOffering) *InsufficientCapacityError {
 	return &InsufficientCapacityError{
-		error: err,
+		error:     err,
+		Offerings: offerings,
 	}
 }
 
@@ -208,6 +306,10 @@ func (e *InsufficientCapacityError) Error() string {
 	return fmt.Sprintf("insufficient capacity, %s", e.error)
 }
 
+func (e *InsufficientCapacityError) Unwrap() error {
+	return e.error
+}
+
 func IsInsufficientCapacityError(err error) bool {
 	if err == nil {
 		return false
@@ -216,6 +318,20 @@ func IsInsufficientCapacityError(err error) bool {
 	return errors.As(err, &icErr)
 }
 
+// UnavailableOfferingsFromError returns the offerings that are carried by an InsufficientCapacityError or a
+// QuotaExceededError
+func UnavailableOfferingsFromError(err error) []UnavailableOffering {
+	var icErr *InsufficientCapacityError
+	if errors.As(err, &icErr) {
+		return icErr.Offerings
+	}
+	var qeErr *QuotaExceededError
+	if errors.As(err, &qeErr) {
+		return qeErr.Offerings
+	}
+	return nil
+}
+
 func IgnoreInsufficientCapacityError(err error) error {
 	if IsInsufficientCapacityError(err) {
 		return nil
@@ -238,6 +354,10 @@ func (e *NodeClassNotReadyError) Error() string {
 	return fmt.Sprintf("NodeClassRef not ready, %s", e.error)
 }
 
+func (e *NodeClassNotReadyError) Unwrap() error {
+	return e.error
+}
+
 func IsNodeClassNotReadyError(err error) bool {
 	if err == nil {
 		return false
@@ -252,3 +372,153 @@ func IgnoreNodeClassNotReadyError(err error) error {
 	}
 	return err
 }
+
+// QuotaExceededError is an error type returned by CloudProviders when a launch fails because it would exceed an account
+// or project quota. Unlike an InsufficientCapacityError, the offerings won't become available until the quota is raised
+// or capacity is released, but they are treated the same way during launch.
+type QuotaExceededError struct {
+	error
+	// Offerings are the offerings that the CloudProvider attempted to launch and that exceed the quota
+	Offerings []UnavailableOffering
+}
+
+// NewQuotaExceededError constructs a QuotaExceededError. CloudProviders should pass the offerings that exceed the quota
+// so that they are avoided in future scheduling decisions until they expire from the cache
+func NewQuotaExceededError(err error, offerings ...UnavailableOffering) *QuotaExceededError {
+	return &QuotaExceededError{
+		error:     err,
+		Offerings: offerings,
+	}
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded, %s", e.error)
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return e.error
+}
+
+func IsQuotaExceededError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var qeErr *QuotaExceededError
+	return errors.As(err, &qeErr)
+}
+
+func IgnoreQuotaExceededError(err error) error {
+	if IsQuotaExceededError(err) {
+		return nil
+	}
+	return err
+}
+
+// ThrottledError is an error type returned by CloudProviders when a call is rate limited by the cloud provider API. The
+// call is expected to succeed if it's retried after a backoff.
+type ThrottledError struct {
+	error
+	// RetryAfter is the minimum time to wait before retrying the call, if the cloud provider API specified one
+	RetryAfter time.Duration
+}
+
+func NewThrottledError(err error, retryAfter time.Duration) *ThrottledError {
+	return &ThrottledError{
+		error:      err,
+		RetryAfter: retryAfter,
+	}
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("throttled, %s", e.error)
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return e.error
+}
+
+func IsThrottledError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var tErr *ThrottledError
+	return errors.As(err, &tErr)
+}
+
+func IgnoreThrottledError(err error) error {
+	if IsThrottledError(err) {
+		return nil
+	}
+	return err
+}
+
+// InvalidConfigurationError is an error type returned by CloudProviders when a launch fails due to a configuration that
+// can never succeed, e.g. a NodeClass that references an image that doesn't exist. The call shouldn't be retried until
+// the configuration changes.
+type InvalidConfigurationError struct {
+	error
+}
+
+func NewInvalidConfigurationError(err error) *InvalidConfigurationError {
+	return &InvalidConfigurationError{
+		error: err,
+	}
+}
+
+func (e *InvalidConfigurationError) Error() string {
+	return fmt.Sprintf("invalid configuration, %s", e.error)
+}
+
+func (e *InvalidConfigurationError) Unwrap() error {
+	return e.error
+}
+
+func IsInvalidConfigurationError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var icErr *InvalidConfigurationError
+	return errors.As(err, &icErr)
+}
+
+func IgnoreInvalidConfigurationError(err error) error {
+	if IsInvalidConfigurationError(err) {
+		return nil
+	}
+	return err
+}
+
+// UnauthorizedError is an error type returned by CloudProviders when a call is rejected because the credentials that
+// Karpenter uses are missing, expired or lack the permissions that the call requires
+type UnauthorizedError struct {
+	error
+}
+
+func NewUnauthorizedError(err error) *UnauthorizedError {
+	return &UnauthorizedError{
+		error: err,
+	}
+}
+
+func (e *UnauthorizedError) Error() string {
+	return fmt.Sprintf("unauthorized, %s", e.error)
+}
+
+func (e *UnauthorizedError) Unwrap() error {
+	return e.error
+}
+
+func IsUnauthorizedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var uErr *UnauthorizedError
+	return errors.As(err, &uErr)
+}
+
+func IgnoreUnauthorizedError(err error) error {
+	if IsUnauthorizedError(err) {
+		return nil
+	}
+	return err
+}
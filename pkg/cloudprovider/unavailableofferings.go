@@ -0,0 +1,123 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// UnavailableOfferingsTTL is the time before offerings that were marked as unavailable
+	// are removed from the cache and are available for launch again
+	UnavailableOfferingsTTL = 3 * time.Minute
+	// UnavailableOfferingsCleanupInterval is the interval at which expired offerings are purged from the cache
+	UnavailableOfferingsCleanupInterval = time.Minute
+)
+
+// UnavailableOffering identifies a single offering of an instance type by its zone and capacity type
+type UnavailableOffering struct {
+	InstanceType string
+	Zone         string
+	CapacityType string
+}
+
+func (o UnavailableOffering) key() string {
+	return fmt.Sprintf("%s:%s:%s", o.CapacityType, o.InstanceType, o.Zone)
+}
+
+// UnavailableOfferings stores any offerings that returned an InsufficientCapacityError or a QuotaExceededError when
+// attempting to launch them. These offerings are treated as unavailable by scheduling and consolidation for as long as
+// they are in the cache.
+type UnavailableOfferings struct {
+	cache *cache.Cache
+}
+
+func NewUnavailableOfferings() *UnavailableOfferings {
+	return &UnavailableOfferings{
+		cache: cache.New(UnavailableOfferingsTTL, UnavailableOfferingsCleanupInterval),
+	}
+}
+
+// IsUnavailable returns true if the offering appears in the cache
+func (u *UnavailableOfferings) IsUnavailable(instanceType, zone, capacityType string) bool {
+	_, found := u.cache.Get(UnavailableOffering{InstanceType: instanceType, Zone: zone, CapacityType: capacityType}.key())
+	return found
+}
+
+// MarkUnavailable communicates recently observed temporary capacity shortages in the provided offerings
+func (u *UnavailableOfferings) MarkUnavailable(ctx context.Context, reason string, offerings ...UnavailableOffering) {
+	for _, o := range offerings {
+		// even if the key is already in the cache, we still need to call Set to extend the cached entry's TTL
+		logging.FromContext(ctx).With(
+			"reason", reason,
+			"instance-type", o.InstanceType,
+			"zone", o.Zone,
+			"capacity-type", o.CapacityType,
+			"ttl", UnavailableOfferingsTTL).Debugf("removing offering from offerings")
+		u.cache.SetDefault(o.key(), struct{}{})
+	}
+}
+
+// MarkUnavailableForError marks the offerings carried by an InsufficientCapacityError or a QuotaExceededError as
+// unavailable
+func (u *UnavailableOfferings) MarkUnavailableForError(ctx context.Context, err error) {
+	reason := "InsufficientCapacityError"
+	if IsQuotaExceededError(err) {
+		reason = "QuotaExceededError"
+	}
+	u.MarkUnavailable(ctx, reason, UnavailableOfferingsFromError(err)...)
+}
+
+// Apply returns the passed instance types with any offerings that are in the cache marked as unavailable.
+// Instance types that have no cached offerings are returned as-is so that we only copy what we need to.
+func (u *UnavailableOfferings) Apply(instanceTypes []*InstanceType) []*InstanceType {
+	if u.cache.ItemCount() == 0 {
+		return instanceTypes
+	}
+	ret := make([]*InstanceType, 0, len(instanceTypes))
+	for _, it := range instanceTypes {
+		var offerings Offerings
+		for i, o := range it.Offerings {
+			if o.Available && u.IsUnavailable(it.Name, o.Zone, o.CapacityType) {
+				if offerings == nil {
+					offerings = append(Offerings{}, it.Offerings...)
+				}
+				offerings[i].Available = false
+			}
+		}
+		if offerings == nil {
+			ret = append(ret, it)
+			continue
+		}
+		ret = append(ret, &InstanceType{
+			Name:         it.Name,
+			Requirements: it.Requirements,
+			Offerings:    offerings,
+			Capacity:     it.Capacity,
+			Overhead:     it.Overhead,
+		})
+	}
+	return ret
+}
+
+// Flush removes all offerings from the cache
+func (u *UnavailableOfferings) Flush() {
+	u.cache.Flush()
+}
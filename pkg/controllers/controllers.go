@@ -22,6 +22,7 @@ import (
 	"github.com/aws/karpenter-core/pkg/cloudprovider"
 	"github.com/aws/karpenter-core/pkg/controllers/disruption"
 	"github.com/aws/karpenter-core/pkg/controllers/disruption/orchestration"
+	disruptionrecordgarbagecollection "github.com/aws/karpenter-core/pkg/controllers/disruptionrecord/garbagecollection"
 	"github.com/aws/karpenter-core/pkg/controllers/leasegarbagecollection"
 	metricsnode "github.com/aws/karpenter-core/pkg/controllers/metrics/node"
 	metricsnodepool "github.com/aws/karpenter-core/pkg/controllers/metrics/nodepool"
@@ -51,14 +52,16 @@ func NewControllers(
 	cloudProvider cloudprovider.CloudProvider,
 ) []controller.Controller {
 
-	p := provisioning.NewProvisioner(kubeClient, kubernetesInterface.CoreV1(), recorder, cloudProvider, cluster)
+	unavailableOfferings := cloudprovider.NewUnavailableOfferings()
+	p := provisioning.NewProvisioner(kubeClient, kubernetesInterface.CoreV1(), kubernetesInterface.NodeV1(), recorder, cloudProvider, cluster, unavailableOfferings)
 	evictionQueue := terminator.NewQueue(kubernetesInterface.CoreV1(), recorder)
 	disruptionQueue := orchestration.NewQueue(kubeClient, recorder, cluster, clock, p)
 
 	return []controller.Controller{
 		p, evictionQueue, disruptionQueue,
-		disruption.NewController(clock, kubeClient, p, cloudProvider, recorder, cluster, disruptionQueue),
+		disruption.NewController(clock, kubeClient, p, cloudProvider, recorder, cluster, disruptionQueue, unavailableOfferings),
 		provisioning.NewController(kubeClient, p, recorder),
+		provisioning.NewNodePoolController(kubeClient, p),
 		nodepoolhash.NewNodePoolController(kubeClient),
 		informer.NewDaemonSetController(kubeClient, cluster),
 		informer.NewNodeController(kubeClient, cluster),
@@ -71,10 +74,11 @@ func NewControllers(
 		metricsnode.NewController(cluster),
 		nodepoolcounter.NewNodePoolController(kubeClient, cluster),
 		nodeclaimconsistency.NewNodeClaimController(clock, kubeClient, recorder, cloudProvider),
-		nodeclaimlifecycle.NewNodeClaimController(clock, kubeClient, cloudProvider, recorder),
+		nodeclaimlifecycle.NewNodeClaimController(clock, kubeClient, cloudProvider, recorder, unavailableOfferings),
 		nodeclaimgarbagecollection.NewController(clock, kubeClient, cloudProvider),
 		nodeclaimtermination.NewNodeClaimController(kubeClient, cloudProvider),
 		nodeclaimdisruption.NewNodeClaimController(clock, kubeClient, cluster, cloudProvider),
 		leasegarbagecollection.NewController(kubeClient),
+		disruptionrecordgarbagecollection.NewController(clock, kubeClient),
 	}
 }
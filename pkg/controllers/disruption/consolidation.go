@@ -21,6 +21,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,14 +32,20 @@ import (
 	disruptionevents "github.com/aws/karpenter-core/pkg/controllers/disruption/events"
 	"github.com/aws/karpenter-core/pkg/controllers/disruption/orchestration"
 	"github.com/aws/karpenter-core/pkg/controllers/provisioning"
+	pscheduling "github.com/aws/karpenter-core/pkg/controllers/provisioning/scheduling"
 	"github.com/aws/karpenter-core/pkg/controllers/state"
 	"github.com/aws/karpenter-core/pkg/events"
+	"github.com/aws/karpenter-core/pkg/operator/options"
 	"github.com/aws/karpenter-core/pkg/scheduling"
 )
 
 // consolidationTTL is the TTL between creating a consolidation command and validating that it still works.
 const consolidationTTL = 15 * time.Second
 
+// MinInstanceTypesForSpotToSpotConsolidation is the minimum number of cheaper instance types that a spot replacement
+// must be able to launch as before we'll replace a spot node with it.
+const MinInstanceTypesForSpotToSpotConsolidation = 15
+
 // consolidation is the base consolidation controller that provides common functionality used across the different
 // consolidation methods.
 type consolidation struct {
@@ -50,19 +57,22 @@ type consolidation struct {
 	provisioner            *provisioning.Provisioner
 	cloudProvider          cloudprovider.CloudProvider
 	recorder               events.Recorder
+	unavailableOfferings   *cloudprovider.UnavailableOfferings
 	lastConsolidationState time.Time
 }
 
 func makeConsolidation(clock clock.Clock, cluster *state.Cluster, kubeClient client.Client, provisioner *provisioning.Provisioner,
-	cloudProvider cloudprovider.CloudProvider, recorder events.Recorder, queue *orchestration.Queue) consolidation {
+	cloudProvider cloudprovider.CloudProvider, recorder events.Recorder, queue *orchestration.Queue,
+	unavailableOfferings *cloudprovider.UnavailableOfferings) consolidation {
 	return consolidation{
-		queue:         queue,
-		clock:         clock,
-		cluster:       cluster,
-		kubeClient:    kubeClient,
-		provisioner:   provisioner,
-		cloudProvider: cloudProvider,
-		recorder:      recorder,
+		queue:                queue,
+		clock:                clock,
+		cluster:              cluster,
+		kubeClient:           kubeClient,
+		provisioner:          provisioner,
+		cloudProvider:        cloudProvider,
+		recorder:             recorder,
+		unavailableOfferings: unavailableOfferings,
 	}
 }
 
@@ -128,6 +138,21 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 		return Command{}, nil
 	}
 
+	// removing the candidates can't eat into the headroom that their NodePools keep available on the existing nodes
+	reduced, err := reducedHeadroom(ctx, c.kubeClient, c.cluster, c.provisioner, candidates...)
+	if err != nil {
+		if errors.Is(err, errCandidateDeleting) {
+			return Command{}, nil
+		}
+		return Command{}, err
+	}
+	if len(reduced) > 0 {
+		if len(candidates) == 1 {
+			c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, fmt.Sprintf("Can't remove without reducing the headroom of NodePool %q", reduced[0]))...)
+		}
+		return Command{}, nil
+	}
+
 	// were we able to schedule all the pods on the inflight candidates?
 	if len(results.NewNodeClaims) == 0 {
 		return Command{
@@ -149,18 +174,10 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 	if err != nil {
 		return Command{}, fmt.Errorf("getting offering price from candidate node, %w", err)
 	}
-	results.NewNodeClaims[0].InstanceTypeOptions = filterByPrice(results.NewNodeClaims[0].InstanceTypeOptions, results.NewNodeClaims[0].Requirements, candidatePrice)
-	if len(results.NewNodeClaims[0].InstanceTypeOptions) == 0 {
-		if len(candidates) == 1 {
-			c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, "Can't replace with a cheaper node")...)
-		}
-		// no instance types remain after filtering by price
-		return Command{}, nil
-	}
 
-	// If the existing candidates are all spot and the replacement is spot, we don't consolidate.  We don't have a reliable
-	// mechanism to determine if this replacement makes sense given instance type availability (e.g. we may replace
-	// a spot node with one that is less available and more likely to be reclaimed).
+	// If the existing candidates are all spot and the replacement is spot, we only consolidate when spot-to-spot
+	// consolidation is enabled, since we need to be careful not to replace a spot node with one that is less available
+	// and more likely to be reclaimed.
 	allExistingAreSpot := true
 	for _, cn := range candidates {
 		if cn.capacityType != v1beta1.CapacityTypeSpot {
@@ -170,8 +187,21 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 
 	if allExistingAreSpot &&
 		results.NewNodeClaims[0].Requirements.Get(v1beta1.CapacityTypeLabelKey).Has(v1beta1.CapacityTypeSpot) {
+		return c.computeSpotToSpotConsolidation(ctx, candidates, results.NewNodeClaims[0], candidatePrice)
+	}
+
+	results.NewNodeClaims[0].InstanceTypeOptions = filterByPrice(results.NewNodeClaims[0].InstanceTypeOptions, results.NewNodeClaims[0].Requirements, candidatePrice, c.unavailableOfferings)
+	if len(results.NewNodeClaims[0].InstanceTypeOptions) == 0 {
 		if len(candidates) == 1 {
-			c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, "Can't replace a spot node with a spot node")...)
+			c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, "Can't replace with a cheaper node")...)
+		}
+		// no instance types remain after filtering by price
+		return Command{}, nil
+	}
+	// filtering by price may have removed the instance type flexibility that the NodePool's requirements demand
+	if _, err := results.NewNodeClaims[0].InstanceTypeOptions.SatisfiesMinValues(results.NewNodeClaims[0].Requirements); err != nil {
+		if len(candidates) == 1 {
+			c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, fmt.Sprintf("Can't replace with a cheaper node, %s", err))...)
 		}
 		return Command{}, nil
 	}
@@ -191,6 +221,53 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 	}, nil
 }
 
+// computeSpotToSpotConsolidation computes a consolidation action that replaces spot candidates with a cheaper spot
+// replacement. We only replace when there are at least MinInstanceTypesForSpotToSpotConsolidation instance types that
+// are cheaper than the candidates and then only launch from the cheapest of those. Without this, each consolidation would
+// walk the replacement down to the single cheapest instance type, which is usually the one that is most likely to be
+// interrupted.
+func (c *consolidation) computeSpotToSpotConsolidation(ctx context.Context, candidates []*Candidate, replacement *pscheduling.NodeClaim,
+	candidatePrice float64) (Command, error) {
+	if !options.FromContext(ctx).FeatureGates.SpotToSpotConsolidation {
+		if len(candidates) == 1 {
+			c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, "Can't replace a spot node with a spot node")...)
+		}
+		return Command{}, nil
+	}
+
+	// The replacement must launch as spot since we only filter the instance types by their spot prices
+	replacement.Requirements.Add(scheduling.NewRequirement(v1beta1.CapacityTypeLabelKey, v1.NodeSelectorOpIn, v1beta1.CapacityTypeSpot))
+	replacement.InstanceTypeOptions = filterByPrice(replacement.InstanceTypeOptions, replacement.Requirements, candidatePrice, c.unavailableOfferings)
+	// Filter out the candidates' own instance types before we keep only the cheapest options, otherwise the
+	// multi-node filtering would leave fewer options than we require
+	if len(candidates) > 1 {
+		replacement.InstanceTypeOptions = filterOutSameType(replacement, candidates, c.unavailableOfferings)
+	}
+	if len(replacement.InstanceTypeOptions) < MinInstanceTypesForSpotToSpotConsolidation {
+		if len(candidates) == 1 {
+			c.recorder.Publish(disruptionevents.SpotToSpotUnconsolidatable(candidates[0].Node, candidates[0].NodeClaim,
+				fmt.Sprintf("Can't replace with a cheaper spot node, requires %d cheaper instance type options, got %d", MinInstanceTypesForSpotToSpotConsolidation, len(replacement.InstanceTypeOptions)))...)
+		}
+		return Command{}, nil
+	}
+	// We launch from the cheapest instance types, keeping as many as are needed to satisfy any minValues requirements
+	replacement.InstanceTypeOptions = replacement.InstanceTypeOptions.OrderByPrice(replacement.Requirements)
+	minInstanceTypes, err := replacement.InstanceTypeOptions.SatisfiesMinValues(replacement.Requirements)
+	if err != nil {
+		if len(candidates) == 1 {
+			c.recorder.Publish(disruptionevents.SpotToSpotUnconsolidatable(candidates[0].Node, candidates[0].NodeClaim, fmt.Sprintf("Can't replace with a cheaper spot node, %s", err))...)
+		}
+		return Command{}, nil
+	}
+	replacement.InstanceTypeOptions = lo.Slice(replacement.InstanceTypeOptions, 0, lo.Max([]int{MinInstanceTypesForSpotToSpotConsolidation, minInstanceTypes}))
+
+	return Command{
+		candidates:   candidates,
+		replacements: []*pscheduling.NodeClaim{replacement},
+		spotToSpot:   true,
+	}, nil
+}
+
 // getCandidatePrices returns the sum of the prices of the given candidates
 func getCandidatePrices(candidates []*Candidate) (float64, error) {
 	var price float64
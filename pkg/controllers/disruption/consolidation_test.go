@@ -26,11 +26,13 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,6 +44,7 @@ import (
 	"github.com/aws/karpenter-core/pkg/cloudprovider/fake"
 	"github.com/aws/karpenter-core/pkg/controllers/disruption"
 	"github.com/aws/karpenter-core/pkg/events"
+	"github.com/aws/karpenter-core/pkg/operator/options"
 	"github.com/aws/karpenter-core/pkg/scheduling"
 	"github.com/aws/karpenter-core/pkg/test"
 	. "github.com/aws/karpenter-core/pkg/test/expectations"
@@ -143,6 +146,43 @@ var _ = Describe("Consolidation", func() {
 			ExpectNotFound(ctx, env.Client, nodeClaim)
 			ExpectNotFound(ctx, env.Client, nodeClaim2)
 		})
+		It("should only delete as many empty nodes as the budget allows", func() {
+			nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{{MaxUnavailable: intstr.FromInt(1)}}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodeClaim2, node2, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node, node2}, []*v1beta1.NodeClaim{nodeClaim, nodeClaim2})
+
+			fakeClock.Step(10 * time.Minute)
+			wg := sync.WaitGroup{}
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+			wg.Wait()
+
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			// Cascade any deletion of the nodeclaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim, nodeClaim2)
+
+			// we should only delete one of the empty nodes
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+		})
+		It("should not delete empty nodes when the budget allows no disruptions", func() {
+			nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{{MaxUnavailable: intstr.FromInt(0)}}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodeClaim2, node2, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node, node2}, []*v1beta1.NodeClaim{nodeClaim, nodeClaim2})
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+
+			// we shouldn't delete any of the empty nodes
+			Expect(queue.Len()).To(Equal(0))
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(2))
+		})
 		It("considers pending pods when consolidating", func() {
 			largeTypes := lo.Filter(cloudProvider.InstanceTypes, func(item *cloudprovider.InstanceType, index int) bool {
 				return item.Capacity.Cpu().Cmp(resource.MustParse("64")) >= 0
@@ -256,12 +296,102 @@ var _ = Describe("Consolidation", func() {
 
 			// Expect that the new nodeclaim does not request the most expensive instance type
 			Expect(nodeClaims[0].Name).ToNot(Equal(nodeClaim.Name))
-			Expect(scheduling.NewNodeSelectorRequirements(nodeClaims[0].Spec.Requirements...).Has(v1.LabelInstanceTypeStable)).To(BeTrue())
-			Expect(scheduling.NewNodeSelectorRequirements(nodeClaims[0].Spec.Requirements...).Get(v1.LabelInstanceTypeStable).Has(mostExpensiveInstance.Name)).To(BeFalse())
+			Expect(scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaims[0].Spec.Requirements...).Has(v1.LabelInstanceTypeStable)).To(BeTrue())
+			Expect(scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaims[0].Spec.Requirements...).Get(v1.LabelInstanceTypeStable).Has(mostExpensiveInstance.Name)).To(BeFalse())
 
 			// and delete the old one
 			ExpectNotFound(ctx, env.Client, nodeClaim, node)
 		})
+		It("won't replace node if the cheaper offerings are marked as unavailable", func() {
+			for _, it := range cloudProvider.InstanceTypes {
+				if it.Name == mostExpensiveInstance.Name {
+					continue
+				}
+				for _, of := range it.Offerings {
+					unavailableOfferings.MarkUnavailable(ctx, "test", cloudprovider.UnavailableOffering{
+						InstanceType: it.Name, Zone: of.Zone, CapacityType: of.CapacityType,
+					})
+				}
+			}
+			pod := test.Pod()
+			ExpectApplied(ctx, env.Client, pod, node, nodeClaim, nodePool)
+
+			// bind pods to node
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeClaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+
+			// the only instance type that we could launch isn't cheaper, so we shouldn't replace the node
+			Expect(queue.Len()).To(Equal(0))
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("can replace node with a node in an unused reservation", func() {
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: "on-demand-instance-type",
+					Offerings: []cloudprovider.Offering{
+						{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 2.00, Available: true},
+					},
+				}),
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: "reserved-instance-type",
+					Offerings: []cloudprovider.Offering{
+						{CapacityType: v1beta1.CapacityTypeReserved, Zone: "test-zone-1", Price: 0, Available: true, ReservationCapacity: 1},
+						{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 3.00, Available: true},
+					},
+				}),
+			}
+			nodeClaim, node = test.NodeClaimAndNode(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:     nodePool.Name,
+						v1.LabelInstanceTypeStable:   "on-demand-instance-type",
+						v1beta1.CapacityTypeLabelKey: v1beta1.CapacityTypeOnDemand,
+						v1.LabelTopologyZone:         "test-zone-1",
+					},
+				},
+				Status: v1beta1.NodeClaimStatus{
+					ProviderID:  test.RandomProviderID(),
+					Allocatable: map[v1.ResourceName]resource.Quantity{v1.ResourceCPU: resource.MustParse("4")},
+				},
+			})
+			pod := test.Pod()
+			ExpectApplied(ctx, env.Client, pod, node, nodeClaim, nodePool)
+
+			// bind pods to node
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeClaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectMakeNewNodeClaimsReady(ctx, env.Client, &wg, cluster, cloudProvider, 1)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			// Process the item so that the nodes can be deleted.
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			// Cascade any deletion of the nodeclaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+
+			// the node should be replaced with a node in the reservation, even though its on-demand offering is more expensive
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(1))
+			requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaims[0].Spec.Requirements...)
+			Expect(requirements.Get(v1beta1.CapacityTypeLabelKey).Values()).To(ConsistOf(v1beta1.CapacityTypeReserved))
+			Expect(requirements.Get(v1.LabelInstanceTypeStable).Values()).To(ConsistOf("reserved-instance-type"))
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		})
 		It("can replace nodes if another nodePool returns no instance types", func() {
 			labels := map[string]string{
 				"app": "test",
@@ -317,8 +447,8 @@ var _ = Describe("Consolidation", func() {
 
 			// Expect that the new nodeclaim does not request the most expensive instance type
 			Expect(nodeclaims[0].Name).ToNot(Equal(nodeClaim.Name))
-			Expect(scheduling.NewNodeSelectorRequirements(nodeclaims[0].Spec.Requirements...).Has(v1.LabelInstanceTypeStable)).To(BeTrue())
-			Expect(scheduling.NewNodeSelectorRequirements(nodeclaims[0].Spec.Requirements...).Get(v1.LabelInstanceTypeStable).Has(mostExpensiveInstance.Name)).To(BeFalse())
+			Expect(scheduling.NewNodeSelectorRequirementsWithMinValues(nodeclaims[0].Spec.Requirements...).Has(v1.LabelInstanceTypeStable)).To(BeTrue())
+			Expect(scheduling.NewNodeSelectorRequirementsWithMinValues(nodeclaims[0].Spec.Requirements...).Get(v1.LabelInstanceTypeStable).Has(mostExpensiveInstance.Name)).To(BeFalse())
 
 			// and delete the old one
 			ExpectNotFound(ctx, env.Client, nodeClaim, node)
@@ -1010,12 +1140,12 @@ var _ = Describe("Consolidation", func() {
 					}}})
 
 			// nodePool should require on-demand instance for this test case
-			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-				{
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 					Key:      v1beta1.CapacityTypeLabelKey,
 					Operator: v1.NodeSelectorOpIn,
 					Values:   []string{v1alpha5.CapacityTypeOnDemand},
-				},
+				}},
 			}
 			nodeClaim, node = test.NodeClaimAndNode(v1beta1.NodeClaim{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1883,6 +2013,50 @@ var _ = Describe("Consolidation", func() {
 			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(2))
 		})
+		It("won't delete nodes if it would reduce the headroom of their nodepool", func() {
+			labels := map[string]string{
+				"app": "test",
+			}
+			// create our RS so we can link a pod to it
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			pods := test.Pods(3, test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         ptr.Bool(true),
+							BlockOwnerDeletion: ptr.Bool(true),
+						},
+					}}})
+			// the headroom only fits when each of the nodes keeps capacity available for one of its pods
+			nodePool.Spec.Headroom = &v1beta1.Headroom{Pods: &v1beta1.HeadroomPods{
+				Count:    2,
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("20")},
+			}}
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaim, node, nodeClaim2, node2, nodePool)
+
+			// bind pods to node
+			ExpectManualBinding(ctx, env.Client, pods[0], node)
+			ExpectManualBinding(ctx, env.Client, pods[1], node)
+			ExpectManualBinding(ctx, env.Client, pods[2], node2)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node, node2}, []*v1beta1.NodeClaim{nodeClaim, nodeClaim2})
+
+			fakeClock.Step(10 * time.Minute)
+
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+
+			// The pods fit on either node, but no node can be deleted as the headroom would no longer fit
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(2))
+			Expect(cloudProvider.CreateCalls).To(HaveLen(0))
+			Expect(recorder.DetectedEvent(fmt.Sprintf("Can't remove without reducing the headroom of NodePool %q", nodePool.Name))).To(BeTrue())
+		})
 		It("can delete nodes while an invalid node pool exists", func() {
 			labels := map[string]string{
 				"app": "test",
@@ -1896,7 +2070,7 @@ var _ = Describe("Consolidation", func() {
 				Spec: v1beta1.NodePoolSpec{
 					Template: v1beta1.NodeClaimTemplate{
 						Spec: v1beta1.NodeClaimSpec{
-							Requirements: []v1.NodeSelectorRequirement{},
+							Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{},
 							NodeClassRef: &v1beta1.NodeClassReference{
 								Name: "non-existent",
 							},
@@ -2844,6 +3018,59 @@ var _ = Describe("Consolidation", func() {
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
 			ExpectNotFound(ctx, env.Client, nodeClaim1, node1, nodeClaim2, node2, nodeClaim3, node3)
 		})
+		It("won't merge nodes if it would reduce the headroom of their nodepool", func() {
+			labels := map[string]string{
+				"app": "test",
+			}
+			// create our RS so we can link a pod to it
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			pods := test.Pods(3, test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         ptr.Bool(true),
+							BlockOwnerDeletion: ptr.Bool(true),
+						},
+					}}})
+			// the headroom fits on any two of the nodes, so only a single node can be removed
+			nodePool.Spec.Headroom = &v1beta1.Headroom{Pods: &v1beta1.HeadroomPods{
+				Count:    2,
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("20")},
+			}}
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaim1, node1, nodeClaim2, node2, nodeClaim3, node3, nodePool)
+			ExpectMakeNodesInitialized(ctx, env.Client, node1, node2, node3)
+
+			// bind pods to nodes
+			ExpectManualBinding(ctx, env.Client, pods[0], node1)
+			ExpectManualBinding(ctx, env.Client, pods[1], node2)
+			ExpectManualBinding(ctx, env.Client, pods[2], node3)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1, node2, node3}, []*v1beta1.NodeClaim{nodeClaim1, nodeClaim2, nodeClaim3})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			// Process the item so that the nodes can be deleted.
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			// Cascade any deletion of the nodeclaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim1, nodeClaim2, nodeClaim3)
+
+			// merging the nodes into a cheaper replacement would eat into the headroom, so a single node is deleted instead
+			Expect(cloudProvider.CreateCalls).To(HaveLen(0))
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(2))
+		})
 		It("won't merge 2 nodes into 1 of the same type", func() {
 			labels := map[string]string{
 				"app": "test",
@@ -3157,6 +3384,311 @@ var _ = Describe("Consolidation", func() {
 			ExpectNotFound(ctx, env.Client, nodeClaim3, node3)
 		})
 	})
+	Context("Spot-to-Spot", func() {
+		var spotInstances []*cloudprovider.InstanceType
+		var currentInstance *cloudprovider.InstanceType
+		var rs *appsv1.ReplicaSet
+		var labels map[string]string
+
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{Drift: lo.ToPtr(true), SpotToSpotConsolidation: lo.ToPtr(true)}}))
+			// the instance types are ordered by price, so the last one is the most expensive
+			spotInstances = fake.InstanceTypes(20)
+			currentInstance = spotInstances[len(spotInstances)-1]
+			cloudProvider.InstanceTypes = spotInstances
+
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: v1.NodeSelectorRequirement{
+					Key:      v1beta1.CapacityTypeLabelKey,
+					Operator: v1.NodeSelectorOpIn,
+					Values:   []string{v1beta1.CapacityTypeSpot},
+				}},
+			}
+			nodeClaim, node = test.NodeClaimAndNode(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:     nodePool.Name,
+						v1.LabelInstanceTypeStable:   currentInstance.Name,
+						v1beta1.CapacityTypeLabelKey: v1beta1.CapacityTypeSpot,
+						v1.LabelTopologyZone:         "test-zone-1",
+					},
+				},
+				Status: v1beta1.NodeClaimStatus{
+					ProviderID:  test.RandomProviderID(),
+					Allocatable: map[v1.ResourceName]resource.Quantity{v1.ResourceCPU: resource.MustParse("20")},
+				},
+			})
+			labels = map[string]string{
+				"app": "test",
+			}
+			// create our RS so we can link a pod to it
+			rs = test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			Expect(env.Client.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+		})
+		ownedPods := func(count int) []*v1.Pod {
+			return test.Pods(count, test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         ptr.Bool(true),
+							BlockOwnerDeletion: ptr.Bool(true),
+						},
+					}}})
+		}
+		It("won't replace a spot node with a spot node when spot-to-spot consolidation is disabled", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{Drift: lo.ToPtr(true), SpotToSpotConsolidation: lo.ToPtr(false)}}))
+			pod := ownedPods(1)[0]
+			ExpectApplied(ctx, env.Client, rs, pod, nodeClaim, node, nodePool)
+
+			// bind pods to node
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+
+			Expect(queue.Len()).To(Equal(0))
+			Expect(recorder.DetectedEvent("Can't replace a spot node with a spot node")).To(BeTrue())
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("can replace a spot node with a cheaper spot node", func() {
+			pod := ownedPods(1)[0]
+			ExpectApplied(ctx, env.Client, rs, pod, nodeClaim, node, nodePool)
+
+			// bind pods to node
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+
+			// consolidation won't delete the old nodeclaim until the new nodeclaim is ready
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectMakeNewNodeClaimsReady(ctx, env.Client, &wg, cluster, cloudProvider, 1)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			// Process the item so that the nodes can be deleted.
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			// Cascade any deletion of the nodeclaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+			Expect(recorder.Calls("SpotToSpotConsolidation")).To(Equal(2))
+
+			// the replacement should be spot and only launch as one of the cheapest instance types
+			requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaims[0].Spec.Requirements...)
+			Expect(requirements.Get(v1beta1.CapacityTypeLabelKey).Values()).To(ConsistOf(v1beta1.CapacityTypeSpot))
+			Expect(requirements.Get(v1.LabelInstanceTypeStable).Values()).To(ConsistOf(lo.Map(spotInstances[:disruption.MinInstanceTypesForSpotToSpotConsolidation], func(it *cloudprovider.InstanceType, _ int) string {
+				return it.Name
+			})))
+		})
+		It("won't replace a spot node if there aren't enough cheaper instance type options", func() {
+			// only 9 of the instance types are cheaper than the current instance type
+			spotInstances = spotInstances[:9]
+			cloudProvider.InstanceTypes = append(spotInstances, currentInstance)
+			pod := ownedPods(1)[0]
+			ExpectApplied(ctx, env.Client, rs, pod, nodeClaim, node, nodePool)
+
+			// bind pods to node
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+
+			Expect(queue.Len()).To(Equal(0))
+			Expect(recorder.DetectedEvent(fmt.Sprintf("Can't replace with a cheaper spot node, requires %d cheaper instance type options, got 9", disruption.MinInstanceTypesForSpotToSpotConsolidation))).To(BeTrue())
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should keep enough instance types to satisfy minValues when replacing a spot node", func() {
+			nodePool.Spec.Template.Spec.Requirements = append(nodePool.Spec.Template.Spec.Requirements, v1beta1.NodeSelectorRequirementWithMinValues{
+				NodeSelectorRequirement: v1.NodeSelectorRequirement{
+					Key:      v1.LabelInstanceTypeStable,
+					Operator: v1.NodeSelectorOpExists,
+				},
+				MinValues: lo.ToPtr(18),
+			})
+			pod := ownedPods(1)[0]
+			ExpectApplied(ctx, env.Client, rs, pod, nodeClaim, node, nodePool)
+
+			// bind pods to node
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectMakeNewNodeClaimsReady(ctx, env.Client, &wg, cluster, cloudProvider, 1)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			// Process the item so that the nodes can be deleted.
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			// Cascade any deletion of the nodeclaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(1))
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+
+			// the replacement should launch from the 18 cheapest instance types rather than the usual 15
+			requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaims[0].Spec.Requirements...)
+			Expect(requirements.Get(v1.LabelInstanceTypeStable).Values()).To(ConsistOf(lo.Map(spotInstances[:18], func(it *cloudprovider.InstanceType, _ int) string {
+				return it.Name
+			})))
+			Expect(requirements.Get(v1.LabelInstanceTypeStable).MinValues).To(Equal(lo.ToPtr(18)))
+		})
+		It("won't replace a spot node if the cheaper instance types don't satisfy minValues", func() {
+			nodePool.Spec.Template.Spec.Requirements = append(nodePool.Spec.Template.Spec.Requirements, v1beta1.NodeSelectorRequirementWithMinValues{
+				NodeSelectorRequirement: v1.NodeSelectorRequirement{
+					Key:      v1.LabelInstanceTypeStable,
+					Operator: v1.NodeSelectorOpExists,
+				},
+				MinValues: lo.ToPtr(20),
+			})
+			pod := ownedPods(1)[0]
+			ExpectApplied(ctx, env.Client, rs, pod, nodeClaim, node, nodePool)
+
+			// bind pods to node
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+			fakeClock.Step(10 * time.Minute)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+
+			// only 19 of the instance types are cheaper than the current instance type
+			Expect(queue.Len()).To(Equal(0))
+			Expect(recorder.DetectedEvent(fmt.Sprintf("Can't replace with a cheaper spot node, minValues requirement is not met for %q", v1.LabelInstanceTypeStable))).To(BeTrue())
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("can merge spot nodes into a cheaper spot node", func() {
+			nodeClaim2, node2 := test.NodeClaimAndNode(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:     nodePool.Name,
+						v1.LabelInstanceTypeStable:   currentInstance.Name,
+						v1beta1.CapacityTypeLabelKey: v1beta1.CapacityTypeSpot,
+						v1.LabelTopologyZone:         "test-zone-1",
+					},
+				},
+				Status: v1beta1.NodeClaimStatus{
+					ProviderID:  test.RandomProviderID(),
+					Allocatable: map[v1.ResourceName]resource.Quantity{v1.ResourceCPU: resource.MustParse("20")},
+				},
+			})
+			pods := ownedPods(2)
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], nodeClaim, node, nodeClaim2, node2, nodePool)
+
+			// bind pods to nodes
+			ExpectManualBinding(ctx, env.Client, pods[0], node)
+			ExpectManualBinding(ctx, env.Client, pods[1], node2)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node, node2}, []*v1beta1.NodeClaim{nodeClaim, nodeClaim2})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectMakeNewNodeClaimsReady(ctx, env.Client, &wg, cluster, cloudProvider, 1)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			// Process the item so that the nodes can be deleted.
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			// Cascade any deletion of the nodeclaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim, nodeClaim2)
+
+			// both spot nodeclaims should be replaced with a single spot nodeclaim
+			nodeClaims := ExpectNodeClaims(ctx, env.Client)
+			Expect(nodeClaims).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectNotFound(ctx, env.Client, nodeClaim, node, nodeClaim2, node2)
+
+			requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaims[0].Spec.Requirements...)
+			Expect(requirements.Get(v1beta1.CapacityTypeLabelKey).Values()).To(ConsistOf(v1beta1.CapacityTypeSpot))
+			Expect(requirements.Get(v1.LabelInstanceTypeStable).Len()).To(Equal(disruption.MinInstanceTypesForSpotToSpotConsolidation))
+		})
+		It("won't merge spot nodes into a replacement that could launch as one of the candidates' cheap instance types", func() {
+			// the second candidate runs on one of the cheapest instance types, so merging both candidates only saves money
+			// if the replacement is cheaper still
+			cheapInstance := spotInstances[2]
+			nodeClaim2, node2 := test.NodeClaimAndNode(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:     nodePool.Name,
+						v1.LabelInstanceTypeStable:   cheapInstance.Name,
+						v1beta1.CapacityTypeLabelKey: v1beta1.CapacityTypeSpot,
+						v1.LabelTopologyZone:         "test-zone-1",
+					},
+				},
+				Status: v1beta1.NodeClaimStatus{
+					ProviderID:  test.RandomProviderID(),
+					Allocatable: map[v1.ResourceName]resource.Quantity{v1.ResourceCPU: resource.MustParse("20")},
+				},
+			})
+			// the expensive node has fewer pods, so it's cheaper to disrupt
+			pods := ownedPods(3)
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaim, node, nodeClaim2, node2, nodePool)
+
+			// bind pods to nodes
+			ExpectManualBinding(ctx, env.Client, pods[0], node)
+			ExpectManualBinding(ctx, env.Client, pods[1], node2)
+			ExpectManualBinding(ctx, env.Client, pods[2], node2)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node, node2}, []*v1beta1.NodeClaim{nodeClaim, nodeClaim2})
+
+			fakeClock.Step(10 * time.Minute)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, client.ObjectKey{})
+			wg.Wait()
+
+			// Process the item so that the nodes can be deleted.
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			// Cascade any deletion of the nodeclaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+
+			// Only two instance types are cheaper than the cheap candidate, which isn't enough for a spot-to-spot
+			// replacement, so the expensive node is deleted and its pod moves to the cheap node instead
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+			ExpectExists(ctx, env.Client, nodeClaim2)
+			ExpectExists(ctx, env.Client, node2)
+		})
+	})
 	Context("Node Lifetime Consideration", func() {
 		var nodeClaim1, nodeClaim2 *v1beta1.NodeClaim
 		var node1, node2 *v1.Node
@@ -20,21 +20,28 @@ import (
 	"sync"
 	"time"
 
+	"github.com/patrickmn/go-cache"
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/clock"
 	"knative.dev/pkg/logging"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
 	"github.com/aws/karpenter-core/pkg/cloudprovider"
+	disruptionevents "github.com/aws/karpenter-core/pkg/controllers/disruption/events"
 	"github.com/aws/karpenter-core/pkg/controllers/disruption/orchestration"
 	"github.com/aws/karpenter-core/pkg/controllers/provisioning"
+	pscheduling "github.com/aws/karpenter-core/pkg/controllers/provisioning/scheduling"
 	"github.com/aws/karpenter-core/pkg/controllers/state"
 	"github.com/aws/karpenter-core/pkg/events"
 	"github.com/aws/karpenter-core/pkg/metrics"
 	"github.com/aws/karpenter-core/pkg/operator/controller"
+	"github.com/aws/karpenter-core/pkg/operator/options"
+	"github.com/aws/karpenter-core/pkg/utils/disruptionrecord"
 )
 
 type Controller struct {
@@ -48,6 +55,8 @@ type Controller struct {
 	methods       []Method
 	mu            sync.Mutex
 	lastRun       map[string]time.Time
+	// dryRunCandidates are the candidates of recent dry-run commands, mapped by their provider ids
+	dryRunCandidates *cache.Cache
 }
 
 // pollingPeriod that we inspect cluster to look for opportunities to disrupt
@@ -56,25 +65,29 @@ const pollingPeriod = 10 * time.Second
 var errCandidateDeleting = fmt.Errorf("candidate is deleting")
 
 func NewController(clk clock.Clock, kubeClient client.Client, provisioner *provisioning.Provisioner,
-	cp cloudprovider.CloudProvider, recorder events.Recorder, cluster *state.Cluster, queue *orchestration.Queue) *Controller {
-	c := makeConsolidation(clk, cluster, kubeClient, provisioner, cp, recorder, queue)
+	cp cloudprovider.CloudProvider, recorder events.Recorder, cluster *state.Cluster, queue *orchestration.Queue,
+	unavailableOfferings *cloudprovider.UnavailableOfferings) *Controller {
+	c := makeConsolidation(clk, cluster, kubeClient, provisioner, cp, recorder, queue, unavailableOfferings)
 	return &Controller{
-		queue:         queue,
-		clock:         clk,
-		kubeClient:    kubeClient,
-		cluster:       cluster,
-		provisioner:   provisioner,
-		recorder:      recorder,
-		cloudProvider: cp,
-		lastRun:       map[string]time.Time{},
+		queue:            queue,
+		clock:            clk,
+		kubeClient:       kubeClient,
+		cluster:          cluster,
+		provisioner:      provisioner,
+		recorder:         recorder,
+		cloudProvider:    cp,
+		lastRun:          map[string]time.Time{},
+		dryRunCandidates: cache.New(dryRunCandidateTTL, time.Minute),
 		methods: []Method{
+			// Replace any NodeClaims whose nodes have been unhealthy for too long, as they're unlikely to recover on their own
+			NewRepair(kubeClient, cluster, provisioner, recorder),
 			// Expire any NodeClaims that must be deleted, allowing their pods to potentially land on currently
 			NewExpiration(clk, kubeClient, cluster, provisioner, recorder),
 			// Terminate any NodeClaims that have drifted from provisioning specifications, allowing the pods to reschedule.
 			NewDrift(kubeClient, cluster, provisioner, recorder),
 			// Delete any remaining empty NodeClaims as there is zero cost in terms of disruption.  Emptiness and
 			// emptyNodeConsolidation are mutually exclusive, only one of these will operate
-			NewEmptiness(clk),
+			NewEmptiness(clk, kubeClient, cluster, provisioner),
 			NewEmptyNodeConsolidation(c),
 			// Attempt to identify multiple NodeClaims that we can consolidate simultaneously to reduce pod churn
 			NewMultiNodeConsolidation(c),
@@ -105,9 +118,15 @@ func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconc
 		logging.FromContext(ctx).Debugf("waiting on cluster sync")
 		return reconcile.Result{RequeueAfter: time.Second}, nil
 	}
+	// The orchestration queue restores the commands that were in-flight before Karpenter restarted. We need to wait for
+	// this so that we don't untaint their candidates or compute commands that conflict with them.
+	if !c.queue.Restored() {
+		logging.FromContext(ctx).Debugf("waiting on disruption queue to restore in-flight commands")
+		return reconcile.Result{RequeueAfter: time.Second}, nil
+	}
 
-	// Karpenter taints nodes with a karpenter.sh/disruption taint as part of the disruption process
-	// while it progresses in memory. If Karpenter restarts during a disruption action, some nodes can be left tainted.
+	// Karpenter taints nodes with a karpenter.sh/disruption taint as part of the disruption process. If Karpenter restarts
+	// before a command is persisted by the orchestration queue, some nodes can be left tainted.
 	// Idempotently remove this taint from candidates that are not in the orchestration queue before continuing.
 	if err := state.RequireNoScheduleTaint(ctx, c.kubeClient, false, lo.Filter(c.cluster.Nodes(), func(s *state.StateNode, _ int) bool {
 		return !c.queue.HasAny(s.ProviderID())
@@ -115,9 +134,10 @@ func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconc
 		return reconcile.Result{}, fmt.Errorf("removing taint from nodes, %w", err)
 	}
 
-	// Check if the queue is processing an item. If it is, retry again later.
-	// TODO this should be removed when disruption budgets are added in.
-	if !c.queue.IsEmpty() {
+	// The orchestration queue executes commands for non-overlapping candidates in parallel, so we only stop computing
+	// new commands once the global concurrency limit is reached. Disruption budgets separately bound how many nodes
+	// each NodePool can have disrupting at once.
+	if c.queue.Count() >= options.FromContext(ctx).MaxConcurrentDisruptions {
 		return reconcile.Result{RequeueAfter: time.Second}, nil
 	}
 
@@ -146,19 +166,41 @@ func (c *Controller) disrupt(ctx context.Context, disruption Method) (bool, erro
 	if err != nil {
 		return false, fmt.Errorf("determining candidates, %w", err)
 	}
+	// Candidates of recent dry-run commands are treated as if they were already being disrupted
+	candidates = lo.Reject(candidates, func(cn *Candidate, _ int) bool {
+		_, ok := c.dryRunCandidates.Get(cn.ProviderID())
+		return ok
+	})
 	// If there are no candidates, move to the next disruption
 	if len(candidates) == 0 {
 		return false, nil
 	}
 
+	disruptionBudgetMapping, err := BuildDisruptionBudgets(ctx, c.cluster, c.clock, c.kubeClient, c.recorder)
+	if err != nil {
+		return false, fmt.Errorf("building disruption budgets, %w", err)
+	}
+
 	// Determine the disruption action
-	cmd, err := disruption.ComputeCommand(ctx, candidates...)
+	cmd, err := disruption.ComputeCommand(ctx, disruptionBudgetMapping, candidates...)
 	if err != nil {
 		return false, fmt.Errorf("computing disruption decision, %w", err)
 	}
 	if cmd.Action() == NoOpAction {
 		return false, nil
 	}
+	// Other commands are executing while we compute this one, so ensure that none of its candidates have been
+	// picked up by an in-flight command or marked for deletion since we determined the candidates
+	if c.hasConflict(cmd) {
+		logging.FromContext(ctx).Debugf("abandoning disruption via %s %s, candidates are already being disrupted", disruption.Type(), cmd)
+		return false, nil
+	}
+	// In dry-run mode we stop short of executing the command. We don't consider this a successful disruption so
+	// that the remaining methods are still evaluated.
+	if isDryRun(ctx, cmd) {
+		c.publishDryRun(ctx, disruption, cmd)
+		return false, nil
+	}
 
 	// Attempt to disrupt
 	if err := c.executeCommand(ctx, disruption, cmd); err != nil {
@@ -172,14 +214,38 @@ func (c *Controller) disrupt(ctx context.Context, disruption Method) (bool, erro
 // 1. Taint candidate nodes
 // 2. Spin up replacement nodes
 // 3. Add Command to orchestration.Queue to wait to delete the candiates.
-func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command) error {
+// Each command is recorded as a DisruptionRecord which the orchestration.Queue keeps up to date as the command progresses.
+func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command) (err error) {
 	disruptionActionsPerformedCounter.With(map[string]string{
 		actionLabel:            string(cmd.Action()),
 		methodLabel:            m.Type(),
 		consolidationTypeLabel: m.ConsolidationType(),
 	}).Inc()
+	if cmd.spotToSpot {
+		disruptionSpotToSpotActionsPerformedCounter.With(map[string]string{
+			consolidationTypeLabel: m.ConsolidationType(),
+		}).Inc()
+		for _, candidate := range cmd.candidates {
+			c.recorder.Publish(disruptionevents.SpotToSpotConsolidation(candidate.Node, candidate.NodeClaim,
+				pscheduling.InstanceTypeList(cmd.replacements[0].InstanceTypeOptions))...)
+		}
+	}
+	if m.Type() == metrics.DriftReason {
+		for _, candidate := range cmd.candidates {
+			if cond := candidate.NodeClaim.StatusConditions().GetCondition(v1beta1.Drifted); cond.IsTrue() {
+				c.recorder.Publish(disruptionevents.Drifted(candidate.Node, candidate.NodeClaim, cond.Reason, cond.Message)...)
+			}
+		}
+	}
 	logging.FromContext(ctx).Infof("disrupting via %s %s", m.Type(), cmd)
 
+	record := c.createDisruptionRecord(ctx, m, cmd)
+	defer func() {
+		if err != nil {
+			c.transitionDisruptionRecord(ctx, record, v1beta1.DisruptionPhaseFailed, disruptionrecord.WithMessage(err.Error()))
+		}
+	}()
+
 	stateNodes := lo.Map(cmd.candidates, func(c *Candidate, _ int) *state.StateNode {
 		return c.StateNode
 	})
@@ -189,7 +255,6 @@ func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command)
 	}
 
 	var nodeClaimNames []string
-	var err error
 	if len(cmd.replacements) > 0 {
 		if nodeClaimNames, err = c.createReplacementNodeClaims(ctx, m, cmd); err != nil {
 			// If we failed to launch the replacement, don't disrupt.  If this is some permanent failure,
@@ -202,14 +267,28 @@ func (c *Controller) executeCommand(ctx context.Context, m Method, cmd Command)
 	// We have the new NodeClaims created at the API server so mark the old NodeClaims for deletion
 	c.cluster.MarkForDeletion(providerIDs...)
 
-	if err := c.queue.Add(orchestration.NewCommand(nodeClaimNames,
-		lo.Map(cmd.candidates, func(c *Candidate, _ int) *state.StateNode { return c.StateNode }), m.Type(), m.ConsolidationType())); err != nil {
+	// Transition the record before handing the command off so that the queue's transitions are always recorded after this one
+	c.transitionDisruptionRecord(ctx, record, v1beta1.DisruptionPhaseQueued, disruptionrecord.WithReplacementNodeClaims(nodeClaimNames...))
+	if err := c.queue.Add(ctx, orchestration.NewCommand(nodeClaimNames,
+		lo.Map(cmd.candidates, func(c *Candidate, _ int) *state.StateNode { return c.StateNode }), m.Type(), m.ConsolidationType()).WithRecord(record)); err != nil {
 		c.cluster.UnmarkForDeletion(providerIDs...)
 		return fmt.Errorf("adding command to queue, %w", multierr.Append(err, state.RequireNoScheduleTaint(ctx, c.kubeClient, false, stateNodes...)))
 	}
 	return nil
 }
 
+// hasConflict returns true if any of the command's candidates are part of an in-flight command in the orchestration
+// queue or are marked for deletion in cluster state
+func (c *Controller) hasConflict(cmd Command) bool {
+	providerIDs := sets.New(lo.Map(cmd.candidates, func(c *Candidate, _ int) string { return c.ProviderID() })...)
+	if c.queue.HasAny(providerIDs.UnsortedList()...) {
+		return true
+	}
+	return lo.ContainsBy(c.cluster.Nodes(), func(n *state.StateNode) bool {
+		return providerIDs.Has(n.ProviderID()) && n.MarkedForDeletion()
+	})
+}
+
 // createReplacementNodeClaims creates replacement NodeClaims
 func (c *Controller) createReplacementNodeClaims(ctx context.Context, m Method, cmd Command) ([]string, error) {
 	reason := fmt.Sprintf("%s/%s", m.Type(), cmd.Action())
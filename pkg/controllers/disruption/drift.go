@@ -16,16 +16,13 @@ package disruption
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"math"
 	"sort"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"github.com/samber/lo"
-
 	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
-	disruptionevents "github.com/aws/karpenter-core/pkg/controllers/disruption/events"
 	"github.com/aws/karpenter-core/pkg/controllers/provisioning"
 	"github.com/aws/karpenter-core/pkg/controllers/state"
 	"github.com/aws/karpenter-core/pkg/events"
@@ -70,7 +67,7 @@ func (d *Drift) filterAndSortCandidates(ctx context.Context, candidates []*Candi
 }
 
 // ComputeCommand generates a disruption command given candidates
-func (d *Drift) ComputeCommand(ctx context.Context, candidates ...*Candidate) (Command, error) {
+func (d *Drift) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, error) {
 	candidates, err := d.filterAndSortCandidates(ctx, candidates)
 	if err != nil {
 		return Command{}, err
@@ -80,41 +77,7 @@ func (d *Drift) ComputeCommand(ctx context.Context, candidates ...*Candidate) (C
 		consolidationTypeLabel: d.ConsolidationType(),
 	}).Set(float64(len(candidates)))
 
-	// Disrupt all empty drifted candidates, as they require no scheduling simulations.
-	if empty := lo.Filter(candidates, func(c *Candidate, _ int) bool {
-		return len(c.pods) == 0
-	}); len(empty) > 0 {
-		return Command{
-			candidates: empty,
-		}, nil
-	}
-
-	for _, candidate := range candidates {
-		// Check if we need to create any NodeClaims.
-		results, err := simulateScheduling(ctx, d.kubeClient, d.cluster, d.provisioner, candidate)
-		if err != nil {
-			// if a candidate is now deleting, just retry
-			if errors.Is(err, errCandidateDeleting) {
-				continue
-			}
-			return Command{}, err
-		}
-		// Emit an event that we couldn't reschedule the pods on the node.
-		if !results.AllNonPendingPodsScheduled() {
-			d.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, "Scheduling simulation failed to schedule all pods")...)
-			continue
-		}
-		if len(results.NewNodeClaims) == 0 {
-			return Command{
-				candidates: []*Candidate{candidate},
-			}, nil
-		}
-		return Command{
-			candidates:   []*Candidate{candidate},
-			replacements: results.NewNodeClaims,
-		}, nil
-	}
-	return Command{}, nil
+	return computeDisruptionCommand(ctx, d.kubeClient, d.cluster, d.provisioner, d.recorder, disruptionBudgetMapping, math.MaxInt, candidates)
 }
 
 func (d *Drift) Type() string {
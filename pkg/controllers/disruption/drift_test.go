@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -302,6 +303,87 @@ var _ = Describe("Drift", func() {
 		Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(0))
 		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
 	})
+	It("should publish how the node has drifted when disrupting it", func() {
+		nodeClaim.StatusConditions().SetCondition(apis.Condition{
+			Type:     v1beta1.Drifted,
+			Status:   v1.ConditionTrue,
+			Severity: apis.ConditionSeverityWarning,
+			Reason:   "ImageDrifted",
+			Message:  `Image has changed, spec.image changed from "image-1" to "image-2"`,
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+		var wg sync.WaitGroup
+		ExpectTriggerVerifyAction(&wg)
+		ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+		wg.Wait()
+
+		Expect(recorder.DetectedEvent(`Disrupting Node: Drifted (ImageDrifted), Image has changed, spec.image changed from "image-1" to "image-2"`)).To(BeTrue())
+		Expect(recorder.DetectedEvent(`Disrupting NodeClaim: Drifted (ImageDrifted), Image has changed, spec.image changed from "image-1" to "image-2"`)).To(BeTrue())
+	})
+	It("should only disrupt as many empty drifted nodes as the budget allows", func() {
+		nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{{MaxUnavailable: intstr.FromInt(5)}}
+		nodeClaims, nodes := test.NodeClaimsAndNodes(10, v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+					v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+					v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+				},
+			},
+			Status: v1beta1.NodeClaimStatus{
+				Allocatable: map[v1.ResourceName]resource.Quantity{
+					v1.ResourceCPU:  resource.MustParse("32"),
+					v1.ResourcePods: resource.MustParse("100"),
+				},
+			},
+		})
+		for _, m := range nodeClaims {
+			m.StatusConditions().MarkTrue(v1beta1.Drifted)
+			ExpectApplied(ctx, env.Client, m)
+		}
+		for _, n := range nodes {
+			ExpectApplied(ctx, env.Client, n)
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+
+		// inform cluster state about nodes and nodeClaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+
+		var wg sync.WaitGroup
+		ExpectTriggerVerifyAction(&wg)
+		ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+		wg.Wait()
+
+		// Process the item so that the nodes can be deleted.
+		ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+		// Cascade any deletion of the nodeClaim to the node
+		ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaims...)
+
+		// Expect that only half of the drifted nodeClaims are gone
+		Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(5))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(5))
+	})
+	It("should not replace drifted nodes when the budget allows no disruptions", func() {
+		nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{{MaxUnavailable: intstr.FromString("0%")}}
+		pod := test.Pod()
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+		ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+
+		// Expect to not create or delete more nodeclaims
+		Expect(queue.Len()).To(Equal(0))
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
 	It("can replace drifted nodes", func() {
 		labels := map[string]string{
 			"app": "test",
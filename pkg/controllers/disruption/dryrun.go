@@ -0,0 +1,78 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"knative.dev/pkg/logging"
+
+	disruptionevents "github.com/aws/karpenter-core/pkg/controllers/disruption/events"
+	"github.com/aws/karpenter-core/pkg/controllers/provisioning/scheduling"
+	"github.com/aws/karpenter-core/pkg/operator/options"
+)
+
+// dryRunCandidateTTL is how long a candidate that was part of a dry-run command is excluded from further disruption
+// decisions. This stands in for the time it would have taken to disrupt the candidate so that dry-run moves on to
+// other candidates rather than publishing the same command on every loop.
+const dryRunCandidateTTL = 15 * time.Minute
+
+// isDryRun returns true if the command should be published rather than executed. Each NodePool can override the
+// operator-wide setting through spec.disruption.dryRun. A command that disrupts candidates from any NodePool in dry-run
+// mode is not executed.
+func isDryRun(ctx context.Context, cmd Command) bool {
+	return lo.ContainsBy(cmd.candidates, func(c *Candidate) bool {
+		return lo.FromPtrOr(c.nodePool.Spec.Disruption.DryRun, options.FromContext(ctx).DisruptionDryRun)
+	})
+}
+
+// publishDryRun records the command that would have been executed as a log line, events against each of its
+// candidates and metrics
+func (c *Controller) publishDryRun(ctx context.Context, m Method, cmd Command) {
+	savings, err := estimatedSavings(cmd)
+	if err != nil {
+		logging.FromContext(ctx).Debugf("estimating savings for dry-run command, %s", err)
+	}
+	logging.FromContext(ctx).With(
+		"method", m.Type(),
+		"consolidation-type", m.ConsolidationType(),
+		"action", cmd.Action(),
+		"candidates", lo.Map(cmd.candidates, func(c *Candidate, _ int) string { return c.Name() }),
+		"replacements", len(cmd.replacements),
+		"replacement-instance-types", lo.Map(cmd.replacements, func(n *scheduling.NodeClaim, _ int) string {
+			return scheduling.InstanceTypeList(n.InstanceTypeOptions)
+		}),
+		"estimated-savings", savings,
+	).Infof("dry-run, would have disrupted via %s %s", m.Type(), cmd)
+
+	reason := fmt.Sprintf("%s/%s (dry-run, estimated savings of %.4f/hour)", m.Type(), cmd.Action(), savings)
+	for _, cn := range cmd.candidates {
+		c.recorder.Publish(disruptionevents.DryRun(cn.Node, cn.NodeClaim, reason)...)
+		c.dryRunCandidates.SetDefault(cn.ProviderID(), struct{}{})
+	}
+
+	labels := map[string]string{
+		actionLabel:            string(cmd.Action()),
+		methodLabel:            m.Type(),
+		consolidationTypeLabel: m.ConsolidationType(),
+	}
+	disruptionDryRunActionsCounter.With(labels).Inc()
+	disruptionDryRunCandidatesCounter.With(labels).Add(float64(len(cmd.candidates)))
+	disruptionDryRunReplacementsCounter.With(labels).Add(float64(len(cmd.replacements)))
+	disruptionDryRunEstimatedSavingsHistogram.With(labels).Observe(savings)
+}
@@ -16,23 +16,34 @@ package disruption
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/samber/lo"
 	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/controllers/provisioning"
+	"github.com/aws/karpenter-core/pkg/controllers/state"
 	"github.com/aws/karpenter-core/pkg/metrics"
 )
 
 // Emptiness is a subreconciler that deletes empty candidates.
 // Emptiness will respect TTLSecondsAfterEmpty
 type Emptiness struct {
-	clock clock.Clock
+	clock       clock.Clock
+	kubeClient  client.Client
+	cluster     *state.Cluster
+	provisioner *provisioning.Provisioner
 }
 
-func NewEmptiness(clk clock.Clock) *Emptiness {
+func NewEmptiness(clk clock.Clock, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner) *Emptiness {
 	return &Emptiness{
-		clock: clk,
+		clock:       clk,
+		kubeClient:  kubeClient,
+		cluster:     cluster,
+		provisioner: provisioner,
 	}
 }
 
@@ -46,7 +57,7 @@ func (e *Emptiness) ShouldDisrupt(_ context.Context, c *Candidate) bool {
 }
 
 // ComputeCommand generates a disruption command given candidates
-func (e *Emptiness) ComputeCommand(_ context.Context, candidates ...*Candidate) (Command, error) {
+func (e *Emptiness) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, error) {
 	emptyCandidates := lo.Filter(candidates, func(cn *Candidate, _ int) bool {
 		return cn.NodeClaim.DeletionTimestamp.IsZero() && len(cn.pods) == 0
 	})
@@ -55,6 +66,22 @@ func (e *Emptiness) ComputeCommand(_ context.Context, candidates ...*Candidate)
 		consolidationTypeLabel: e.ConsolidationType(),
 	}).Set(float64(len(candidates)))
 
+	// the nodes that the headroom of a NodePool is kept available on are empty, but they aren't unused
+	emptyCandidates, err := filterHeadroomCandidates(ctx, e.kubeClient, e.cluster, e.provisioner, emptyCandidates)
+	if err != nil {
+		if errors.Is(err, errCandidateDeleting) {
+			return Command{}, nil
+		}
+		return Command{}, fmt.Errorf("filtering headroom candidates, %w", err)
+	}
+	// Only disrupt as many of the remaining empty candidates as the disruption budgets of their nodePools allow.
+	emptyCandidates = lo.Filter(emptyCandidates, func(cn *Candidate, _ int) bool {
+		if disruptionBudgetMapping[cn.nodePool.Name] == 0 {
+			return false
+		}
+		disruptionBudgetMapping[cn.nodePool.Name]--
+		return true
+	})
 	return Command{
 		candidates: emptyCandidates,
 	}, nil
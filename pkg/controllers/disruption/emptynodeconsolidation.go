@@ -35,7 +35,7 @@ func NewEmptyNodeConsolidation(consolidation consolidation) *EmptyNodeConsolidat
 }
 
 // ComputeCommand generates a disruption command given candidates
-func (c *EmptyNodeConsolidation) ComputeCommand(ctx context.Context, candidates ...*Candidate) (Command, error) {
+func (c *EmptyNodeConsolidation) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, error) {
 	if c.isConsolidated() {
 		return Command{}, nil
 	}
@@ -48,11 +48,33 @@ func (c *EmptyNodeConsolidation) ComputeCommand(ctx context.Context, candidates
 		consolidationTypeLabel: c.ConsolidationType(),
 	}).Set(float64(len(candidates)))
 
-	// select the entirely empty NodeClaims
-	emptyCandidates := lo.Filter(candidates, func(n *Candidate, _ int) bool { return len(n.pods) == 0 })
+	emptyCandidates := lo.Filter(candidates, func(n *Candidate, _ int) bool {
+		return len(n.pods) == 0
+	})
+	// the nodes that the headroom of a NodePool is kept available on are empty, but they aren't unused
+	emptyCandidates, err = filterHeadroomCandidates(ctx, c.kubeClient, c.cluster, c.provisioner, emptyCandidates)
+	if err != nil {
+		if errors.Is(err, errCandidateDeleting) {
+			return Command{}, nil
+		}
+		return Command{}, fmt.Errorf("filtering headroom candidates, %w", err)
+	}
+	// select the remaining empty NodeClaims that the disruption budgets of their nodePools allow us to disrupt
+	constrainedByBudgets := false
+	emptyCandidates = lo.Filter(emptyCandidates, func(n *Candidate, _ int) bool {
+		if disruptionBudgetMapping[n.nodePool.Name] == 0 {
+			constrainedByBudgets = true
+			return false
+		}
+		disruptionBudgetMapping[n.nodePool.Name]--
+		return true
+	})
 	if len(emptyCandidates) == 0 {
-		// none empty, so do nothing
-		c.markConsolidated()
+		// none empty, so do nothing. Only mark as consolidated if no candidate was
+		// skipped due to its disruption budget
+		if !constrainedByBudgets {
+			c.markConsolidated()
+		}
 		return Command{}, nil
 	}
 
@@ -75,6 +75,26 @@ func Terminating(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason string) []e
 	}
 }
 
+// Drifted is an event that informs the user how a NodeClaim/Node combination has drifted when it's disrupted due to drift
+func Drifted(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason, message string) []events.Event {
+	return []events.Event{
+		{
+			InvolvedObject: node,
+			Type:           v1.EventTypeNormal,
+			Reason:         "DisruptionDrifted",
+			Message:        fmt.Sprintf("Disrupting Node: Drifted (%s), %s", reason, message),
+			DedupeValues:   []string{string(node.UID), reason},
+		},
+		{
+			InvolvedObject: nodeClaim,
+			Type:           v1.EventTypeNormal,
+			Reason:         "DisruptionDrifted",
+			Message:        fmt.Sprintf("Disrupting NodeClaim: Drifted (%s), %s", reason, message),
+			DedupeValues:   []string{string(nodeClaim.UID), reason},
+		},
+	}
+}
+
 // Unconsolidatable is an event that informs the user that a NodeClaim/Node combination cannot be consolidated
 // due to the state of the NodeClaim/Node or due to some state of the pods that are scheduled to the NodeClaim/Node
 func Unconsolidatable(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason string) []events.Event {
@@ -98,6 +118,50 @@ func Unconsolidatable(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason string
 	}
 }
 
+// SpotToSpotUnconsolidatable is an event that informs the user that a spot NodeClaim/Node combination cannot be
+// consolidated with a cheaper spot replacement
+func SpotToSpotUnconsolidatable(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason string) []events.Event {
+	return []events.Event{
+		{
+			InvolvedObject: node,
+			Type:           v1.EventTypeNormal,
+			Reason:         "SpotToSpotUnconsolidatable",
+			Message:        reason,
+			DedupeValues:   []string{string(node.UID)},
+			DedupeTimeout:  time.Minute * 15,
+		},
+		{
+			InvolvedObject: nodeClaim,
+			Type:           v1.EventTypeNormal,
+			Reason:         "SpotToSpotUnconsolidatable",
+			Message:        reason,
+			DedupeValues:   []string{string(nodeClaim.UID)},
+			DedupeTimeout:  time.Minute * 15,
+		},
+	}
+}
+
+// SpotToSpotConsolidation is an event that informs the user that a spot NodeClaim/Node combination is being
+// replaced with a cheaper spot replacement
+func SpotToSpotConsolidation(node *v1.Node, nodeClaim *v1beta1.NodeClaim, instanceTypes string) []events.Event {
+	return []events.Event{
+		{
+			InvolvedObject: node,
+			Type:           v1.EventTypeNormal,
+			Reason:         "SpotToSpotConsolidation",
+			Message:        fmt.Sprintf("Replacing spot Node with a cheaper spot Node from types %s", instanceTypes),
+			DedupeValues:   []string{string(node.UID)},
+		},
+		{
+			InvolvedObject: nodeClaim,
+			Type:           v1.EventTypeNormal,
+			Reason:         "SpotToSpotConsolidation",
+			Message:        fmt.Sprintf("Replacing spot NodeClaim with a cheaper spot NodeClaim from types %s", instanceTypes),
+			DedupeValues:   []string{string(nodeClaim.UID)},
+		},
+	}
+}
+
 // Blocked is an event that informs the user that a NodeClaim/Node combination is blocked on deprovisioning
 // due to the state of the NodeClaim/Node or due to some state of the pods that are scheduled to the NodeClaim/Node
 func Blocked(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason string) []events.Event {
@@ -118,3 +182,38 @@ func Blocked(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason string) []event
 		},
 	}
 }
+
+// NodePoolBlocked is an event that informs the user that a NodePool's disruption budgets
+// currently allow no NodeClaims owned by the NodePool to be disrupted
+func NodePoolBlocked(nodePool *v1beta1.NodePool) events.Event {
+	return events.Event{
+		InvolvedObject: nodePool,
+		Type:           v1.EventTypeNormal,
+		Reason:         "DisruptionBlocked",
+		Message:        "No allowed disruptions due to blocking budget",
+		DedupeValues:   []string{string(nodePool.UID)},
+		// Set a small DedupeTimeout so that this event is emitted if disruption is blocked repeatedly
+		DedupeTimeout: 1 * time.Minute,
+	}
+}
+
+// DryRun is an event that informs the user that a NodeClaim/Node combination would have been disrupted
+// if the NodePool that owns it wasn't in dry-run mode
+func DryRun(node *v1.Node, nodeClaim *v1beta1.NodeClaim, reason string) []events.Event {
+	return []events.Event{
+		{
+			InvolvedObject: node,
+			Type:           v1.EventTypeNormal,
+			Reason:         "DisruptionDryRun",
+			Message:        fmt.Sprintf("Would disrupt Node: %s", reason),
+			DedupeValues:   []string{string(node.UID), reason},
+		},
+		{
+			InvolvedObject: nodeClaim,
+			Type:           v1.EventTypeNormal,
+			Reason:         "DisruptionDryRun",
+			Message:        fmt.Sprintf("Would disrupt NodeClaim: %s", reason),
+			DedupeValues:   []string{string(nodeClaim.UID), reason},
+		},
+	}
+}
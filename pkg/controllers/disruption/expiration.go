@@ -16,8 +16,8 @@ package disruption
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"math"
 	"sort"
 
 	"k8s.io/utils/clock"
@@ -25,10 +25,7 @@ import (
 	"knative.dev/pkg/logging"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"github.com/samber/lo"
-
 	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
-	disruptionevents "github.com/aws/karpenter-core/pkg/controllers/disruption/events"
 	"github.com/aws/karpenter-core/pkg/controllers/provisioning"
 	"github.com/aws/karpenter-core/pkg/controllers/state"
 	"github.com/aws/karpenter-core/pkg/events"
@@ -75,7 +72,7 @@ func (e *Expiration) filterAndSortCandidates(ctx context.Context, candidates []*
 }
 
 // ComputeCommand generates a disrpution command given candidates
-func (e *Expiration) ComputeCommand(ctx context.Context, candidates ...*Candidate) (Command, error) {
+func (e *Expiration) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, error) {
 	candidates, err := e.filterAndSortCandidates(ctx, candidates)
 	if err != nil {
 		return Command{}, fmt.Errorf("filtering candidates, %w", err)
@@ -85,38 +82,14 @@ func (e *Expiration) ComputeCommand(ctx context.Context, candidates ...*Candidat
 		consolidationTypeLabel: e.ConsolidationType(),
 	}).Set(float64(len(candidates)))
 
-	// Disrupt all empty expired candidates, as they require no scheduling simulations.
-	if empty := lo.Filter(candidates, func(c *Candidate, _ int) bool {
-		return len(c.pods) == 0
-	}); len(empty) > 0 {
-		return Command{
-			candidates: empty,
-		}, nil
+	cmd, err := computeDisruptionCommand(ctx, e.kubeClient, e.cluster, e.provisioner, e.recorder, disruptionBudgetMapping, math.MaxInt, candidates)
+	if err != nil {
+		return Command{}, err
 	}
-
-	for _, candidate := range candidates {
-		// Check if we need to create any NodeClaims.
-		results, err := simulateScheduling(ctx, e.kubeClient, e.cluster, e.provisioner, candidate)
-		if err != nil {
-			// if a candidate node is now deleting, just retry
-			if errors.Is(err, errCandidateDeleting) {
-				continue
-			}
-			return Command{}, err
-		}
-		// Emit an event that we couldn't reschedule the pods on the node.
-		if !results.AllNonPendingPodsScheduled() {
-			e.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, "Scheduling simulation failed to schedule all pods")...)
-			continue
-		}
-
-		logging.FromContext(ctx).With("ttl", candidates[0].nodePool.Spec.Disruption.ExpireAfter.String()).Infof("triggering termination for expired node after TTL")
-		return Command{
-			candidates:   []*Candidate{candidate},
-			replacements: results.NewNodeClaims,
-		}, nil
+	if len(cmd.candidates) == 1 && len(cmd.candidates[0].pods) > 0 {
+		logging.FromContext(ctx).With("ttl", cmd.candidates[0].nodePool.Spec.Disruption.ExpireAfter.String()).Infof("triggering termination for expired node after TTL")
 	}
-	return Command{}, nil
+	return cmd, nil
 }
 
 func (e *Expiration) Type() string {
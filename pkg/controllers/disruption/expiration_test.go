@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,6 +34,7 @@ import (
 	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
 	"github.com/aws/karpenter-core/pkg/cloudprovider"
 	"github.com/aws/karpenter-core/pkg/cloudprovider/fake"
+	"github.com/aws/karpenter-core/pkg/controllers/state"
 	"github.com/aws/karpenter-core/pkg/test"
 	. "github.com/aws/karpenter-core/pkg/test/expectations"
 )
@@ -70,6 +72,105 @@ var _ = Describe("Expiration", func() {
 		})
 		nodeClaim.StatusConditions().MarkTrue(v1beta1.Expired)
 	})
+	Context("Budgets", func() {
+		var numNodes = 10
+		var nodeClaims []*v1beta1.NodeClaim
+		var nodes []*v1.Node
+		BeforeEach(func() {
+			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:     nodePool.Name,
+						v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+						v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+						v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+					},
+				},
+				Status: v1beta1.NodeClaimStatus{
+					Allocatable: map[v1.ResourceName]resource.Quantity{
+						v1.ResourceCPU:  resource.MustParse("32"),
+						v1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			for _, m := range nodeClaims {
+				m.StatusConditions().MarkTrue(v1beta1.Expired)
+			}
+		})
+		It("should only allow 3 empty nodes to be disrupted", func() {
+			nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{{MaxUnavailable: intstr.FromString("30%")}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+			wg.Wait()
+
+			// Expect only 3 of the nodes to be marked for deletion
+			Expect(lo.CountBy(cluster.Nodes(), func(n *state.StateNode) bool { return n.MarkedForDeletion() })).To(Equal(3))
+			Expect(queue.Len()).To(Equal(1))
+		})
+		It("should not allow any empty nodes to be disrupted with a budget of 0", func() {
+			nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{{MaxUnavailable: intstr.FromInt(0)}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+
+			ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+
+			Expect(lo.CountBy(cluster.Nodes(), func(n *state.StateNode) bool { return n.MarkedForDeletion() })).To(Equal(0))
+			Expect(queue.Len()).To(Equal(0))
+			Expect(recorder.DetectedEvent("No allowed disruptions due to blocking budget")).To(BeTrue())
+		})
+		It("should not allow any disruptions while a blocking budget is active", func() {
+			nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{
+				{MaxUnavailable: intstr.FromString("100%")},
+				{
+					MaxUnavailable: intstr.FromInt(0),
+					Crontab:        lo.ToPtr("* * * * *"),
+					Duration:       &metav1.Duration{Duration: time.Hour},
+				},
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+
+			ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+
+			Expect(lo.CountBy(cluster.Nodes(), func(n *state.StateNode) bool { return n.MarkedForDeletion() })).To(Equal(0))
+			Expect(queue.Len()).To(Equal(0))
+		})
+		It("should count nodes that are already being disrupted against the budget", func() {
+			nodePool.Spec.Disruption.Budgets = []v1beta1.Budget{{MaxUnavailable: intstr.FromInt(3)}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			cluster.MarkForDeletion(nodeClaims[0].Status.ProviderID, nodeClaims[1].Status.ProviderID)
+
+			var wg sync.WaitGroup
+			ExpectTriggerVerifyAction(&wg)
+			ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+			wg.Wait()
+
+			// Two nodes were already disrupting, so only one more can be disrupted
+			Expect(lo.CountBy(cluster.Nodes(), func(n *state.StateNode) bool { return n.MarkedForDeletion() })).To(Equal(3))
+			Expect(queue.Len()).To(Equal(1))
+		})
+	})
 	It("should ignore nodes without the expired status condition", func() {
 		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Expired)
 		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
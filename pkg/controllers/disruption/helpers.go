@@ -16,8 +16,10 @@ package disruption
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 
 	"github.com/samber/lo"
@@ -36,6 +38,7 @@ import (
 	pscheduling "github.com/aws/karpenter-core/pkg/controllers/provisioning/scheduling"
 	"github.com/aws/karpenter-core/pkg/controllers/state"
 	"github.com/aws/karpenter-core/pkg/events"
+	"github.com/aws/karpenter-core/pkg/metrics"
 	"github.com/aws/karpenter-core/pkg/scheduling"
 	nodeutils "github.com/aws/karpenter-core/pkg/utils/node"
 	nodepoolutil "github.com/aws/karpenter-core/pkg/utils/nodepool"
@@ -67,9 +70,67 @@ func filterCandidates(ctx context.Context, kubeClient client.Client, recorder ev
 	return nodes, nil
 }
 
-//nolint:gocyclo
+// computeDisruptionCommand generates a command for methods that disrupt their candidates regardless of cost. The
+// candidates must already be filtered and sorted in the order that they should be disrupted. All empty candidates are
+// disrupted together, up to maxEmpty of them. Otherwise, the first candidate whose pods can be rescheduled is
+// disrupted, launching replacements for any pods that don't fit on the remaining nodes.
+func computeDisruptionCommand(ctx context.Context, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner,
+	recorder events.Recorder, disruptionBudgetMapping map[string]int, maxEmpty int, candidates []*Candidate) (Command, error) {
+	// Disrupt all empty candidates, as they require no scheduling simulations.
+	// Only disrupt as many empty candidates as the disruption budgets of their nodePools allow.
+	var empty []*Candidate
+	for _, candidate := range candidates {
+		if len(candidate.pods) > 0 {
+			continue
+		}
+		if disruptionBudgetMapping[candidate.nodePool.Name] > 0 && len(empty) < maxEmpty {
+			empty = append(empty, candidate)
+			disruptionBudgetMapping[candidate.nodePool.Name]--
+		}
+	}
+	if len(empty) > 0 {
+		return Command{
+			candidates: empty,
+		}, nil
+	}
+
+	for _, candidate := range candidates {
+		// If the disruption budget doesn't allow this candidate to be disrupted,
+		// continue to the next candidate. We don't need to decrement any budget
+		// counter since these commands can only have one candidate.
+		if disruptionBudgetMapping[candidate.nodePool.Name] == 0 {
+			continue
+		}
+		// Check if we need to create any NodeClaims.
+		results, err := simulateScheduling(ctx, kubeClient, cluster, provisioner, candidate)
+		if err != nil {
+			// if a candidate is now deleting, just retry
+			if errors.Is(err, errCandidateDeleting) {
+				continue
+			}
+			return Command{}, err
+		}
+		// Emit an event that we couldn't reschedule the pods on the node.
+		if !results.AllNonPendingPodsScheduled() {
+			recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, "Scheduling simulation failed to schedule all pods")...)
+			continue
+		}
+		return Command{
+			candidates:   []*Candidate{candidate},
+			replacements: results.NewNodeClaims,
+		}, nil
+	}
+	return Command{}, nil
+}
+
 func simulateScheduling(ctx context.Context, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner,
 	candidates ...*Candidate) (*pscheduling.Results, error) {
+	return simulate(ctx, kubeClient, cluster, provisioner, nil, candidates...)
+}
+
+//nolint:gocyclo
+func simulate(ctx context.Context, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner,
+	headroomPods []*v1.Pod, candidates ...*Candidate) (*pscheduling.Results, error) {
 	candidateNames := sets.NewString(lo.Map(candidates, func(t *Candidate, i int) string { return t.Name() })...)
 	nodes := cluster.Nodes()
 	deletingNodes := nodes.Deleting()
@@ -97,10 +158,13 @@ func simulateScheduling(ctx context.Context, kubeClient client.Client, cluster *
 		return nil, fmt.Errorf("determining pending pods, %w", err)
 	}
 
+	candidatePods := sets.New[*v1.Pod]()
 	for _, n := range candidates {
 		pods = append(pods, n.pods...)
+		candidatePods.Insert(n.pods...)
 	}
 	pods = append(pods, deletingNodePods...)
+	pods = append(pods, headroomPods...)
 	scheduler, err := provisioner.NewScheduler(ctx, pods, stateNodes, pscheduling.SchedulerOptions{
 		SimulationMode: true,
 	})
@@ -112,11 +176,15 @@ func simulateScheduling(ctx context.Context, kubeClient client.Client, cluster *
 	results := scheduler.Solve(ctx, pods)
 	// check if the scheduling relied on an existing node that isn't ready yet, if so we fail
 	// to schedule since we want to assume that we can delete a node and its pods will immediately
-	// move to an existing node which won't occur if that node isn't ready.
+	// move to an existing node which won't occur if that node isn't ready. This only applies to the
+	// pods of the candidates, as the pods of deleting nodes are already handled by the in-flight commands
+	// that launched these nodes for them, and the headroom will be available once the nodes are initialized.
 	for _, n := range results.ExistingNodes {
 		if !n.Initialized() || nodeutils.GetCondition(n.Node, v1.NodeReady).Status != v1.ConditionTrue {
 			for _, p := range n.Pods {
-				results.PodErrors[p] = fmt.Errorf("would schedule against a non-initialized node %s", n.Name())
+				if candidatePods.Has(p) {
+					results.PodErrors[p] = fmt.Errorf("would schedule against a non-initialized node %s", n.Name())
+				}
 			}
 		}
 	}
@@ -124,6 +192,79 @@ func simulateScheduling(ctx context.Context, kubeClient client.Client, cluster *
 	return results, nil
 }
 
+// filterHeadroomCandidates removes the empty candidates that the headroom of their NodePools is kept available on.
+// Empty candidates are otherwise disrupted without simulating scheduling, as they don't have any pods to reschedule,
+// so this keeps the nodes that were launched for the headroom from being removed as soon as they're empty.
+func filterHeadroomCandidates(ctx context.Context, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner,
+	candidates []*Candidate) ([]*Candidate, error) {
+	hasHeadroom := func(c *Candidate, _ int) bool { return c.nodePool.Spec.Headroom != nil }
+	headroomCandidates := lo.Filter(candidates, hasHeadroom)
+	if len(headroomCandidates) == 0 {
+		return candidates, nil
+	}
+	// each candidate with headroom is only removed if the headroom still fits on the remaining nodes of its NodePool
+	removable := lo.Reject(candidates, hasHeadroom)
+	for _, candidate := range headroomCandidates {
+		reduced, err := reducedHeadroom(ctx, kubeClient, cluster, provisioner, append([]*Candidate{candidate}, removable...)...)
+		if err != nil {
+			return nil, err
+		}
+		if len(reduced) == 0 {
+			removable = append(removable, candidate)
+		}
+	}
+	return lo.Filter(candidates, func(c *Candidate, _ int) bool { return lo.Contains(removable, c) }), nil
+}
+
+// reducedHeadroom returns the names of the candidates' NodePools whose headroom would fit on fewer of the existing
+// nodes if the candidates were removed. The headroom is simulated separately from the pods that are rescheduled, so
+// that it doesn't change the replacements, and only the headroom of the candidates' own NodePools is considered.
+func reducedHeadroom(ctx context.Context, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner,
+	candidates ...*Candidate) ([]string, error) {
+	var headroomPods []*v1.Pod
+	for _, nodePool := range lo.UniqBy(lo.Map(candidates, func(c *Candidate, _ int) *v1beta1.NodePool { return c.nodePool }),
+		func(np *v1beta1.NodePool) string { return np.Name }) {
+		if nodePool.DeletionTimestamp.IsZero() {
+			headroomPods = append(headroomPods, pscheduling.HeadroomPods(nodePool)...)
+		}
+	}
+	if len(headroomPods) == 0 {
+		return nil, nil
+	}
+	before, err := simulate(ctx, kubeClient, cluster, provisioner, headroomPods)
+	if err != nil {
+		return nil, err
+	}
+	after, err := simulate(ctx, kubeClient, cluster, provisioner, headroomPods, candidates...)
+	if err != nil {
+		return nil, err
+	}
+	unavailableBefore, unavailableAfter := unavailableHeadroom(before), unavailableHeadroom(after)
+	reduced := lo.Filter(lo.Keys(unavailableAfter), func(nodePoolName string, _ int) bool {
+		return unavailableAfter[nodePoolName] > unavailableBefore[nodePoolName]
+	})
+	sort.Strings(reduced)
+	return reduced, nil
+}
+
+// unavailableHeadroom returns the number of headroom pods of each NodePool that don't schedule to the existing nodes
+func unavailableHeadroom(results *pscheduling.Results) map[string]int {
+	unavailable := map[string]int{}
+	for p := range results.PodErrors {
+		if pod.IsHeadroom(p) {
+			unavailable[p.Annotations[v1beta1.HeadroomAnnotationKey]]++
+		}
+	}
+	for _, n := range results.NewNodeClaims {
+		for _, p := range n.Pods {
+			if pod.IsHeadroom(p) {
+				unavailable[p.Annotations[v1beta1.HeadroomAnnotationKey]]++
+			}
+		}
+	}
+	return unavailable
+}
+
 // instanceTypesAreSubset returns true if the lhs slice of instance types are a subset of the rhs.
 func instanceTypesAreSubset(lhs []*cloudprovider.InstanceType, rhs []*cloudprovider.InstanceType) bool {
 	rhsNames := sets.NewString(lo.Map(rhs, func(t *cloudprovider.InstanceType, i int) string { return t.Name })...)
@@ -155,10 +296,15 @@ func GetPodEvictionCost(ctx context.Context, p *v1.Pod) float64 {
 	return clamp(-10.0, cost, 10.0)
 }
 
-func filterByPrice(options []*cloudprovider.InstanceType, reqs scheduling.Requirements, price float64) []*cloudprovider.InstanceType {
+// filterByPrice returns the instance types that have a worst-case launch price lower than the passed price. Offerings that
+// have recently returned insufficient capacity are ignored since they aren't options that we could launch with.
+func filterByPrice(options []*cloudprovider.InstanceType, reqs scheduling.Requirements, price float64,
+	unavailableOfferings *cloudprovider.UnavailableOfferings) []*cloudprovider.InstanceType {
 	var result []*cloudprovider.InstanceType
 	for _, it := range options {
-		launchPrice := worstLaunchPrice(it.Offerings.Available(), reqs)
+		launchPrice := worstLaunchPrice(lo.Reject(it.Offerings.Available(), func(of cloudprovider.Offering, _ int) bool {
+			return unavailableOfferings.IsUnavailable(it.Name, of.Zone, of.CapacityType)
+		}), reqs)
 		if launchPrice < price {
 			result = append(result, it)
 		}
@@ -166,6 +312,34 @@ func filterByPrice(options []*cloudprovider.InstanceType, reqs scheduling.Requir
 	return result
 }
 
+// estimatedSavings returns the difference between the hourly price of the command's candidates and the price of its
+// replacements. Each replacement is priced at the worst-case launch price of its cheapest instance type option.
+func estimatedSavings(cmd Command) (float64, error) {
+	price, err := getCandidatePrices(cmd.candidates)
+	if err != nil {
+		return 0.0, err
+	}
+	for _, r := range cmd.replacements {
+		launchPrice, err := replacementPrice(r)
+		if err != nil {
+			return 0.0, err
+		}
+		price -= launchPrice
+	}
+	return price, nil
+}
+
+// replacementPrice returns the worst-case launch price of the replacement's cheapest instance type option
+func replacementPrice(r *pscheduling.NodeClaim) (float64, error) {
+	launchPrice := lo.Min(lo.Map(r.InstanceTypeOptions, func(it *cloudprovider.InstanceType, _ int) float64 {
+		return worstLaunchPrice(it.Offerings.Available(), r.Requirements)
+	}))
+	if len(r.InstanceTypeOptions) == 0 || launchPrice == math.MaxFloat64 {
+		return 0.0, fmt.Errorf("unable to determine launch price for replacement")
+	}
+	return launchPrice, nil
+}
+
 func disruptionCost(ctx context.Context, pods []*v1.Pod) float64 {
 	cost := 0.0
 	for _, p := range pods {
@@ -189,6 +363,53 @@ func GetCandidates(ctx context.Context, cluster *state.Cluster, kubeClient clien
 	return lo.Filter(candidates, func(c *Candidate, _ int) bool { return shouldDeprovision(ctx, c) }), nil
 }
 
+// BuildDisruptionBudgets will return a map for nodePoolName -> numAllowedDisruptions and an error
+func BuildDisruptionBudgets(ctx context.Context, cluster *state.Cluster, clk clock.Clock, kubeClient client.Client, recorder events.Recorder) (map[string]int, error) {
+	numNodes := map[string]int{}
+	disrupting := map[string]int{}
+	disruptionBudgetMapping := map[string]int{}
+	for _, node := range cluster.Nodes() {
+		// We only consider nodes that we own and are initialized towards the total.
+		// If a node is launched/registered, but not initialized, pods aren't scheduled
+		// to the node, and these are treated as empty anyways.
+		if !node.Managed() || !node.Initialized() {
+			continue
+		}
+		nodePoolName, ok := node.Labels()[v1beta1.NodePoolLabelKey]
+		if !ok {
+			continue
+		}
+		numNodes[nodePoolName]++
+		// Nodes that are already being disrupted count against the budget of their NodePool
+		if node.MarkedForDeletion() {
+			disrupting[nodePoolName]++
+		}
+	}
+	nodePoolList, err := nodepoolutil.List(ctx, kubeClient)
+	if err != nil {
+		return nil, fmt.Errorf("listing node pools, %w", err)
+	}
+	for i := range nodePoolList.Items {
+		np := &nodePoolList.Items[i]
+		// A misconfigured budget is treated as blocking all disruptions for the NodePool
+		allowed, err := np.GetAllowedDisruptions(ctx, clk, numNodes[np.Name])
+		if err != nil {
+			allowed = 0
+		}
+		// Floor the value since the number of disrupting nodes can exceed the number of allowed disruptions
+		allowed = lo.Clamp(allowed-disrupting[np.Name], 0, math.MaxInt32)
+		disruptionBudgetMapping[np.Name] = allowed
+
+		disruptionBudgetsAllowedDisruptionsGauge.With(map[string]string{
+			metrics.NodePoolLabel: np.Name,
+		}).Set(float64(allowed))
+		if allowed == 0 {
+			recorder.Publish(disruptionevents.NodePoolBlocked(np))
+		}
+	}
+	return disruptionBudgetMapping, nil
+}
+
 // buildNodePoolMap builds a provName -> nodePool map and a provName -> instanceName -> instance type map
 func buildNodePoolMap(ctx context.Context, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) (map[string]*v1beta1.NodePool, map[string]map[string]*cloudprovider.InstanceType, error) {
 	nodePoolMap := map[string]*v1beta1.NodePool{}
@@ -212,7 +433,7 @@ func buildNodePoolMap(ctx context.Context, kubeClient client.Client, cloudProvid
 			continue
 		}
 		nodePoolToInstanceTypesMap[np.Name] = map[string]*cloudprovider.InstanceType{}
-		for _, it := range nodePoolInstanceTypes {
+		for _, it := range cloudprovider.ApplyKubeletConfiguration(nodePoolInstanceTypes, np.Spec.Template.Spec.Kubelet) {
 			nodePoolToInstanceTypesMap[np.Name][it.Name] = it
 		}
 	}
@@ -231,6 +452,17 @@ func mapCandidates(proposed, current []*Candidate) []*Candidate {
 // on an instance type. If the instance type has a spot offering available, then it uses the spot offering
 // to get the launch price; else, it uses the on-demand launch price
 func worstLaunchPrice(ofs []cloudprovider.Offering, reqs scheduling.Requirements) float64 {
+	// Reservations are filled before any other capacity is launched, so they're priced first
+	if reqs.Get(v1beta1.CapacityTypeLabelKey).Has(v1beta1.CapacityTypeReserved) {
+		reservedOfferings := lo.Filter(ofs, func(of cloudprovider.Offering, _ int) bool {
+			return of.CapacityType == v1beta1.CapacityTypeReserved && reqs.Get(v1.LabelTopologyZone).Has(of.Zone)
+		})
+		if len(reservedOfferings) > 0 {
+			return lo.MaxBy(reservedOfferings, func(of1, of2 cloudprovider.Offering) bool {
+				return of1.Price > of2.Price
+			}).Price
+		}
+	}
 	// We prefer to launch spot offerings, so we will get the worst price based on the node requirements
 	if reqs.Get(v1beta1.CapacityTypeLabelKey).Has(v1beta1.CapacityTypeSpot) {
 		spotOfferings := lo.Filter(ofs, func(of cloudprovider.Offering, _ int) bool {
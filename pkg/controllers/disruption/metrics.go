@@ -23,7 +23,9 @@ import (
 
 func init() {
 	crmetrics.Registry.MustRegister(disruptionEvaluationDurationHistogram, disruptionActionsPerformedCounter,
-		disruptionEligibleNodesGauge, disruptionConsolidationTimeoutTotalCounter)
+		disruptionEligibleNodesGauge, disruptionConsolidationTimeoutTotalCounter, disruptionBudgetsAllowedDisruptionsGauge,
+		disruptionDryRunActionsCounter, disruptionDryRunCandidatesCounter, disruptionDryRunReplacementsCounter,
+		disruptionDryRunEstimatedSavingsHistogram, disruptionSpotToSpotActionsPerformedCounter)
 }
 
 const (
@@ -71,4 +73,59 @@ var (
 		},
 		[]string{consolidationTypeLabel},
 	)
+	disruptionBudgetsAllowedDisruptionsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: disruptionSubsystem,
+			Name:      "budgets_allowed_disruptions",
+			Help:      "The number of nodes for a given NodePool that can be disrupted at a point in time. Labeled by NodePool. Note that allowed disruptions can change very rapidly, as new nodes may be created and others may be deleted at any point.",
+		},
+		[]string{metrics.NodePoolLabel},
+	)
+	disruptionSpotToSpotActionsPerformedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: disruptionSubsystem,
+			Name:      "spot_to_spot_actions_performed_total",
+			Help:      "Number of consolidation actions performed that replaced spot nodes with a cheaper spot node. Labeled by consolidation type.",
+		},
+		[]string{consolidationTypeLabel},
+	)
+	disruptionDryRunActionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: disruptionSubsystem,
+			Name:      "dry_run_actions_total",
+			Help:      "Number of disruption actions that would have been performed if disruption wasn't in dry-run mode. Labeled by disruption method.",
+		},
+		[]string{actionLabel, methodLabel, consolidationTypeLabel},
+	)
+	disruptionDryRunCandidatesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: disruptionSubsystem,
+			Name:      "dry_run_candidates_total",
+			Help:      "Number of nodes that would have been disrupted if disruption wasn't in dry-run mode. Labeled by disruption method.",
+		},
+		[]string{actionLabel, methodLabel, consolidationTypeLabel},
+	)
+	disruptionDryRunReplacementsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: disruptionSubsystem,
+			Name:      "dry_run_replacements_total",
+			Help:      "Number of replacement nodes that would have been launched if disruption wasn't in dry-run mode. Labeled by disruption method.",
+		},
+		[]string{actionLabel, methodLabel, consolidationTypeLabel},
+	)
+	disruptionDryRunEstimatedSavingsHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: disruptionSubsystem,
+			Name:      "dry_run_estimated_savings",
+			Help:      "Estimated hourly price savings of the disruption actions that would have been performed if disruption wasn't in dry-run mode. Negative values indicate an increase in price. Labeled by disruption method.",
+			Buckets:   []float64{-1, -0.1, 0, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{actionLabel, methodLabel, consolidationTypeLabel},
+	)
 )
@@ -39,7 +39,7 @@ func NewMultiNodeConsolidation(consolidation consolidation) *MultiNodeConsolidat
 	return &MultiNodeConsolidation{consolidation: consolidation}
 }
 
-func (m *MultiNodeConsolidation) ComputeCommand(ctx context.Context, candidates ...*Candidate) (Command, error) {
+func (m *MultiNodeConsolidation) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, error) {
 	if m.isConsolidated() {
 		return Command{}, nil
 	}
@@ -52,18 +52,36 @@ func (m *MultiNodeConsolidation) ComputeCommand(ctx context.Context, candidates
 		consolidationTypeLabel: m.ConsolidationType(),
 	}).Set(float64(len(candidates)))
 
+	// In order, filter out all candidates that would violate the budget.
+	// Since multi-node consolidation relies on the ordering of these candidates and
+	// binary searches over batches of candidates[0, n], pre-filtering preserves the
+	// ordering while only considering as many candidates as the budgets allow.
+	disruptableCandidates := make([]*Candidate, 0, len(candidates))
+	constrainedByBudgets := false
+	for _, candidate := range candidates {
+		if disruptionBudgetMapping[candidate.nodePool.Name] == 0 {
+			constrainedByBudgets = true
+			continue
+		}
+		disruptableCandidates = append(disruptableCandidates, candidate)
+		disruptionBudgetMapping[candidate.nodePool.Name]--
+	}
+
 	// Only consider a maximum batch of 100 NodeClaims to save on computation.
 	// This could be further configurable in the future.
-	maxParallel := lo.Clamp(len(candidates), 0, 100)
+	maxParallel := lo.Clamp(len(disruptableCandidates), 0, 100)
 
-	cmd, err := m.firstNConsolidationOption(ctx, candidates, maxParallel)
+	cmd, err := m.firstNConsolidationOption(ctx, disruptableCandidates, maxParallel)
 	if err != nil {
 		return Command{}, err
 	}
 
 	if cmd.Action() == NoOpAction {
-		// couldn't identify any candidates
-		m.markConsolidated()
+		// couldn't identify any candidates, only mark as consolidated if no candidate was
+		// skipped due to its disruption budget
+		if !constrainedByBudgets {
+			m.markConsolidated()
+		}
 		return cmd, nil
 	}
 
@@ -118,8 +136,17 @@ func (m *MultiNodeConsolidation) firstNConsolidationOption(ctx context.Context,
 		// required
 		replacementHasValidInstanceTypes := false
 		if cmd.Action() == ReplaceAction {
-			cmd.replacements[0].InstanceTypeOptions = filterOutSameType(cmd.replacements[0], candidatesToConsolidate)
+			cmd.replacements[0].InstanceTypeOptions = filterOutSameType(cmd.replacements[0], candidatesToConsolidate, m.unavailableOfferings)
 			replacementHasValidInstanceTypes = len(cmd.replacements[0].InstanceTypeOptions) > 0
+			// spot-to-spot replacements must keep enough instance type flexibility after filtering
+			if cmd.spotToSpot {
+				replacementHasValidInstanceTypes = len(cmd.replacements[0].InstanceTypeOptions) >= MinInstanceTypesForSpotToSpotConsolidation
+			}
+			// the remaining instance types must still satisfy the minValues of the replacement's requirements
+			if replacementHasValidInstanceTypes {
+				_, err = cmd.replacements[0].InstanceTypeOptions.SatisfiesMinValues(cmd.replacements[0].Requirements)
+				replacementHasValidInstanceTypes = err == nil
+			}
 		}
 
 		// replacementHasValidInstanceTypes will be false if the replacement action has valid instance types remaining after filtering.
@@ -150,7 +177,8 @@ func (m *MultiNodeConsolidation) firstNConsolidationOption(ctx context.Context,
 // This code sees that t3a.small is the cheapest type in both lists and filters it and anything more expensive out
 // leaving the valid consolidation:
 // NodeClaims=[t3a.2xlarge, t3a.2xlarge, t3a.small] -> 1 of t3a.nano
-func filterOutSameType(newNodeClaim *scheduling.NodeClaim, consolidate []*Candidate) []*cloudprovider.InstanceType {
+func filterOutSameType(newNodeClaim *scheduling.NodeClaim, consolidate []*Candidate,
+	unavailableOfferings *cloudprovider.UnavailableOfferings) []*cloudprovider.InstanceType {
 	existingInstanceTypes := sets.New[string]()
 	pricesByInstanceType := map[string]float64{}
 
@@ -182,7 +210,7 @@ func filterOutSameType(newNodeClaim *scheduling.NodeClaim, consolidate []*Candid
 		}
 	}
 
-	return filterByPrice(newNodeClaim.InstanceTypeOptions, newNodeClaim.Requirements, maxPrice)
+	return filterByPrice(newNodeClaim.InstanceTypeOptions, newNodeClaim.Requirements, maxPrice, unavailableOfferings)
 }
 
 func (m *MultiNodeConsolidation) Type() string {
@@ -0,0 +1,193 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/controllers/state"
+	"github.com/aws/karpenter-core/pkg/utils/nodeclaim"
+)
+
+// persistedCommand is the serialized form of a Command. It's stored as an annotation on each of the command's
+// candidate NodeClaims for as long as the command is in-flight so that the queue can be restored if Karpenter
+// restarts or loses leadership.
+type persistedCommand struct {
+	Candidates        []string  `json:"candidates"`
+	Replacements      []string  `json:"replacements,omitempty"`
+	Method            string    `json:"method"`
+	ConsolidationType string    `json:"consolidationType,omitempty"`
+	Record            string    `json:"record,omitempty"`
+	TimeAdded         time.Time `json:"timeAdded"`
+}
+
+// persist stores the command on its candidate NodeClaims
+func (q *Queue) persist(ctx context.Context, cmd *Command) error {
+	raw, err := json.Marshal(persistedCommand{
+		Candidates:        lo.Map(cmd.candidates, func(s *state.StateNode, _ int) string { return s.NodeClaim.Name }),
+		Replacements:      lo.Map(cmd.Replacements, func(r Replacement, _ int) string { return r.name }),
+		Method:            cmd.method,
+		ConsolidationType: cmd.consolidationType,
+		Record:            cmd.record,
+		TimeAdded:         cmd.timeAdded,
+	})
+	if err != nil {
+		return fmt.Errorf("serializing command, %w", err)
+	}
+	return multierr.Combine(lo.Map(cmd.candidates, func(s *state.StateNode, _ int) error {
+		return q.patchCommandAnnotation(ctx, s.NodeClaim.Name, lo.ToPtr(string(raw)))
+	})...)
+}
+
+// unpersist removes the command from its candidate NodeClaims
+func (q *Queue) unpersist(ctx context.Context, cmd *Command) error {
+	return multierr.Combine(lo.Map(cmd.candidates, func(s *state.StateNode, _ int) error {
+		return q.patchCommandAnnotation(ctx, s.NodeClaim.Name, nil)
+	})...)
+}
+
+// patchCommandAnnotation sets the command annotation on the NodeClaim, removing it if the value is nil. We use a raw
+// merge patch since the NodeClaims that are tracked in cluster state may not have observed a previous patch yet.
+func (q *Queue) patchCommandAnnotation(ctx context.Context, name string, value *string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{v1beta1.DisruptionCommandAnnotationKey: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	nodeClaim := &v1beta1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if err := q.kubeClient.Patch(ctx, nodeClaim, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return client.IgnoreNotFound(fmt.Errorf("patching nodeclaim %s, %w", name, err))
+	}
+	return nil
+}
+
+// Restore rebuilds the queue from the commands that were persisted on NodeClaims before Karpenter restarted. Commands
+// are resumed from where they left off, so the queue will wait for their replacements to initialize and then delete
+// their candidates, timing out as usual. Commands that can't be restored are dropped by untainting their candidates so
+// that they don't block disruption. Only transient errors fail the restore, in which case it's retried. Restore must be
+// called once cluster state has synced and before any new commands are computed.
+func (q *Queue) Restore(ctx context.Context) error {
+	nodeClaimList, err := nodeclaim.List(ctx, q.kubeClient)
+	if err != nil {
+		return fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	// Each candidate of a command is annotated with the same serialized command
+	commands := map[string]sets.Set[string]{}
+	for i := range nodeClaimList.Items {
+		if raw, ok := nodeClaimList.Items[i].Annotations[v1beta1.DisruptionCommandAnnotationKey]; ok {
+			if _, ok := commands[raw]; !ok {
+				commands[raw] = sets.New[string]()
+			}
+			commands[raw].Insert(nodeClaimList.Items[i].Name)
+		}
+	}
+	var errs []error
+	for raw, names := range commands {
+		errs = append(errs, q.restoreCommand(ctx, raw, names))
+	}
+	if err = multierr.Combine(errs...); err != nil {
+		return err
+	}
+	q.restored.Store(true)
+	return nil
+}
+
+// Restored returns true once the queue has been rebuilt from the persisted commands
+func (q *Queue) Restored() bool {
+	return q.restored.Load()
+}
+
+func (q *Queue) restoreCommand(ctx context.Context, raw string, names sets.Set[string]) error {
+	pc := persistedCommand{}
+	if err := json.Unmarshal([]byte(raw), &pc); err != nil {
+		// We can't tell what the command was doing, so the safest thing we can do is return the candidates that
+		// we know about to service
+		logging.FromContext(ctx).With("nodeclaims", sets.List(names)).Errorf("dropping disruption command, %s", err)
+		return q.drop(ctx, names)
+	}
+	cmd := &Command{
+		Replacements: lo.Map(pc.Replacements, func(name string, _ int) Replacement {
+			return Replacement{name: name}
+		}),
+		// Candidates that have already been removed from the cluster don't need to be disrupted again
+		candidates:        q.stateNodes(sets.New(pc.Candidates...)),
+		method:            pc.Method,
+		consolidationType: pc.ConsolidationType,
+		record:            pc.Record,
+		timeAdded:         pc.TimeAdded,
+	}
+	if len(cmd.candidates) == 0 {
+		// The command may still be persisted on NodeClaims that cluster state doesn't track, so clear it from them
+		if err := q.clear(ctx, names); err != nil {
+			return fmt.Errorf("clearing command, %w", err)
+		}
+		q.transitionRecord(ctx, cmd, v1beta1.DisruptionPhaseSucceeded)
+		return nil
+	}
+	providerIDs := lo.Map(cmd.candidates, func(s *state.StateNode, _ int) string { return s.ProviderID() })
+	if q.HasAny(providerIDs...) {
+		return nil
+	}
+	if err := state.RequireNoScheduleTaint(ctx, q.kubeClient, true, cmd.candidates...); err != nil {
+		return fmt.Errorf("tainting nodes, %w", err)
+	}
+	q.cluster.MarkForDeletion(providerIDs...)
+	q.add(cmd)
+	logging.FromContext(ctx).With("nodes", lo.Map(cmd.candidates, func(s *state.StateNode, _ int) string {
+		return s.Name()
+	})).Infof("resuming disruption via %s", cmd.Reason())
+	return nil
+}
+
+// drop untaints the candidates of a command that can't be restored and clears it from the NodeClaims it was persisted
+// on so that the candidates can be considered for disruption again
+func (q *Queue) drop(ctx context.Context, names sets.Set[string]) error {
+	if err := multierr.Combine(
+		state.RequireNoScheduleTaint(ctx, q.kubeClient, false, q.stateNodes(names)...),
+		q.clear(ctx, names),
+	); err != nil {
+		return fmt.Errorf("dropping command, %w", err)
+	}
+	return nil
+}
+
+// clear removes the persisted command from the named NodeClaims, whether or not they're tracked in cluster state
+func (q *Queue) clear(ctx context.Context, names sets.Set[string]) error {
+	return multierr.Combine(lo.Map(sets.List(names), func(name string, _ int) error {
+		return q.patchCommandAnnotation(ctx, name, nil)
+	})...)
+}
+
+// stateNodes returns the state nodes that are tracked in cluster state for the named NodeClaims
+func (q *Queue) stateNodes(names sets.Set[string]) []*state.StateNode {
+	return lo.Filter(q.cluster.Nodes(), func(s *state.StateNode, _ int) bool {
+		return s.NodeClaim != nil && names.Has(s.NodeClaim.Name)
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/samber/lo"
@@ -41,6 +42,7 @@ import (
 	disruptionevents "github.com/aws/karpenter-core/pkg/controllers/disruption/events"
 	"github.com/aws/karpenter-core/pkg/controllers/state"
 	"github.com/aws/karpenter-core/pkg/events"
+	"github.com/aws/karpenter-core/pkg/utils/disruptionrecord"
 	"github.com/aws/karpenter-core/pkg/utils/nodeclaim"
 )
 
@@ -56,6 +58,7 @@ type Command struct {
 	timeAdded         time.Time // timeAdded is used to track timeouts
 	method            string    // used for metrics
 	consolidationType string    // used for metrics
+	record            string    // name of the DisruptionRecord that tracks the command
 	lastError         error
 }
 
@@ -95,6 +98,7 @@ type Queue struct {
 
 	mu                  sync.RWMutex
 	providerIDToCommand map[string]*Command // providerID -> command, maps a candidate to its command
+	restored            atomic.Bool         // restored is set once the queue has been rebuilt from persisted commands
 
 	kubeClient  client.Client
 	recorder    events.Recorder
@@ -118,7 +122,8 @@ func NewQueue(kubeClient client.Client, recorder events.Recorder, cluster *state
 	return queue
 }
 
-// NewTestingQueue uses a test RateLimitingInterface that will immediately re-queue items.
+// NewTestingQueue uses a test RateLimitingInterface that will immediately re-queue items. The testing queue
+// starts out restored so that tests don't need to reconcile it before computing commands.
 func NewTestingQueue(kubeClient client.Client, recorder events.Recorder, cluster *state.Cluster, clock clock.Clock,
 	provisioner *provisioning.Provisioner) *Queue {
 	queue := &Queue{
@@ -130,6 +135,7 @@ func NewTestingQueue(kubeClient client.Client, recorder events.Recorder, cluster
 		clock:                 clock,
 		provisioner:           provisioner,
 	}
+	queue.restored.Store(true)
 	return queue
 }
 
@@ -145,6 +151,12 @@ func NewCommand(replacements []string, candidates []*state.StateNode, method str
 	}
 }
 
+// WithRecord sets the name of the DisruptionRecord that is updated as the command is orchestrated
+func (c *Command) WithRecord(name string) *Command {
+	c.record = name
+	return c
+}
+
 func (q *Queue) Name() string {
 	return "disruption.queue"
 }
@@ -157,7 +169,19 @@ func (q *Queue) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.R
 	// The queue depth is the number of commands currently being considered.
 	// This should not use the RateLimitingInterface.Len() method, as this does not include
 	// commands that haven't completed their requeue backoff.
-	disruptionQueueDepthGauge.Set(float64(len(lo.Uniq(lo.Values(q.providerIDToCommand)))))
+	disruptionQueueDepthGauge.Set(float64(q.Count()))
+
+	// Commands that were in-flight when Karpenter last stopped are restored once we know about all the nodes in
+	// the cluster. The disruption controller doesn't compute new commands until this has happened.
+	if !q.Restored() {
+		if !q.cluster.Synced(ctx) {
+			logging.FromContext(ctx).Debugf("waiting on cluster sync")
+			return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
+		}
+		if err := q.Restore(ctx); err != nil {
+			return reconcile.Result{}, fmt.Errorf("restoring commands, %w", err)
+		}
+	}
 
 	// Check if the queue is empty. client-go recommends not using this function to gate the subsequent
 	// get call, but since we're popping items off the queue synchronously retrying, there should be
@@ -185,7 +209,8 @@ func (q *Queue) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.R
 		// If the command failed, bail on the action.
 		// 1. Emit metrics for launch failures
 		// 2. Ensure cluster state no longer thinks these nodes are deleting
-		// 3. Remove it from the Queue's internal data structure
+		// 3. Remove the persisted command from the candidates
+		// 4. Remove it from the Queue's internal data structure
 		failedLaunches := lo.Filter(cmd.Replacements, func(r Replacement, _ int) bool {
 			return !r.Initialized
 		})
@@ -193,11 +218,17 @@ func (q *Queue) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.R
 			methodLabel:            cmd.method,
 			consolidationTypeLabel: cmd.consolidationType,
 		}).Add(float64(len(failedLaunches)))
-		multiErr := multierr.Combine(err, cmd.lastError, state.RequireNoScheduleTaint(ctx, q.kubeClient, false, cmd.candidates...))
+		multiErr := multierr.Combine(err, cmd.lastError, state.RequireNoScheduleTaint(ctx, q.kubeClient, false, cmd.candidates...), q.unpersist(ctx, cmd))
 		// Log the error
 		logging.FromContext(ctx).With("nodes", strings.Join(lo.Map(cmd.candidates, func(s *state.StateNode, _ int) string {
 			return s.Name()
 		}), ",")).Errorf("failed to disrupt nodes, %s", multiErr)
+		q.transitionRecord(ctx, cmd, v1beta1.DisruptionPhaseFailed, disruptionrecord.WithMessage(multiErr.Error()))
+	} else {
+		if err := q.unpersist(ctx, cmd); err != nil {
+			logging.FromContext(ctx).Errorf("removing persisted command, %s", err)
+		}
+		q.transitionRecord(ctx, cmd, v1beta1.DisruptionPhaseSucceeded)
 	}
 	// If command is complete, remove command from queue.
 	q.Remove(cmd)
@@ -253,6 +284,7 @@ func (q *Queue) waitOrTerminate(ctx context.Context, cmd *Command) error {
 	// All replacements have been provisioned.
 	// All we need to do now is get a successful delete call for each node claim,
 	// then the termination controller will handle the eventual deletion of the nodes.
+	q.transitionRecord(ctx, cmd, v1beta1.DisruptionPhaseTerminating)
 	var multiErr error
 	for i := range cmd.candidates {
 		candidate := cmd.candidates[i]
@@ -271,9 +303,21 @@ func (q *Queue) waitOrTerminate(ctx context.Context, cmd *Command) error {
 	return nil
 }
 
+// transitionRecord moves the command's DisruptionRecord into the passed phase. Records are best-effort, so failures
+// are logged rather than failing the command.
+func (q *Queue) transitionRecord(ctx context.Context, cmd *Command, phase v1beta1.DisruptionPhase, opts ...disruptionrecord.TransitionOption) {
+	if cmd.record == "" {
+		return
+	}
+	if err := disruptionrecord.Transition(ctx, q.kubeClient, cmd.record, phase, q.clock.Now(), opts...); err != nil {
+		logging.FromContext(ctx).With("disruption-record", cmd.record).Errorf("transitioning disruption record to %s, %s", phase, err)
+	}
+}
+
 // Add adds commands to the Queue
-// Each command added to the queue should already be validated and ready for execution.
-func (q *Queue) Add(cmd *Command) error {
+// Each command added to the queue should already be validated and ready for execution. The command is persisted
+// on its candidates so that it can be restored if Karpenter restarts before the command completes.
+func (q *Queue) Add(ctx context.Context, cmd *Command) error {
 	providerIDs := lo.Map(cmd.candidates, func(s *state.StateNode, _ int) string {
 		return s.ProviderID()
 	})
@@ -283,13 +327,20 @@ func (q *Queue) Add(cmd *Command) error {
 	}
 
 	cmd.timeAdded = q.clock.Now()
+	if err := q.persist(ctx, cmd); err != nil {
+		return fmt.Errorf("persisting command, %w", multierr.Append(err, q.unpersist(ctx, cmd)))
+	}
+	q.add(cmd)
+	return nil
+}
+
+func (q *Queue) add(cmd *Command) {
 	q.mu.Lock()
 	for _, candidate := range cmd.candidates {
 		q.providerIDToCommand[candidate.ProviderID()] = cmd
 	}
 	q.mu.Unlock()
 	q.RateLimitingInterface.Add(cmd)
-	return nil
 }
 
 // HasAny checks to see if the candidate is part of an currently executing command.
@@ -327,6 +378,13 @@ func (q *Queue) Reset() {
 	q.providerIDToCommand = map[string]*Command{}
 }
 
+// Count returns the number of commands that are currently being executed
+func (q *Queue) Count() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return len(lo.Uniq(lo.Values(q.providerIDToCommand)))
+}
+
 func (q *Queue) IsEmpty() bool {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
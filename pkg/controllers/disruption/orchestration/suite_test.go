@@ -21,6 +21,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -30,6 +31,7 @@ import (
 	"github.com/aws/karpenter-core/pkg/apis"
 	"github.com/aws/karpenter-core/pkg/apis/settings"
 	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
 	"github.com/aws/karpenter-core/pkg/cloudprovider/fake"
 	disruptionevents "github.com/aws/karpenter-core/pkg/controllers/disruption/events"
 	"github.com/aws/karpenter-core/pkg/controllers/disruption/orchestration"
@@ -78,7 +80,7 @@ var _ = BeforeSuite(func() {
 	nodeStateController = informer.NewNodeController(env.Client, cluster)
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cluster)
 	recorder = test.NewEventRecorder()
-	prov = provisioning.NewProvisioner(env.Client, env.KubernetesInterface.CoreV1(), recorder, cloudProvider, cluster)
+	prov = provisioning.NewProvisioner(env.Client, env.KubernetesInterface.CoreV1(), env.KubernetesInterface.NodeV1(), recorder, cloudProvider, cluster, cloudprovider.NewUnavailableOfferings())
 	queue = orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
 })
 
@@ -165,7 +167,7 @@ var _ = Describe("Queue", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
 
 			stateNode := ExpectStateNodeExists(cluster, node1)
-			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
+			Expect(queue.Add(ctx, orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
 
 			node1 = ExpectNodeExists(ctx, env.Client, node1.Name)
 			Expect(node1.Spec.Taints).To(ContainElement(v1beta1.DisruptionNoScheduleTaint))
@@ -183,7 +185,7 @@ var _ = Describe("Queue", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
 
-			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
+			Expect(queue.Add(ctx, orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
 			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
 		})
 		It("should untaint nodes when a command times out", func() {
@@ -191,7 +193,7 @@ var _ = Describe("Queue", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
 
-			Expect(queue.Add(orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
+			Expect(queue.Add(ctx, orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
 
 			// Step the clock to trigger the timeout.
 			fakeClock.Step(11 * time.Minute)
@@ -206,7 +208,7 @@ var _ = Describe("Queue", func() {
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
 
 			cmd := orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type")
-			Expect(queue.Add(cmd)).To(BeNil())
+			Expect(queue.Add(ctx, cmd)).To(BeNil())
 			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
 
 			// Get the command
@@ -243,7 +245,7 @@ var _ = Describe("Queue", func() {
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
 
 			cmd := orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type")
-			Expect(queue.Add(cmd)).To(BeNil())
+			Expect(queue.Add(ctx, cmd)).To(BeNil())
 
 			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
 			Expect(cmd.Replacements[0].Initialized).To(BeFalse())
@@ -272,7 +274,7 @@ var _ = Describe("Queue", func() {
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
 			cmd := orchestration.NewCommand([]string{}, []*state.StateNode{stateNode}, "test-method", "fake-type")
-			Expect(queue.Add(cmd)).To(BeNil())
+			Expect(queue.Add(ctx, cmd)).To(BeNil())
 
 			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
 
@@ -299,9 +301,9 @@ var _ = Describe("Queue", func() {
 			stateNode2 := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim2)
 
 			cmd := orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type")
-			Expect(queue.Add(cmd)).To(BeNil())
+			Expect(queue.Add(ctx, cmd)).To(BeNil())
 			cmd2 := orchestration.NewCommand(replacements2, []*state.StateNode{stateNode2}, "test-method", "fake-type")
-			Expect(queue.Add(cmd2)).To(BeNil())
+			Expect(queue.Add(ctx, cmd2)).To(BeNil())
 
 			// Reconcile the first command and expect nothing to be initialized
 			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
@@ -341,4 +343,180 @@ var _ = Describe("Queue", func() {
 		})
 
 	})
+	Context("Persistence", func() {
+		It("should persist commands on their candidates", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, replacementNodeClaim, replacementNode)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			Expect(queue.Add(ctx, orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
+
+			nodeClaim1 = ExpectExists(ctx, env.Client, nodeClaim1)
+			Expect(nodeClaim1.Annotations).To(HaveKey(v1beta1.DisruptionCommandAnnotationKey))
+			Expect(nodeClaim1.Annotations[v1beta1.DisruptionCommandAnnotationKey]).To(ContainSubstring(replacementNodeClaim.Name))
+			Expect(nodeClaim1.Annotations[v1beta1.DisruptionCommandAnnotationKey]).To(ContainSubstring("test-method"))
+		})
+		It("should remove the persisted command when a command fails", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			Expect(queue.Add(ctx, orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
+
+			// Step the clock to trigger the timeout.
+			fakeClock.Step(11 * time.Minute)
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			nodeClaim1 = ExpectExists(ctx, env.Client, nodeClaim1)
+			Expect(nodeClaim1.Annotations).ToNot(HaveKey(v1beta1.DisruptionCommandAnnotationKey))
+		})
+		It("should resume a persisted command after a restart", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, replacementNodeClaim, replacementNode)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			Expect(queue.Add(ctx, orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
+
+			// Simulate a restart by rebuilding a new queue from the persisted commands
+			restoredQueue := orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
+			Expect(restoredQueue.Restore(ctx)).To(Succeed())
+			Expect(restoredQueue.HasAny(stateNode.ProviderID())).To(BeTrue())
+			Expect(ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1).MarkedForDeletion()).To(BeTrue())
+
+			// The candidate shouldn't be deleted until the replacement is initialized
+			ExpectReconcileSucceeded(ctx, restoredQueue, types.NamespacedName{})
+			ExpectExists(ctx, env.Client, nodeClaim1)
+			node1 = ExpectNodeExists(ctx, env.Client, node1.Name)
+			Expect(node1.Spec.Taints).To(ContainElement(v1beta1.DisruptionNoScheduleTaint))
+
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController,
+				[]*v1.Node{replacementNode}, []*v1beta1.NodeClaim{replacementNodeClaim})
+			ExpectReconcileSucceeded(ctx, restoredQueue, types.NamespacedName{})
+
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim1)
+			ExpectNotFound(ctx, env.Client, nodeClaim1, node1)
+		})
+		It("should time out a resumed command from when it was originally added", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			Expect(queue.Add(ctx, orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
+
+			// Simulate a restart that happens after the command should have timed out
+			fakeClock.Step(11 * time.Minute)
+			restoredQueue := orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
+			Expect(restoredQueue.Restore(ctx)).To(Succeed())
+			ExpectReconcileSucceeded(ctx, restoredQueue, types.NamespacedName{})
+
+			Expect(restoredQueue.HasAny(stateNode.ProviderID())).To(BeFalse())
+			nodeClaim1 = ExpectExists(ctx, env.Client, nodeClaim1)
+			Expect(nodeClaim1.Annotations).ToNot(HaveKey(v1beta1.DisruptionCommandAnnotationKey))
+			node1 = ExpectNodeExists(ctx, env.Client, node1.Name)
+			Expect(node1.Spec.Taints).ToNot(ContainElement(v1beta1.DisruptionNoScheduleTaint))
+		})
+		It("should drop a persisted command that can't be read", func() {
+			nodeClaim1.Annotations = lo.Assign(nodeClaim1.Annotations, map[string]string{v1beta1.DisruptionCommandAnnotationKey: "not-a-command"})
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			restoredQueue := orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
+			Expect(restoredQueue.Restore(ctx)).To(Succeed())
+
+			Expect(restoredQueue.HasAny(stateNode.ProviderID())).To(BeFalse())
+			nodeClaim1 = ExpectExists(ctx, env.Client, nodeClaim1)
+			Expect(nodeClaim1.Annotations).ToNot(HaveKey(v1beta1.DisruptionCommandAnnotationKey))
+			node1 = ExpectNodeExists(ctx, env.Client, node1.Name)
+			Expect(node1.Spec.Taints).ToNot(ContainElement(v1beta1.DisruptionNoScheduleTaint))
+		})
+		It("should drop a persisted command that can't be read from NodeClaims that aren't tracked in cluster state", func() {
+			nodeClaim1.Annotations = lo.Assign(nodeClaim1.Annotations, map[string]string{v1beta1.DisruptionCommandAnnotationKey: "not-a-command"})
+			ExpectApplied(ctx, env.Client, nodeClaim1, nodePool)
+
+			restoredQueue := orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
+			Expect(restoredQueue.Restore(ctx)).To(Succeed())
+			Expect(restoredQueue.Restored()).To(BeTrue())
+
+			nodeClaim1 = ExpectExists(ctx, env.Client, nodeClaim1)
+			Expect(nodeClaim1.Annotations).ToNot(HaveKey(v1beta1.DisruptionCommandAnnotationKey))
+		})
+		It("should clear a persisted command whose candidates aren't tracked in cluster state", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+			Expect(queue.Add(ctx, orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
+
+			// The candidate is removed from cluster state, but its NodeClaim still carries the command
+			ExpectDeleted(ctx, env.Client, node1)
+			ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node1))
+			cluster.DeleteNodeClaim(nodeClaim1.Name)
+
+			restoredQueue := orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
+			Expect(restoredQueue.Restore(ctx)).To(Succeed())
+			Expect(restoredQueue.Restored()).To(BeTrue())
+			Expect(restoredQueue.HasAny(stateNode.ProviderID())).To(BeFalse())
+
+			nodeClaim1 = ExpectExists(ctx, env.Client, nodeClaim1)
+			Expect(nodeClaim1.Annotations).ToNot(HaveKey(v1beta1.DisruptionCommandAnnotationKey))
+		})
+		It("should resume a persisted command whose candidate's node is already gone", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, replacementNodeClaim, replacementNode)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+			Expect(queue.Add(ctx, orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type"))).To(BeNil())
+
+			// The node is deleted before cluster state observes it, so it can't be tainted again
+			ExpectDeleted(ctx, env.Client, node1)
+
+			restoredQueue := orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
+			Expect(restoredQueue.Restore(ctx)).To(Succeed())
+			Expect(restoredQueue.Restored()).To(BeTrue())
+			Expect(restoredQueue.HasAny(stateNode.ProviderID())).To(BeTrue())
+		})
+	})
+	Context("Disruption Records", func() {
+		var record *v1beta1.DisruptionRecord
+
+		BeforeEach(func() {
+			record = test.DisruptionRecord()
+		})
+		It("should record each phase of a command that succeeds", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, replacementNodeClaim, replacementNode, record)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			Expect(queue.Add(ctx, orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type").WithRecord(record.Name))).To(BeNil())
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			// The command is waiting on its replacement, so it shouldn't have moved on to terminating the candidates
+			record = ExpectExists(ctx, env.Client, record)
+			Expect(record.Status.PhaseTransitions).To(BeEmpty())
+
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController,
+				[]*v1.Node{replacementNode}, []*v1beta1.NodeClaim{replacementNodeClaim})
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			record = ExpectExists(ctx, env.Client, record)
+			Expect(record.Status.Phase).To(Equal(v1beta1.DisruptionPhaseSucceeded))
+			Expect(lo.Map(record.Status.PhaseTransitions, func(t v1beta1.DisruptionPhaseTransition, _ int) v1beta1.DisruptionPhase {
+				return t.Phase
+			})).To(Equal([]v1beta1.DisruptionPhase{v1beta1.DisruptionPhaseTerminating, v1beta1.DisruptionPhaseSucceeded}))
+		})
+		It("should record the failure of a command that times out", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool, record)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node1}, []*v1beta1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			Expect(queue.Add(ctx, orchestration.NewCommand(replacements, []*state.StateNode{stateNode}, "test-method", "fake-type").WithRecord(record.Name))).To(BeNil())
+
+			// Step the clock to trigger the timeout.
+			fakeClock.Step(11 * time.Minute)
+			ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+
+			record = ExpectExists(ctx, env.Client, record)
+			Expect(record.Status.Phase).To(Equal(v1beta1.DisruptionPhaseFailed))
+			Expect(record.Status.Message).To(ContainSubstring("command reached timeout"))
+		})
+	})
 })
@@ -0,0 +1,97 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/logging"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+	pscheduling "github.com/aws/karpenter-core/pkg/controllers/provisioning/scheduling"
+	"github.com/aws/karpenter-core/pkg/utils/disruptionrecord"
+)
+
+// newDisruptionRecord returns a DisruptionRecord that describes the decision that was made by the command
+func newDisruptionRecord(m Method, cmd Command) *v1beta1.DisruptionRecord {
+	record := &v1beta1.DisruptionRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-", m.Type()),
+		},
+		Spec: v1beta1.DisruptionRecordSpec{
+			Method:            m.Type(),
+			ConsolidationType: m.ConsolidationType(),
+			Action:            string(cmd.Action()),
+			Candidates: lo.Map(cmd.candidates, func(c *Candidate, _ int) v1beta1.DisruptionCandidate {
+				dc := v1beta1.DisruptionCandidate{
+					Name:         c.NodeClaim.Name,
+					NodeName:     c.Node.Name,
+					NodePool:     c.nodePool.Name,
+					InstanceType: c.instanceType.Name,
+					CapacityType: c.capacityType,
+					Zone:         c.zone,
+				}
+				if offering, ok := c.instanceType.Offerings.Get(c.capacityType, c.zone); ok {
+					dc.Price = formatPrice(offering.Price)
+				}
+				return dc
+			}),
+			Replacements: lo.Map(cmd.replacements, func(r *pscheduling.NodeClaim, _ int) v1beta1.DisruptionReplacement {
+				dr := v1beta1.DisruptionReplacement{
+					NodePool: r.NodePoolName,
+					InstanceTypes: lo.Map(r.InstanceTypeOptions, func(it *cloudprovider.InstanceType, _ int) string {
+						return it.Name
+					}),
+				}
+				if price, err := replacementPrice(r); err == nil {
+					dr.Price = formatPrice(price)
+				}
+				return dr
+			}),
+		},
+	}
+	if savings, err := estimatedSavings(cmd); err == nil {
+		record.Spec.EstimatedSavings = formatPrice(savings)
+	}
+	return record
+}
+
+// createDisruptionRecord persists a DisruptionRecord for the command and returns its name. Records are best-effort,
+// so failing to create one doesn't stop the command from executing.
+func (c *Controller) createDisruptionRecord(ctx context.Context, m Method, cmd Command) string {
+	record := newDisruptionRecord(m, cmd)
+	if err := disruptionrecord.Create(ctx, c.kubeClient, record, v1beta1.DisruptionPhaseLaunching, c.clock.Now()); err != nil {
+		logging.FromContext(ctx).Errorf("creating disruption record, %s", err)
+	}
+	return record.Name
+}
+
+// transitionDisruptionRecord moves the command's DisruptionRecord into the passed phase
+func (c *Controller) transitionDisruptionRecord(ctx context.Context, name string, phase v1beta1.DisruptionPhase, opts ...disruptionrecord.TransitionOption) {
+	if name == "" {
+		return
+	}
+	if err := disruptionrecord.Transition(ctx, c.kubeClient, name, phase, c.clock.Now(), opts...); err != nil {
+		logging.FromContext(ctx).With("disruption-record", name).Errorf("transitioning disruption record to %s, %s", phase, err)
+	}
+}
+
+func formatPrice(price float64) string {
+	return fmt.Sprintf("%.4f", price)
+}
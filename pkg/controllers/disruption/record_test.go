@@ -0,0 +1,154 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/test"
+	. "github.com/aws/karpenter-core/pkg/test/expectations"
+)
+
+var _ = Describe("Disruption Records", func() {
+	var nodePool *v1beta1.NodePool
+	var nodeClaim *v1beta1.NodeClaim
+	var node *v1.Node
+
+	BeforeEach(func() {
+		nodePool = test.NodePool(v1beta1.NodePool{
+			Spec: v1beta1.NodePoolSpec{
+				Disruption: v1beta1.Disruption{
+					ConsolidateAfter: &v1beta1.NillableDuration{Duration: nil},
+					ExpireAfter:      v1beta1.NillableDuration{Duration: nil},
+				},
+			},
+		})
+		nodeClaim, node = test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey:     nodePool.Name,
+					v1.LabelInstanceTypeStable:   mostExpensiveInstance.Name,
+					v1beta1.CapacityTypeLabelKey: mostExpensiveOffering.CapacityType,
+					v1.LabelTopologyZone:         mostExpensiveOffering.Zone,
+				},
+			},
+			Status: v1beta1.NodeClaimStatus{
+				ProviderID: test.RandomProviderID(),
+				Allocatable: map[v1.ResourceName]resource.Quantity{
+					v1.ResourceCPU:  resource.MustParse("32"),
+					v1.ResourcePods: resource.MustParse("100"),
+				},
+			},
+		})
+		nodeClaim.StatusConditions().MarkTrue(v1beta1.Drifted)
+	})
+	It("should record a command that deletes its candidates", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+		fakeClock.Step(10 * time.Minute)
+		ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+
+		records := ExpectDisruptionRecords(ctx, env.Client)
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].Spec.Method).To(Equal("drift"))
+		Expect(records[0].Spec.Action).To(Equal("delete"))
+		Expect(records[0].Spec.Candidates).To(ConsistOf(v1beta1.DisruptionCandidate{
+			Name:         nodeClaim.Name,
+			NodeName:     node.Name,
+			NodePool:     nodePool.Name,
+			InstanceType: mostExpensiveInstance.Name,
+			CapacityType: mostExpensiveOffering.CapacityType,
+			Zone:         mostExpensiveOffering.Zone,
+			Price:        fmt.Sprintf("%.4f", mostExpensiveOffering.Price),
+		}))
+		Expect(records[0].Spec.Replacements).To(BeEmpty())
+		Expect(records[0].Spec.EstimatedSavings).To(Equal(fmt.Sprintf("%.4f", mostExpensiveOffering.Price)))
+		Expect(records[0].Status.Phase).To(Equal(v1beta1.DisruptionPhaseQueued))
+
+		// Process the item so that the nodes can be deleted.
+		ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+		// Cascade any deletion of the nodeClaim to the node
+		ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim, node)
+
+		record := ExpectExists(ctx, env.Client, records[0])
+		Expect(record.Status.Phase).To(Equal(v1beta1.DisruptionPhaseSucceeded))
+		Expect(lo.Map(record.Status.PhaseTransitions, func(t v1beta1.DisruptionPhaseTransition, _ int) v1beta1.DisruptionPhase {
+			return t.Phase
+		})).To(Equal([]v1beta1.DisruptionPhase{
+			v1beta1.DisruptionPhaseLaunching,
+			v1beta1.DisruptionPhaseQueued,
+			v1beta1.DisruptionPhaseTerminating,
+			v1beta1.DisruptionPhaseSucceeded,
+		}))
+	})
+	It("should record the replacements of a command that replaces its candidates", func() {
+		rs := test.ReplicaSet()
+		ExpectApplied(ctx, env.Client, rs)
+		Expect(env.Client.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion:         "apps/v1",
+						Kind:               "ReplicaSet",
+						Name:               rs.Name,
+						UID:                rs.UID,
+						Controller:         ptr.Bool(true),
+						BlockOwnerDeletion: ptr.Bool(true),
+					},
+				}}})
+		ExpectApplied(ctx, env.Client, rs, pod, nodeClaim, node, nodePool)
+
+		// bind the pods to the node
+		ExpectManualBinding(ctx, env.Client, pod, node)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node}, []*v1beta1.NodeClaim{nodeClaim})
+
+		fakeClock.Step(10 * time.Minute)
+		ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+
+		replacements := lo.Reject(ExpectNodeClaims(ctx, env.Client), func(nc *v1beta1.NodeClaim, _ int) bool {
+			return nc.Name == nodeClaim.Name
+		})
+		Expect(replacements).To(HaveLen(1))
+
+		records := ExpectDisruptionRecords(ctx, env.Client)
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].Spec.Action).To(Equal("replace"))
+		Expect(records[0].Spec.Replacements).To(HaveLen(1))
+		Expect(records[0].Spec.Replacements[0].NodePool).To(Equal(nodePool.Name))
+		Expect(records[0].Spec.Replacements[0].InstanceTypes).ToNot(BeEmpty())
+		Expect(records[0].Status.ReplacementNodeClaims).To(ConsistOf(replacements[0].Name))
+		Expect(records[0].Status.Phase).To(Equal(v1beta1.DisruptionPhaseQueued))
+	})
+})
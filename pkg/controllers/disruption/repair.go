@@ -0,0 +1,139 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	disruptionevents "github.com/aws/karpenter-core/pkg/controllers/disruption/events"
+	"github.com/aws/karpenter-core/pkg/controllers/provisioning"
+	"github.com/aws/karpenter-core/pkg/controllers/state"
+	"github.com/aws/karpenter-core/pkg/events"
+	"github.com/aws/karpenter-core/pkg/metrics"
+	"github.com/aws/karpenter-core/pkg/operator/options"
+)
+
+// Repair is a subreconciler that replaces candidates whose nodes have been unhealthy for longer than the toleration
+// duration of the unhealthy node condition.
+type Repair struct {
+	kubeClient  client.Client
+	cluster     *state.Cluster
+	provisioner *provisioning.Provisioner
+	recorder    events.Recorder
+	// blocked are the names of the candidates that are currently blocked by the repair limit
+	blocked sets.Set[string]
+}
+
+func NewRepair(kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder) *Repair {
+	return &Repair{
+		kubeClient:  kubeClient,
+		cluster:     cluster,
+		provisioner: provisioner,
+		recorder:    recorder,
+		blocked:     sets.New[string](),
+	}
+}
+
+// ShouldDisrupt is a predicate used to filter candidates
+func (r *Repair) ShouldDisrupt(ctx context.Context, c *Candidate) bool {
+	return options.FromContext(ctx).FeatureGates.NodeRepair &&
+		c.NodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy).IsTrue()
+}
+
+// filterAndSortCandidates orders unhealthy candidates by when they became unhealthy. Unlike the other methods, the
+// pods of unhealthy nodes are already disrupted, so neither PDBs nor pods with the do-not-disrupt annotation keep a
+// node from being repaired. Only the nodes that are already being deleted are filtered out.
+func (r *Repair) filterAndSortCandidates(_ context.Context, candidates []*Candidate) ([]*Candidate, error) {
+	candidates = lo.Filter(candidates, func(cn *Candidate, _ int) bool {
+		if !cn.Node.DeletionTimestamp.IsZero() {
+			r.recorder.Publish(disruptionevents.Blocked(cn.Node, cn.NodeClaim, "Node in the process of deletion")...)
+			return false
+		}
+		return true
+	})
+	sort.Slice(candidates, func(i int, j int) bool {
+		return candidates[i].NodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy).LastTransitionTime.Inner.Time.Before(
+			candidates[j].NodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy).LastTransitionTime.Inner.Time)
+	})
+	return candidates, nil
+}
+
+// ComputeCommand generates a disruption command given candidates
+func (r *Repair) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, error) {
+	candidates, err := r.filterAndSortCandidates(ctx, candidates)
+	if err != nil {
+		return Command{}, err
+	}
+	disruptionEligibleNodesGauge.With(map[string]string{
+		methodLabel:            r.Type(),
+		consolidationTypeLabel: r.ConsolidationType(),
+	}).Set(float64(len(candidates)))
+
+	// Only repair as many nodes as the cluster-wide repair limit allows. This stops us from replacing a large part of
+	// the cluster at once when nodes become unhealthy for a reason that replacing them won't fix, e.g. a network outage.
+	repairable := r.repairableNodes(ctx)
+	if repairable <= 0 {
+		// Only publish when a candidate becomes blocked, rather than on every pass while it stays blocked
+		blocked := sets.New[string]()
+		for _, candidate := range candidates {
+			blocked.Insert(candidate.NodeClaim.Name)
+			if !r.blocked.Has(candidate.NodeClaim.Name) {
+				r.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim,
+					fmt.Sprintf("Node repair is limited to %d%% of nodes", options.FromContext(ctx).NodeRepairMaxPercentage))...)
+			}
+		}
+		r.blocked = blocked
+		return Command{}, nil
+	}
+	r.blocked = sets.New[string]()
+
+	cmd, err := computeDisruptionCommand(ctx, r.kubeClient, r.cluster, r.provisioner, r.recorder, disruptionBudgetMapping, repairable, candidates)
+	if err != nil {
+		return Command{}, err
+	}
+	if len(cmd.candidates) == 1 && len(cmd.candidates[0].pods) > 0 {
+		logging.FromContext(ctx).With("reason", cmd.candidates[0].NodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy).Message).Infof("triggering replacement for unhealthy node")
+	}
+	return cmd, nil
+}
+
+// repairableNodes returns the number of nodes that can start being repaired without exceeding the cluster-wide repair
+// limit. Nodes that are already being disrupted while unhealthy count against the limit.
+func (r *Repair) repairableNodes(ctx context.Context) int {
+	// Only nodes that Karpenter manages can be repaired, so they're the only ones that count towards the limit
+	nodes := lo.Filter(r.cluster.Nodes(), func(n *state.StateNode, _ int) bool { return n.Managed() })
+	repairing := lo.CountBy(nodes, func(n *state.StateNode) bool {
+		return n.MarkedForDeletion() && n.NodeClaim != nil && n.NodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy).IsTrue()
+	})
+	limit := int(math.Ceil(float64(len(nodes)) * float64(options.FromContext(ctx).NodeRepairMaxPercentage) / 100))
+	return limit - repairing
+}
+
+func (r *Repair) Type() string {
+	return metrics.RepairReason
+}
+
+func (r *Repair) ConsolidationType() string {
+	return ""
+}
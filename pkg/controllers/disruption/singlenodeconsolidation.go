@@ -37,7 +37,7 @@ func NewSingleNodeConsolidation(consolidation consolidation) *SingleNodeConsolid
 
 // ComputeCommand generates a disruption command given candidates
 // nolint:gocyclo
-func (s *SingleNodeConsolidation) ComputeCommand(ctx context.Context, candidates ...*Candidate) (Command, error) {
+func (s *SingleNodeConsolidation) ComputeCommand(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) (Command, error) {
 	if s.isConsolidated() {
 		return Command{}, nil
 	}
@@ -54,6 +54,7 @@ func (s *SingleNodeConsolidation) ComputeCommand(ctx context.Context, candidates
 
 	// Set a timeout
 	timeout := s.clock.Now().Add(SingleNodeConsolidationTimeoutDuration)
+	constrainedByBudgets := false
 	// binary search to find the maximum number of NodeClaims we can terminate
 	for i, candidate := range candidates {
 		if s.clock.Now().After(timeout) {
@@ -61,6 +62,13 @@ func (s *SingleNodeConsolidation) ComputeCommand(ctx context.Context, candidates
 			logging.FromContext(ctx).Debugf("abandoning single-node consolidation due to timeout after evaluating %d candidates", i)
 			return Command{}, nil
 		}
+		// If the disruption budget doesn't allow this candidate to be disrupted,
+		// continue to the next candidate. We don't need to decrement any budget
+		// counter since single node consolidation commands can only have one candidate.
+		if disruptionBudgetMapping[candidate.nodePool.Name] == 0 {
+			constrainedByBudgets = true
+			continue
+		}
 		// compute a possible consolidation option
 		cmd, err := s.computeConsolidation(ctx, candidate)
 		if err != nil {
@@ -80,8 +88,11 @@ func (s *SingleNodeConsolidation) ComputeCommand(ctx context.Context, candidates
 		}
 		return cmd, nil
 	}
-	// couldn't remove any candidate
-	s.markConsolidated()
+	// couldn't remove any candidate. Only mark the cluster as consolidated if no candidate was skipped
+	// because of its disruption budget, since the candidate may be consolidatable once the budget allows it.
+	if !constrainedByBudgets {
+		s.markConsolidated()
+	}
 	return Command{}, nil
 }
 
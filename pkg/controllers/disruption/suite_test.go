@@ -59,6 +59,7 @@ var cluster *state.Cluster
 var disruptionController *disruption.Controller
 var prov *provisioning.Provisioner
 var cloudProvider *fake.CloudProvider
+var unavailableOfferings *cloudprovider.UnavailableOfferings
 var nodeStateController controller.Controller
 var nodeClaimStateController controller.Controller
 var fakeClock *clock.FakeClock
@@ -84,9 +85,10 @@ var _ = BeforeSuite(func() {
 	nodeStateController = informer.NewNodeController(env.Client, cluster)
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cluster)
 	recorder = test.NewEventRecorder()
-	prov = provisioning.NewProvisioner(env.Client, env.KubernetesInterface.CoreV1(), recorder, cloudProvider, cluster)
+	unavailableOfferings = cloudprovider.NewUnavailableOfferings()
+	prov = provisioning.NewProvisioner(env.Client, env.KubernetesInterface.CoreV1(), env.KubernetesInterface.NodeV1(), recorder, cloudProvider, cluster, unavailableOfferings)
 	queue = orchestration.NewTestingQueue(env.Client, recorder, cluster, fakeClock, prov)
-	disruptionController = disruption.NewController(fakeClock, env.Client, prov, cloudProvider, recorder, cluster, queue)
+	disruptionController = disruption.NewController(fakeClock, env.Client, prov, cloudProvider, recorder, cluster, queue, unavailableOfferings)
 })
 
 var _ = AfterSuite(func() {
@@ -96,6 +98,7 @@ var _ = AfterSuite(func() {
 var _ = BeforeEach(func() {
 	cloudProvider.Reset()
 	cloudProvider.InstanceTypes = fake.InstanceTypesAssorted()
+	unavailableOfferings.Flush()
 
 	recorder.Reset() // Reset the events that we captured during the run
 
@@ -214,7 +217,63 @@ var _ = Describe("Queue Limits", func() {
 		nodeClaim.StatusConditions().MarkTrue(v1beta1.Drifted)
 		nodeClaim2.StatusConditions().MarkTrue(v1beta1.Drifted)
 	})
-	It("should be able to disrupt two nodes with replace, but only ever be disrupting one at a time", func() {
+	It("should be able to disrupt two nodes with replace in parallel", func() {
+		labels := map[string]string{
+			"app": "test",
+		}
+		// create our RS so we can link a pod to it
+		rs := test.ReplicaSet()
+		ExpectApplied(ctx, env.Client, rs)
+		Expect(env.Client.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+
+		pods := test.Pods(2, test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse("2"),
+				},
+			},
+			ObjectMeta: metav1.ObjectMeta{Labels: labels,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion:         "apps/v1",
+						Kind:               "ReplicaSet",
+						Name:               rs.Name,
+						UID:                rs.UID,
+						Controller:         ptr.Bool(true),
+						BlockOwnerDeletion: ptr.Bool(true),
+					},
+				}}})
+
+		ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], nodeClaim, nodeClaim2, node, node2, nodePool)
+
+		// bind the pods to the nodes so that they're both non-empty
+		ExpectManualBinding(ctx, env.Client, pods[0], node)
+		ExpectManualBinding(ctx, env.Client, pods[1], node2)
+
+		// inform cluster state about nodes and nodeclaims
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*v1.Node{node, node2}, []*v1beta1.NodeClaim{nodeClaim, nodeClaim2})
+
+		// Do one reconcile to add one node to the queue
+		ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+
+		Expect(queue.Len()).To(BeNumerically("==", 1))
+		// Process the item but still expect it to be in the queue, since it's replacements aren't created
+		ExpectReconcileSucceeded(ctx, queue, types.NamespacedName{})
+		ExpectNodeExists(ctx, env.Client, node.Name)
+		ExpectNodeExists(ctx, env.Client, node2.Name)
+		Expect(queue.Len()).To(BeNumerically("==", 1))
+
+		// Do another reconcile to add the other node to the queue while the replacement of the first command is still
+		// launching. The pods of the in-flight command don't block the simulation of the other candidate.
+		ExpectReconcileSucceeded(ctx, disruptionController, types.NamespacedName{})
+		ExpectNodeExists(ctx, env.Client, node.Name)
+		ExpectNodeExists(ctx, env.Client, node2.Name)
+		Expect(queue.Len()).To(BeNumerically("==", 2))
+		Expect(queue.HasAny(nodeClaim.Status.ProviderID)).To(BeTrue())
+		Expect(queue.HasAny(nodeClaim2.Status.ProviderID)).To(BeTrue())
+	})
+	It("should be able to disrupt two nodes with replace, but only ever be disrupting one at a time when limited to one concurrent disruption", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{MaxConcurrentDisruptions: lo.ToPtr(1), FeatureGates: test.FeatureGates{Drift: lo.ToPtr(true)}}))
 		labels := map[string]string{
 			"app": "test",
 		}
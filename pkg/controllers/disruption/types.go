@@ -35,7 +35,7 @@ import (
 
 type Method interface {
 	ShouldDisrupt(context.Context, *Candidate) bool
-	ComputeCommand(context.Context, ...*Candidate) (Command, error)
+	ComputeCommand(context.Context, map[string]int, ...*Candidate) (Command, error)
 	Type() string
 	ConsolidationType() string
 }
@@ -145,6 +145,8 @@ func (c *Candidate) lifetimeRemaining(clock clock.Clock) float64 {
 type Command struct {
 	candidates   []*Candidate
 	replacements []*scheduling.NodeClaim
+	// spotToSpot is true if the command replaces spot candidates with a spot replacement
+	spotToSpot bool
 }
 
 type Action string
@@ -92,6 +92,18 @@ func (v *Validation) IsValid(ctx context.Context, cmd Command) (bool, error) {
 	if len(validationCandidates) != len(cmd.candidates) {
 		return false, nil
 	}
+	// Rebuild the disruption budgets to ensure that the command still fits within them, since other
+	// nodes may have started disrupting during the validation period
+	disruptionBudgetMapping, err := BuildDisruptionBudgets(ctx, v.cluster, v.clock, v.kubeClient, v.recorder)
+	if err != nil {
+		return false, fmt.Errorf("building disruption budgets, %w", err)
+	}
+	for _, vc := range validationCandidates {
+		if disruptionBudgetMapping[vc.nodePool.Name] == 0 {
+			return false, nil
+		}
+		disruptionBudgetMapping[vc.nodePool.Name]--
+	}
 	// a candidate we are about to delete is a target of a currently pending pod, wait for that to settle
 	// before continuing consolidation
 	for _, n := range validationCandidates {
@@ -128,6 +140,14 @@ func (v *Validation) ValidateCommand(ctx context.Context, cmd Command, candidate
 	if !results.AllNonPendingPodsScheduled() {
 		return false, nil
 	}
+	// the headroom of the candidates' NodePools may have been taken up by pods since the command was computed
+	reduced, err := reducedHeadroom(ctx, v.kubeClient, v.cluster, v.provisioner, candidates...)
+	if err != nil {
+		return false, fmt.Errorf("simulating headroom, %w", err)
+	}
+	if len(reduced) > 0 {
+		return false, nil
+	}
 
 	// We want to ensure that the re-simulated scheduling using the current cluster state produces the same result.
 	// There are three possible options for the number of new candidates that we need to handle:
@@ -0,0 +1,83 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package garbagecollection
+
+import (
+	"context"
+	"time"
+
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	corecontroller "github.com/aws/karpenter-core/pkg/operator/controller"
+	"github.com/aws/karpenter-core/pkg/operator/options"
+	"github.com/aws/karpenter-core/pkg/utils/disruptionrecord"
+)
+
+// Controller deletes DisruptionRecords once the command they describe has been finished for longer than the configured
+// retention. Records of commands that are still being orchestrated are kept.
+type Controller struct {
+	clock      clock.Clock
+	kubeClient client.Client
+}
+
+func NewController(c clock.Clock, kubeClient client.Client) corecontroller.Controller {
+	return &Controller{
+		clock:      c,
+		kubeClient: kubeClient,
+	}
+}
+
+func (c *Controller) Name() string {
+	return "disruptionrecord.garbagecollection"
+}
+
+func (c *Controller) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	recordList, err := disruptionrecord.List(ctx, c.kubeClient)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	ttl := options.FromContext(ctx).DisruptionRecordTTL
+	records := lo.Filter(lo.ToSlicePtr(recordList.Items), func(r *v1beta1.DisruptionRecord, _ int) bool {
+		completionTime, ok := r.CompletionTime()
+		return r.DeletionTimestamp.IsZero() && ok && c.clock.Since(completionTime) > ttl
+	})
+
+	errs := make([]error, len(records))
+	workqueue.ParallelizeUntil(ctx, 20, len(records), func(i int) {
+		if err := c.kubeClient.Delete(ctx, records[i]); err != nil {
+			errs[i] = client.IgnoreNotFound(err)
+			return
+		}
+		logging.FromContext(ctx).
+			With("disruption-record", records[i].Name, "phase", records[i].Status.Phase, "ttl", ttl).
+			Debugf("garbage collecting expired disruption record")
+	})
+	if err = multierr.Combine(errs...); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: time.Minute * 5}, nil
+}
+
+func (c *Controller) Builder(_ context.Context, m manager.Manager) corecontroller.Builder {
+	return corecontroller.NewSingletonManagedBy(m)
+}
@@ -0,0 +1,152 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package garbagecollection_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "knative.dev/pkg/logging/testing"
+
+	"github.com/aws/karpenter-core/pkg/apis"
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	disruptionrecordgarbagecollection "github.com/aws/karpenter-core/pkg/controllers/disruptionrecord/garbagecollection"
+	"github.com/aws/karpenter-core/pkg/operator/controller"
+	"github.com/aws/karpenter-core/pkg/operator/options"
+	"github.com/aws/karpenter-core/pkg/operator/scheme"
+	"github.com/aws/karpenter-core/pkg/test"
+
+	. "github.com/aws/karpenter-core/pkg/test/expectations"
+)
+
+var ctx context.Context
+var garbageCollectionController controller.Controller
+var env *test.Environment
+var fakeClock *clock.FakeClock
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DisruptionRecordGarbageCollection")
+}
+
+var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(scheme.Scheme, test.WithCRDs(apis.CRDs...))
+	garbageCollectionController = disruptionrecordgarbagecollection.NewController(fakeClock, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options())
+	fakeClock.SetTime(time.Now())
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = Describe("GarbageCollection", func() {
+	It("should delete DisruptionRecords that finished longer than the TTL ago", func() {
+		record := finishedDisruptionRecord(v1beta1.DisruptionPhaseSucceeded)
+		ExpectApplied(ctx, env.Client, record)
+
+		fakeClock.Step(7*24*time.Hour + time.Minute)
+		ExpectReconcileSucceeded(ctx, garbageCollectionController, client.ObjectKey{})
+		ExpectNotFound(ctx, env.Client, record)
+	})
+	It("should delete failed DisruptionRecords that finished longer than the TTL ago", func() {
+		record := finishedDisruptionRecord(v1beta1.DisruptionPhaseFailed)
+		ExpectApplied(ctx, env.Client, record)
+
+		fakeClock.Step(7*24*time.Hour + time.Minute)
+		ExpectReconcileSucceeded(ctx, garbageCollectionController, client.ObjectKey{})
+		ExpectNotFound(ctx, env.Client, record)
+	})
+	It("should not delete DisruptionRecords that finished less than the TTL ago", func() {
+		record := finishedDisruptionRecord(v1beta1.DisruptionPhaseSucceeded)
+		ExpectApplied(ctx, env.Client, record)
+
+		fakeClock.Step(6 * 24 * time.Hour)
+		ExpectReconcileSucceeded(ctx, garbageCollectionController, client.ObjectKey{})
+		ExpectExists(ctx, env.Client, record)
+	})
+	It("should measure the TTL from the completion time rather than the creation time", func() {
+		record := test.DisruptionRecord()
+		ExpectApplied(ctx, env.Client, record)
+
+		// the command takes most of the TTL to finish
+		fakeClock.Step(6 * 24 * time.Hour)
+		record.Status = v1beta1.DisruptionRecordStatus{
+			Phase:            v1beta1.DisruptionPhaseSucceeded,
+			PhaseTransitions: []v1beta1.DisruptionPhaseTransition{{Phase: v1beta1.DisruptionPhaseSucceeded, Time: metav1.NewTime(fakeClock.Now())}},
+		}
+		ExpectApplied(ctx, env.Client, record)
+
+		fakeClock.Step(2 * 24 * time.Hour)
+		ExpectReconcileSucceeded(ctx, garbageCollectionController, client.ObjectKey{})
+		ExpectExists(ctx, env.Client, record)
+
+		fakeClock.Step(6 * 24 * time.Hour)
+		ExpectReconcileSucceeded(ctx, garbageCollectionController, client.ObjectKey{})
+		ExpectNotFound(ctx, env.Client, record)
+	})
+	It("should not delete DisruptionRecords of commands that haven't finished", func() {
+		record := test.DisruptionRecord(v1beta1.DisruptionRecord{
+			Status: v1beta1.DisruptionRecordStatus{
+				Phase:            v1beta1.DisruptionPhaseQueued,
+				PhaseTransitions: []v1beta1.DisruptionPhaseTransition{{Phase: v1beta1.DisruptionPhaseQueued, Time: metav1.NewTime(fakeClock.Now())}},
+			},
+		})
+		ExpectApplied(ctx, env.Client, record)
+
+		fakeClock.Step(30 * 24 * time.Hour)
+		ExpectReconcileSucceeded(ctx, garbageCollectionController, client.ObjectKey{})
+		ExpectExists(ctx, env.Client, record)
+	})
+	It("should delete DisruptionRecords using the configured TTL", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionRecordTTL: lo.ToPtr(time.Hour)}))
+		records := []client.Object{
+			finishedDisruptionRecord(v1beta1.DisruptionPhaseSucceeded),
+			finishedDisruptionRecord(v1beta1.DisruptionPhaseSucceeded),
+			finishedDisruptionRecord(v1beta1.DisruptionPhaseFailed),
+		}
+		ExpectApplied(ctx, env.Client, records...)
+
+		fakeClock.Step(2 * time.Hour)
+		ExpectReconcileSucceeded(ctx, garbageCollectionController, client.ObjectKey{})
+		ExpectNotFound(ctx, env.Client, records...)
+	})
+})
+
+func finishedDisruptionRecord(phase v1beta1.DisruptionPhase) *v1beta1.DisruptionRecord {
+	return test.DisruptionRecord(v1beta1.DisruptionRecord{
+		Status: v1beta1.DisruptionRecordStatus{
+			Phase:            phase,
+			PhaseTransitions: []v1beta1.DisruptionPhaseTransition{{Phase: phase, Time: metav1.NewTime(fakeClock.Now())}},
+		},
+	})
+}
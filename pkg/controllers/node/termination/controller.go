@@ -23,6 +23,7 @@ import (
 	"golang.org/x/time/rate"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"knative.dev/pkg/logging"
 	controllerruntime "sigs.k8s.io/controller-runtime"
@@ -81,7 +82,11 @@ func (c *Controller) Finalize(ctx context.Context, node *v1.Node) (reconcile.Res
 	if err := c.terminator.Taint(ctx, node); err != nil {
 		return reconcile.Result{}, fmt.Errorf("tainting node, %w", err)
 	}
-	if err := c.terminator.Drain(ctx, node); err != nil {
+	nodeTerminationTime, err := c.nodeTerminationTime(ctx, node)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("getting node termination time, %w", err)
+	}
+	if err := c.terminator.Drain(ctx, node, nodeTerminationTime); err != nil {
 		if !terminator.IsNodeDrainError(err) {
 			return reconcile.Result{}, fmt.Errorf("draining node, %w", err)
 		}
@@ -117,6 +122,31 @@ func (c *Controller) deleteAllNodeClaims(ctx context.Context, node *v1.Node) err
 	return nil
 }
 
+// nodeTerminationTime returns the time by which the node must be terminated, based on the TerminationGracePeriod of
+// the NodePool that owns it. If the NodePool doesn't set a TerminationGracePeriod, nil is returned.
+func (c *Controller) nodeTerminationTime(ctx context.Context, node *v1.Node) (*time.Time, error) {
+	nodePoolName, ok := node.Labels[v1beta1.NodePoolLabelKey]
+	if !ok {
+		return nil, nil
+	}
+	nodeClaimList := &v1beta1.NodeClaimList{}
+	if err := c.kubeClient.List(ctx, nodeClaimList, client.MatchingFields{"status.providerID": node.Spec.ProviderID}); err != nil {
+		return nil, fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	if len(nodeClaimList.Items) == 0 {
+		return nil, nil
+	}
+	nodePool := &v1beta1.NodePool{}
+	if err := c.kubeClient.Get(ctx, types.NamespacedName{Name: nodePoolName}, nodePool); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	terminationTime, ok := nodeclaimutil.TerminationTime(nodePool, &nodeClaimList.Items[0], node)
+	if !ok {
+		return nil, nil
+	}
+	return &terminationTime, nil
+}
+
 func (c *Controller) removeFinalizer(ctx context.Context, n *v1.Node) error {
 	stored := n.DeepCopy()
 	controllerutil.RemoveFinalizer(n, v1beta1.TerminationFinalizer)
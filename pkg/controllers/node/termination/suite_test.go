@@ -332,6 +332,102 @@ var _ = Describe("Termination", func() {
 			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
 			ExpectNotFound(ctx, env.Client, node)
 		})
+		It("should delete pods that violate a PDB once the node termination time is approaching", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.Disruption.ExpireAfter.Duration = lo.ToPtr(time.Minute * 10)
+			nodePool.Spec.Disruption.TerminationGracePeriod = &metav1.Duration{Duration: time.Minute * 5}
+			node.Labels[v1beta1.NodePoolLabelKey] = nodePool.Name
+			nodeClaim.Labels = lo.Assign(nodeClaim.Labels, map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name})
+
+			minAvailable := intstr.FromInt(1)
+			labelSelector := map[string]string{test.RandomName(): test.RandomName()}
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels: labelSelector,
+				// Don't let any pod evict
+				MinAvailable: &minAvailable,
+			})
+			podNoEvict := test.Pod(test.PodOptions{
+				NodeName: node.Name,
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:          labelSelector,
+					OwnerReferences: defaultOwnerRefs,
+				},
+				Phase:                         v1.PodRunning,
+				TerminationGracePeriodSeconds: lo.ToPtr[int64](60),
+			})
+			fakeClock.SetTime(time.Now()) // make our fake clock match the nodeclaim creation time
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, podNoEvict, pdb)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectReconcileSucceeded(ctx, queue, client.ObjectKey{})
+
+			// Expect podNoEvict to fail eviction due to PDB, and not be deleted before its deadline
+			Eventually(func() int {
+				return queue.NumRequeues(client.ObjectKeyFromObject(podNoEvict))
+			}).Should(BeNumerically(">=", 1))
+			Expect(ExpectPodExists(ctx, env.Client, podNoEvict.Name, podNoEvict.Namespace).DeletionTimestamp.IsZero()).To(BeTrue())
+
+			// The node must be terminated 15 minutes after creation, so the pod must be deleted 60 seconds before that
+			fakeClock.SetTime(time.Now().Add(time.Minute * 14))
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectEvicted(env.Client, podNoEvict)
+			ExpectNodeWithNodeClaimDraining(env.Client, node.Name)
+		})
+		It("should only delete pods at their deletion deadline when draining an expired node", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.Disruption.ExpireAfter.Duration = lo.ToPtr(time.Minute * 10)
+			nodePool.Spec.Disruption.TerminationGracePeriod = &metav1.Duration{Duration: time.Minute * 5}
+			node.Labels[v1beta1.NodePoolLabelKey] = nodePool.Name
+			nodeClaim.Labels = lo.Assign(nodeClaim.Labels, map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name})
+
+			minAvailable := intstr.FromInt(1)
+			labelSelector := map[string]string{test.RandomName(): test.RandomName()}
+			pdb := test.PodDisruptionBudget(test.PDBOptions{
+				Labels: labelSelector,
+				// Don't let any pod evict
+				MinAvailable: &minAvailable,
+			})
+			podNoEvict := test.Pod(test.PodOptions{
+				NodeName: node.Name,
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:          labelSelector,
+					OwnerReferences: defaultOwnerRefs,
+				},
+				Phase:                         v1.PodRunning,
+				TerminationGracePeriodSeconds: lo.ToPtr[int64](120),
+			})
+			fakeClock.SetTime(time.Now()) // make our fake clock match the nodeclaim creation time
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, podNoEvict, pdb)
+
+			// The node is disrupted gracefully once it expires
+			fakeClock.Step(time.Minute * 10)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectReconcileSucceeded(ctx, queue, client.ObjectKey{})
+			Eventually(func() int {
+				return queue.NumRequeues(client.ObjectKeyFromObject(podNoEvict))
+			}).Should(BeNumerically(">=", 1))
+			Expect(ExpectPodExists(ctx, env.Client, podNoEvict.Name, podNoEvict.Namespace).DeletionTimestamp.IsZero()).To(BeTrue())
+
+			// The node must be terminated 15 minutes after creation, so the pod can still be evicted gracefully until
+			// 120 seconds before that
+			fakeClock.Step(time.Minute*2 + time.Second*58)
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			Expect(ExpectPodExists(ctx, env.Client, podNoEvict.Name, podNoEvict.Namespace).DeletionTimestamp.IsZero()).To(BeTrue())
+
+			// Once the deadline is reached, the pod is deleted regardless of its PDB
+			fakeClock.Step(time.Second * 3)
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectReconcileSucceeded(ctx, terminationController, client.ObjectKeyFromObject(node))
+			ExpectEvicted(env.Client, podNoEvict)
+			ExpectNodeWithNodeClaimDraining(env.Client, node.Name)
+		})
 		It("should evict pods in order", func() {
 			daemonEvict := test.DaemonSet()
 			daemonNodeCritical := test.DaemonSet(test.DaemonSetOptions{PodOptions: test.PodOptions{PriorityClassName: "system-node-critical"}})
@@ -22,6 +22,7 @@ import (
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/clock"
 	"knative.dev/pkg/logging"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -77,7 +78,9 @@ func (t *Terminator) Taint(ctx context.Context, node *v1.Node) error {
 
 // Drain evicts pods from the node and returns true when all pods are evicted
 // https://kubernetes.io/docs/concepts/architecture/nodes/#graceful-node-shutdown
-func (t *Terminator) Drain(ctx context.Context, node *v1.Node) error {
+// If nodeTerminationTime is set, pods are deleted instead of evicted once there is no longer enough time left for them
+// to terminate gracefully before the node is terminated, regardless of any PodDisruptionBudgets.
+func (t *Terminator) Drain(ctx context.Context, node *v1.Node, nodeTerminationTime *time.Time) error {
 	// Get evictable pods
 	pods := &v1.PodList{}
 	if err := t.kubeClient.List(ctx, pods, client.MatchingFields{"spec.nodeName": node.Name}); err != nil {
@@ -101,6 +104,10 @@ func (t *Terminator) Drain(ctx context.Context, node *v1.Node) error {
 		}
 		return p, true
 	})
+	// Delete pods that have passed their deletion deadline
+	if err := t.DeleteExpiringPods(ctx, podsToEvict, nodeTerminationTime); err != nil {
+		return fmt.Errorf("deleting expiring pods, %w", err)
+	}
 	// Enqueue for eviction
 	t.Evict(podsToEvict)
 
@@ -147,6 +154,39 @@ func (t *Terminator) Evict(pods []*v1.Pod) {
 	}
 }
 
+// DeleteExpiringPods deletes pods whose deletion deadline has passed. A pod's deletion deadline is the node termination
+// time minus the pod's terminationGracePeriodSeconds, so that the pod can still terminate gracefully before the node is terminated.
+func (t *Terminator) DeleteExpiringPods(ctx context.Context, pods []*v1.Pod, nodeTerminationTime *time.Time) error {
+	if nodeTerminationTime == nil {
+		return nil
+	}
+	for _, pod := range pods {
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if t.clock.Now().Before(podDeletionDeadline(pod, *nodeTerminationTime)) {
+			continue
+		}
+		if err := t.kubeClient.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		logging.FromContext(ctx).With("pod", client.ObjectKeyFromObject(pod), "termination-time", nodeTerminationTime.Format(time.RFC3339)).Infof("deleted pod, node termination time is approaching")
+		// Set a deletion timestamp so that we don't also enqueue the pod for eviction
+		pod.DeletionTimestamp = lo.ToPtr(metav1.NewTime(t.clock.Now()))
+	}
+	return nil
+}
+
+// podDeletionDeadline returns the latest time that a pod can be deleted while still being given its full
+// terminationGracePeriodSeconds before the node is terminated
+func podDeletionDeadline(pod *v1.Pod, nodeTerminationTime time.Time) time.Time {
+	gracePeriod := int64(v1.DefaultTerminationGracePeriodSeconds)
+	if pod.Spec.TerminationGracePeriodSeconds != nil {
+		gracePeriod = *pod.Spec.TerminationGracePeriodSeconds
+	}
+	return nodeTerminationTime.Add(-time.Duration(gracePeriod) * time.Second)
+}
+
 func (t *Terminator) isStuckTerminating(pod *v1.Pod) bool {
 	if pod.DeletionTimestamp == nil {
 		return false
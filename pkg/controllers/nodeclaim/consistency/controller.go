@@ -67,7 +67,7 @@ func NewController(clk clock.Clock, kubeClient client.Client, recorder events.Re
 		lastScanned: cache.New(scanPeriod, 1*time.Minute),
 		checks: []Check{
 			NewTermination(kubeClient),
-			NewNodeShape(provider),
+			NewNodeShape(kubeClient, provider),
 		},
 	}
 }
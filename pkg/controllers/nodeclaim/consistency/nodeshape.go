@@ -17,25 +17,38 @@ package consistency
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/patrickmn/go-cache"
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
 	"github.com/aws/karpenter-core/pkg/cloudprovider"
 )
 
-// NodeShape detects nodes that have launched with 10% or less of any resource than was expected.
+// NodeShape detects nodes that have launched with 10% or less of any resource than was expected. Allocatable is
+// expected to match the instance type with the kubelet configuration of the NodeClaim applied, as that's what the
+// scheduler assumed when it packed pods onto the node.
 type NodeShape struct {
-	provider cloudprovider.CloudProvider
+	kubeClient client.Client
+	provider   cloudprovider.CloudProvider
+	// instanceTypes caches the instance types of each NodePool for a scan period, so that they're retrieved once per
+	// NodePool rather than once per NodeClaim
+	instanceTypes *cache.Cache
 }
 
-func NewNodeShape(provider cloudprovider.CloudProvider) Check {
+func NewNodeShape(kubeClient client.Client, provider cloudprovider.CloudProvider) Check {
 	return &NodeShape{
-		provider: provider,
+		kubeClient:    kubeClient,
+		provider:      provider,
+		instanceTypes: cache.New(scanPeriod, time.Minute),
 	}
 }
 
-func (n *NodeShape) Check(_ context.Context, node *v1.Node, nodeClaim *v1beta1.NodeClaim) ([]Issue, error) {
+func (n *NodeShape) Check(ctx context.Context, node *v1.Node, nodeClaim *v1beta1.NodeClaim) ([]Issue, error) {
 	// ignore NodeClaims that are deleting
 	if !nodeClaim.DeletionTimestamp.IsZero() {
 		return nil, nil
@@ -57,5 +70,61 @@ func (n *NodeShape) Check(_ context.Context, node *v1.Node, nodeClaim *v1beta1.N
 				resourceName, nodeQuantity.String(), pct*100)))
 		}
 	}
+	expectedAllocatable, err := n.expectedAllocatable(ctx, nodeClaim)
+	if err != nil {
+		return nil, err
+	}
+	for resourceName, requested := range nodeClaim.Spec.Resources.Requests {
+		nodeQuantity, ok := node.Status.Allocatable[resourceName]
+		expectedQuantity := expectedAllocatable[resourceName]
+		// nodes that don't report a resource yet are covered by the capacity check
+		if !ok || requested.IsZero() || expectedQuantity.Sign() <= 0 {
+			continue
+		}
+		pct := nodeQuantity.AsApproximateFloat64() / expectedQuantity.AsApproximateFloat64()
+		if pct < 0.90 {
+			issues = append(issues, Issue(fmt.Sprintf("expected %s of allocatable resource %s, but found %s (%0.1f%% of expected)", expectedQuantity.String(),
+				resourceName, nodeQuantity.String(), pct*100)))
+		}
+	}
 	return issues, nil
 }
+
+// expectedAllocatable returns the allocatable of the instance type of the NodeClaim with its kubelet configuration
+// applied, or nil if the NodePool or instance type no longer exist
+func (n *NodeShape) expectedAllocatable(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (v1.ResourceList, error) {
+	nodePoolName, ok := nodeClaim.Labels[v1beta1.NodePoolLabelKey]
+	if !ok {
+		return nil, nil
+	}
+	nodePool := &v1beta1.NodePool{}
+	if err := n.kubeClient.Get(ctx, types.NamespacedName{Name: nodePoolName}, nodePool); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	instanceTypes, err := n.getInstanceTypes(ctx, nodePool)
+	if err != nil {
+		return nil, err
+	}
+	instanceType, ok := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool {
+		return it.Name == nodeClaim.Labels[v1.LabelInstanceTypeStable]
+	})
+	if !ok {
+		return nil, nil
+	}
+	return cloudprovider.ApplyKubeletConfiguration([]*cloudprovider.InstanceType{instanceType}, nodeClaim.Spec.Kubelet)[0].Allocatable(), nil
+}
+
+// getInstanceTypes returns the instance types of the NodePool. They're cached by the hash of the NodePool, so that
+// they're retrieved again as soon as the NodePool changes.
+func (n *NodeShape) getInstanceTypes(ctx context.Context, nodePool *v1beta1.NodePool) ([]*cloudprovider.InstanceType, error) {
+	key := fmt.Sprintf("%s/%s", nodePool.Name, nodePool.Hash())
+	if instanceTypes, ok := n.instanceTypes.Get(key); ok {
+		return instanceTypes.([]*cloudprovider.InstanceType), nil
+	}
+	instanceTypes, err := n.provider.GetInstanceTypes(ctx, nodePool)
+	if err != nil {
+		return nil, fmt.Errorf("getting instance types, %w", err)
+	}
+	n.instanceTypes.SetDefault(key, instanceTypes)
+	return instanceTypes, nil
+}
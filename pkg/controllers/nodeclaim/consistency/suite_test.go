@@ -22,6 +22,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -160,5 +161,101 @@ var _ = Describe("NodeClaimController", func() {
 			ExpectReconcileSucceeded(ctx, nodeClaimConsistencyController, client.ObjectKeyFromObject(nodeClaim))
 			Expect(recorder.DetectedEvent("expected 128Gi of resource memory, but found 64Gi (50.0% of expected)")).To(BeTrue())
 		})
+		It("should detect issues that launch with much less allocatable than the kubelet configuration allows", func() {
+			nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:        nodePool.Name,
+						v1.LabelInstanceTypeStable:      "default-instance-type",
+						v1beta1.NodeInitializedLabelKey: "true",
+					},
+				},
+				Spec: v1beta1.NodeClaimSpec{
+					Kubelet: &v1beta1.KubeletConfiguration{MaxPods: lo.ToPtr[int32](4)},
+					Resources: v1beta1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:  resource.MustParse("1"),
+							v1.ResourcePods: resource.MustParse("3"),
+						},
+					},
+				},
+				Status: v1beta1.NodeClaimStatus{
+					ProviderID: test.RandomProviderID(),
+				},
+			})
+			node.Status.Allocatable = v1.ResourceList{
+				v1.ResourceCPU:  resource.MustParse("3900m"),
+				v1.ResourcePods: resource.MustParse("2"),
+			}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectMakeNodeClaimsInitialized(ctx, env.Client, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimConsistencyController, client.ObjectKeyFromObject(nodeClaim))
+			Expect(recorder.DetectedEvent("expected 4 of allocatable resource pods, but found 2 (50.0% of expected)")).To(BeTrue())
+		})
+		It("should not detect issues for nodes that launch with the allocatable that the kubelet configuration allows", func() {
+			nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1beta1.NodePoolLabelKey:        nodePool.Name,
+						v1.LabelInstanceTypeStable:      "default-instance-type",
+						v1beta1.NodeInitializedLabelKey: "true",
+					},
+				},
+				Spec: v1beta1.NodeClaimSpec{
+					Kubelet: &v1beta1.KubeletConfiguration{MaxPods: lo.ToPtr[int32](4)},
+					Resources: v1beta1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:  resource.MustParse("1"),
+							v1.ResourcePods: resource.MustParse("3"),
+						},
+					},
+				},
+				Status: v1beta1.NodeClaimStatus{
+					ProviderID: test.RandomProviderID(),
+				},
+			})
+			node.Status.Allocatable = v1.ResourceList{
+				v1.ResourceCPU:  resource.MustParse("3900m"),
+				v1.ResourcePods: resource.MustParse("4"),
+			}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectMakeNodeClaimsInitialized(ctx, env.Client, nodeClaim)
+			ExpectReconcileSucceeded(ctx, nodeClaimConsistencyController, client.ObjectKeyFromObject(nodeClaim))
+			Expect(recorder.Events()).To(BeEmpty())
+		})
+		It("should only retrieve the instance types of a nodepool once for all of its nodeclaims", func() {
+			nodeClaims := make([]*v1beta1.NodeClaim, 2)
+			for i := range nodeClaims {
+				nodeClaim, node := test.NodeClaimAndNode(v1beta1.NodeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							v1beta1.NodePoolLabelKey:        nodePool.Name,
+							v1.LabelInstanceTypeStable:      "default-instance-type",
+							v1beta1.NodeInitializedLabelKey: "true",
+						},
+					},
+					Spec: v1beta1.NodeClaimSpec{
+						Kubelet: &v1beta1.KubeletConfiguration{MaxPods: lo.ToPtr[int32](4)},
+						Resources: v1beta1.ResourceRequirements{
+							Requests: v1.ResourceList{v1.ResourcePods: resource.MustParse("3")},
+						},
+					},
+					Status: v1beta1.NodeClaimStatus{
+						ProviderID: test.RandomProviderID(),
+					},
+				})
+				node.Status.Allocatable = v1.ResourceList{v1.ResourcePods: resource.MustParse("2")}
+				ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+				ExpectMakeNodeClaimsInitialized(ctx, env.Client, nodeClaim)
+				nodeClaims[i] = nodeClaim
+			}
+			ExpectReconcileSucceeded(ctx, nodeClaimConsistencyController, client.ObjectKeyFromObject(nodeClaims[0]))
+
+			// the second nodeclaim is checked against the instance types that were retrieved for the first one
+			cp.ErrorsForNodePool = map[string]error{nodePool.Name: fmt.Errorf("failed to get instance types")}
+			DeferCleanup(func() { cp.ErrorsForNodePool = nil })
+			ExpectReconcileSucceeded(ctx, nodeClaimConsistencyController, client.ObjectKeyFromObject(nodeClaims[1]))
+			Expect(recorder.Calls("FailedConsistencyCheck")).To(Equal(2))
+		})
 	})
 })
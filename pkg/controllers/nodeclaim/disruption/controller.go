@@ -52,6 +52,7 @@ type Controller struct {
 	drift      *Drift
 	expiration *Expiration
 	emptiness  *Emptiness
+	health     *Health
 }
 
 // NewController constructs a machine disruption controller
@@ -61,6 +62,7 @@ func NewController(clk clock.Clock, kubeClient client.Client, cluster *state.Clu
 		drift:      &Drift{cloudProvider: cloudProvider},
 		expiration: &Expiration{kubeClient: kubeClient, clock: clk},
 		emptiness:  &Emptiness{kubeClient: kubeClient, cluster: cluster, clock: clk},
+		health:     &Health{kubeClient: kubeClient, clock: clk},
 	}
 }
 
@@ -85,6 +87,7 @@ func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim
 		c.expiration,
 		c.drift,
 		c.emptiness,
+		c.health,
 	}
 	for _, reconciler := range reconcilers {
 		res, err := reconciler.Reconcile(ctx, nodePool, nodeClaim)
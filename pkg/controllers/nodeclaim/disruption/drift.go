@@ -65,12 +65,12 @@ func (d *Drift) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool, nodeC
 		}
 		return reconcile.Result{}, nil
 	}
-	driftedReason, err := d.isDrifted(ctx, nodePool, nodeClaim)
+	drift, err := d.isDrifted(ctx, nodePool, nodeClaim)
 	if err != nil {
 		return reconcile.Result{}, cloudprovider.IgnoreNodeClaimNotFoundError(fmt.Errorf("getting drift, %w", err))
 	}
 	// 3. Otherwise, if the NodeClaim isn't drifted, but has the status condition, remove it.
-	if driftedReason == "" {
+	if drift == nil {
 		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Drifted)
 		if hasDriftedCondition {
 			logging.FromContext(ctx).Debugf("removing drifted status condition, not drifted")
@@ -82,51 +82,61 @@ func (d *Drift) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool, nodeC
 		Type:     v1beta1.Drifted,
 		Status:   v1.ConditionTrue,
 		Severity: apis.ConditionSeverityWarning,
-		Reason:   string(driftedReason),
+		Reason:   string(drift.Reason),
+		Message:  drift.String(),
 	})
 	if !hasDriftedCondition {
-		logging.FromContext(ctx).Debugf("marking drifted")
+		logging.FromContext(ctx).With("reason", drift.Reason).Debugf("marking drifted, %s", drift)
 		nodeclaimutil.DisruptedCounter(nodeClaim, metrics.DriftReason).Inc()
-		nodeclaimutil.DriftedCounter(nodeClaim, string(driftedReason)).Inc()
+		nodeclaimutil.DriftedCounter(nodeClaim, drift).Inc()
 	}
 	// Requeue after 5 minutes for the cache TTL
 	return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
 }
 
 // isDrifted will check if a NodeClaim is drifted from the fields in the NodePool Spec and the CloudProvider
-func (d *Drift) isDrifted(ctx context.Context, nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) (cloudprovider.DriftReason, error) {
+func (d *Drift) isDrifted(ctx context.Context, nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) (*cloudprovider.Drift, error) {
 	// First check for static drift or node requirements have drifted to save on API calls.
-	if reason := lo.FindOrElse([]cloudprovider.DriftReason{areStaticFieldsDrifted(nodePool, nodeClaim), areRequirementsDrifted(nodePool, nodeClaim)}, "", func(i cloudprovider.DriftReason) bool {
-		return i != ""
-	}); reason != "" {
-		return reason, nil
+	if drift, ok := lo.Find([]*cloudprovider.Drift{areStaticFieldsDrifted(nodePool, nodeClaim), areRequirementsDrifted(nodePool, nodeClaim)}, func(d *cloudprovider.Drift) bool {
+		return d != nil
+	}); ok {
+		return drift, nil
 	}
-	driftedReason, err := d.cloudProvider.IsDrifted(ctx, nodeClaim)
+	drift, err := d.cloudProvider.IsDrifted(ctx, nodeClaim)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return driftedReason, nil
+	return drift, nil
 }
 
 // Eligible fields for static drift are described in the docs
 // https://karpenter.sh/docs/concepts/deprovisioning/#drift
-func areStaticFieldsDrifted(nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) cloudprovider.DriftReason {
+func areStaticFieldsDrifted(nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) *cloudprovider.Drift {
 	nodePoolHash, foundHashNodePool := nodePool.Annotations[v1beta1.NodePoolHashAnnotationKey]
 	nodeClaimHash, foundHashNodeClaim := nodeClaim.Annotations[v1beta1.NodePoolHashAnnotationKey]
-	if !foundHashNodePool || !foundHashNodeClaim {
-		return ""
+	if !foundHashNodePool || !foundHashNodeClaim || nodePoolHash == nodeClaimHash {
+		return nil
+	}
+	return &cloudprovider.Drift{
+		Reason:   NodePoolDrifted,
+		Message:  "NodePool template has changed",
+		Field:    v1beta1.NodePoolHashAnnotationKey,
+		OldValue: nodeClaimHash,
+		NewValue: nodePoolHash,
 	}
-	return lo.Ternary(nodePoolHash != nodeClaimHash, NodePoolDrifted, "")
 }
 
-func areRequirementsDrifted(nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) cloudprovider.DriftReason {
-	provisionerReq := scheduling.NewNodeSelectorRequirements(nodePool.Spec.Template.Spec.Requirements...)
+func areRequirementsDrifted(nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) *cloudprovider.Drift {
+	provisionerReq := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Spec.Template.Spec.Requirements...)
 	nodeClaimReq := scheduling.NewLabelRequirements(nodeClaim.Labels)
 
 	// Every provisioner requirement is compatible with the NodeClaim label set
-	if nodeClaimReq.Compatible(provisionerReq) != nil {
-		return RequirementsDrifted
+	if err := nodeClaimReq.Compatible(provisionerReq); err != nil {
+		return &cloudprovider.Drift{
+			Reason:  RequirementsDrifted,
+			Message: fmt.Sprintf("NodeClaim labels don't satisfy the NodePool requirements, %s", err),
+		}
 	}
 
-	return ""
+	return nil
 }
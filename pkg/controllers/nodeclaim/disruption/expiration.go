@@ -16,7 +16,6 @@ package disruption
 
 import (
 	"context"
-	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/utils/clock"
@@ -40,7 +39,7 @@ type Expiration struct {
 func (e *Expiration) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) (reconcile.Result, error) {
 	hasExpiredCondition := nodeClaim.StatusConditions().GetCondition(v1beta1.Expired) != nil
 
-	// From here there are three scenarios to handle:
+	// From here there are four scenarios to handle:
 	// 1. If ExpireAfter is not configured, remove the expired status condition
 	if nodePool.Spec.Disruption.ExpireAfter.Duration == nil {
 		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Expired)
@@ -53,16 +52,7 @@ func (e *Expiration) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool,
 	if nodeclaimutil.IgnoreNodeNotFoundError(nodeclaimutil.IgnoreDuplicateNodeError(err)) != nil {
 		return reconcile.Result{}, err
 	}
-	// We do the expiration check in this way since there is still a migration path for creating Machines from Nodes
-	// In this case, we need to make sure that we take the older of the two for expiration
-	// TODO @joinnis: This check that takes the minimum between the Node and Machine CreationTimestamps can be removed
-	// once machine migration is ripped out, which should happen when apis and Karpenter are promoted to v1
-	var expirationTime time.Time
-	if node == nil || nodeClaim.CreationTimestamp.Before(&node.CreationTimestamp) {
-		expirationTime = nodeClaim.CreationTimestamp.Add(*nodePool.Spec.Disruption.ExpireAfter.Duration)
-	} else {
-		expirationTime = node.CreationTimestamp.Add(*nodePool.Spec.Disruption.ExpireAfter.Duration)
-	}
+	expirationTime, _ := nodeclaimutil.ExpirationTime(nodePool, nodeClaim, node)
 	// 2. If the NodeClaim isn't expired, remove the status condition.
 	if e.clock.Now().Before(expirationTime) {
 		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Expired)
@@ -83,5 +73,24 @@ func (e *Expiration) Reconcile(ctx context.Context, nodePool *v1beta1.NodePool,
 		logging.FromContext(ctx).Debugf("marking expired")
 		nodeclaimutil.DisruptedCounter(nodeClaim, metrics.ExpirationReason).Inc()
 	}
+	// 4. If the NodeClaim has been expired for longer than the termination grace period of its NodePool, delete it directly.
+	// This bypasses disruption budgets, PodDisruptionBudgets and do-not-disrupt pods, which may otherwise block the
+	// graceful disruption of the NodeClaim forever.
+	terminationTime, ok := nodeclaimutil.TerminationTime(nodePool, nodeClaim, node)
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+	// Until the termination grace period has elapsed, the expired NodeClaim is only disrupted gracefully
+	if e.clock.Now().Before(terminationTime) {
+		return reconcile.Result{RequeueAfter: terminationTime.Sub(e.clock.Now())}, nil
+	}
+	if !nodeClaim.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	if err := nodeclaimutil.Delete(ctx, e.kubeClient, nodeClaim); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	logging.FromContext(ctx).With("ttl", nodePool.Spec.Disruption.ExpireAfter.String(), "termination-grace-period", nodePool.Spec.Disruption.TerminationGracePeriod.Duration).Infof("forcefully terminating expired nodeclaim after termination grace period")
+	nodeclaimutil.TerminatedCounter(nodeClaim, metrics.ExpirationReason).Inc()
 	return reconcile.Result{}, nil
 }
@@ -0,0 +1,119 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/metrics"
+	"github.com/aws/karpenter-core/pkg/operator/options"
+	nodeclaimutil "github.com/aws/karpenter-core/pkg/utils/nodeclaim"
+)
+
+// Health is a nodeclaim sub-controller that adds or removes status conditions on nodeclaims whose nodes have reported
+// an unhealthy node condition for longer than its toleration duration
+type Health struct {
+	kubeClient client.Client
+	clock      clock.Clock
+}
+
+//nolint:gocyclo
+func (h *Health) Reconcile(ctx context.Context, _ *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim) (reconcile.Result, error) {
+	hasUnhealthyCondition := nodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy) != nil
+
+	// From here there are four scenarios to handle:
+	// 1. If node repair is not enabled, remove the unhealthy status condition
+	if !options.FromContext(ctx).FeatureGates.NodeRepair {
+		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Unhealthy)
+		if hasUnhealthyCondition {
+			logging.FromContext(ctx).Debugf("removing unhealthy status condition, node repair has been disabled")
+		}
+		return reconcile.Result{}, nil
+	}
+	// 2. If the NodeClaim isn't initialized, remove the unhealthy status condition. Nodes that never become healthy
+	// enough to initialize are handled by the liveness controller.
+	if !nodeClaim.StatusConditions().GetCondition(v1beta1.Initialized).IsTrue() {
+		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Unhealthy)
+		if hasUnhealthyCondition {
+			logging.FromContext(ctx).Debugf("removing unhealthy status condition, isn't initialized")
+		}
+		return reconcile.Result{}, nil
+	}
+	node, err := nodeclaimutil.NodeForNodeClaim(ctx, h.kubeClient, nodeClaim)
+	if nodeclaimutil.IgnoreNodeNotFoundError(nodeclaimutil.IgnoreDuplicateNodeError(err)) != nil {
+		return reconcile.Result{}, err
+	}
+	if node == nil {
+		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Unhealthy)
+		return reconcile.Result{}, nil
+	}
+	unhealthyCondition, requeueAfter := h.unhealthyCondition(ctx, node)
+	// 3. If the node hasn't had an unhealthy condition for longer than its toleration, remove the status condition
+	// and requeue for when the earliest toleration would be exceeded
+	if unhealthyCondition == nil {
+		_ = nodeClaim.StatusConditions().ClearCondition(v1beta1.Unhealthy)
+		if hasUnhealthyCondition {
+			logging.FromContext(ctx).Debugf("removing unhealthy status condition, healthy")
+		}
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
+	}
+	// 4. Otherwise, if the node is unhealthy, add the status condition
+	nodeClaim.StatusConditions().SetCondition(apis.Condition{
+		Type:     v1beta1.Unhealthy,
+		Status:   v1.ConditionTrue,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   string(unhealthyCondition.Type),
+		Message: fmt.Sprintf("Node condition %s has been %s for longer than %s",
+			unhealthyCondition.Type, unhealthyCondition.Status, unhealthyCondition.TolerationDuration),
+	})
+	if !hasUnhealthyCondition {
+		logging.FromContext(ctx).With("condition", unhealthyCondition.Type, "status", unhealthyCondition.Status).Debugf("marking unhealthy")
+		nodeclaimutil.DisruptedCounter(nodeClaim, metrics.RepairReason).Inc()
+	}
+	return reconcile.Result{}, nil
+}
+
+// unhealthyCondition returns the first node repair condition that the node has had for longer than its toleration
+// duration. If there isn't one, it returns how long until the earliest toleration duration would be exceeded.
+func (h *Health) unhealthyCondition(ctx context.Context, node *v1.Node) (*options.NodeRepairCondition, time.Duration) {
+	var requeueAfter time.Duration
+	for _, repairCondition := range options.FromContext(ctx).NodeRepairConditions {
+		repairCondition := repairCondition
+		for _, condition := range node.Status.Conditions {
+			if condition.Type != repairCondition.Type || condition.Status != repairCondition.Status {
+				continue
+			}
+			// Use t.Sub(clock.Now()) instead of time.Until() to ensure we're using the injected clock.
+			remaining := condition.LastTransitionTime.Add(repairCondition.TolerationDuration).Sub(h.clock.Now())
+			if remaining <= 0 {
+				return &repairCondition, 0
+			}
+			if requeueAfter == 0 || remaining < requeueAfter {
+				requeueAfter = remaining
+			}
+		}
+	}
+	return nil, requeueAfter
+}
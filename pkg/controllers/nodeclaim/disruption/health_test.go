@@ -0,0 +1,136 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption_test
+
+import (
+	"time"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/operator/options"
+	"github.com/aws/karpenter-core/pkg/test"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/aws/karpenter-core/pkg/test/expectations"
+)
+
+var _ = Describe("Health", func() {
+	var nodePool *v1beta1.NodePool
+	var nodeClaim *v1beta1.NodeClaim
+	var node *v1.Node
+	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+			NodeRepairConditions: []options.NodeRepairCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionFalse, TolerationDuration: 10 * time.Minute},
+				{Type: "KernelDeadlock", Status: v1.ConditionTrue, TolerationDuration: time.Minute},
+			},
+			FeatureGates: test.FeatureGates{NodeRepair: lo.ToPtr(true)},
+		}))
+		nodePool = test.NodePool()
+		nodeClaim, node = test.NodeClaimAndNode(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+			},
+		})
+		nodeClaim.StatusConditions().MarkTrue(v1beta1.Initialized)
+		node.Status.Conditions = []v1.NodeCondition{
+			{Type: v1.NodeReady, Status: v1.ConditionFalse, LastTransitionTime: metav1.Time{Time: fakeClock.Now()}},
+		}
+	})
+
+	It("should mark NodeClaims as unhealthy once a node condition exceeds its toleration duration", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		fakeClock.Step(11 * time.Minute)
+		ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy).IsTrue()).To(BeTrue())
+		Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy).Reason).To(Equal(string(v1.NodeReady)))
+	})
+	It("should mark NodeClaims as unhealthy for problem conditions reported by other node agents", func() {
+		node.Status.Conditions = append(node.Status.Conditions, v1.NodeCondition{
+			Type: "KernelDeadlock", Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: fakeClock.Now()},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		fakeClock.Step(2 * time.Minute)
+		ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy).IsTrue()).To(BeTrue())
+		Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy).Reason).To(Equal("KernelDeadlock"))
+	})
+	It("should not mark NodeClaims as unhealthy before a node condition exceeds its toleration duration", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		fakeClock.Step(5 * time.Minute)
+		ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy)).To(BeNil())
+	})
+	It("should not mark NodeClaims as unhealthy for node conditions that aren't configured", func() {
+		node.Status.Conditions = []v1.NodeCondition{
+			{Type: v1.NodeReady, Status: v1.ConditionUnknown, LastTransitionTime: metav1.Time{Time: fakeClock.Now()}},
+		}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		fakeClock.Step(time.Hour)
+		ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy)).To(BeNil())
+	})
+	It("should not mark NodeClaims as unhealthy when they aren't initialized", func() {
+		nodeClaim.StatusConditions().MarkFalse(v1beta1.Initialized, "", "")
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		fakeClock.Step(11 * time.Minute)
+		ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy)).To(BeNil())
+	})
+	It("should remove the status condition from NodeClaims when the node becomes healthy", func() {
+		nodeClaim.StatusConditions().MarkTrue(v1beta1.Unhealthy)
+		node.Status.Conditions = []v1.NodeCondition{
+			{Type: v1.NodeReady, Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: fakeClock.Now()}},
+		}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy)).To(BeNil())
+	})
+	It("should remove the status condition from NodeClaims when node repair is disabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{NodeRepair: lo.ToPtr(false)}}))
+		nodeClaim.StatusConditions().MarkTrue(v1beta1.Unhealthy)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+		fakeClock.Step(11 * time.Minute)
+		ExpectReconcileSucceeded(ctx, nodeClaimDisruptionController, client.ObjectKeyFromObject(nodeClaim))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().GetCondition(v1beta1.Unhealthy)).To(BeNil())
+	})
+})
@@ -62,11 +62,17 @@ type Controller struct {
 	liveness       *Liveness
 }
 
-func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder) *Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder,
+	unavailableOfferings *cloudprovider.UnavailableOfferings) *Controller {
+	launch := &Launch{kubeClient: kubeClient, cloudProvider: cloudProvider, cache: cache.New(time.Minute, time.Second*10), recorder: recorder,
+		unavailableOfferings: unavailableOfferings}
+	if batchCreator, ok := cloudProvider.(cloudprovider.BatchCreator); ok {
+		launch.createBatcher = newCreateBatcher(batchCreator)
+	}
 	return &Controller{
 		kubeClient: kubeClient,
 
-		launch:         &Launch{kubeClient: kubeClient, cloudProvider: cloudProvider, cache: cache.New(time.Minute, time.Second*10), recorder: recorder},
+		launch:         launch,
 		registration:   &Registration{kubeClient: kubeClient},
 		initialization: &Initialization{kubeClient: kubeClient},
 		liveness:       &Liveness{clock: clk, kubeClient: kubeClient},
@@ -126,9 +132,10 @@ type NodeClaimController struct {
 	*Controller
 }
 
-func NewNodeClaimController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder) corecontroller.Controller {
+func NewNodeClaimController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder,
+	unavailableOfferings *cloudprovider.UnavailableOfferings) corecontroller.Controller {
 	return corecontroller.Typed[*v1beta1.NodeClaim](kubeClient, &NodeClaimController{
-		Controller: NewController(clk, kubeClient, cloudProvider, recorder),
+		Controller: NewController(clk, kubeClient, cloudProvider, recorder, unavailableOfferings),
 	})
 }
 
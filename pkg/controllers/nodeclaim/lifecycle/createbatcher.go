@@ -0,0 +1,159 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/hashstructure/v2"
+	"github.com/samber/lo"
+	"knative.dev/pkg/logging"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+)
+
+const (
+	// createBatchIdleDuration is the time that a batch waits for more NodeClaims before it's launched. NodeClaims
+	// for a single scheduling decision are created in parallel, so they arrive well within this window.
+	createBatchIdleDuration = 100 * time.Millisecond
+	// createBatchMaxDuration is the longest that a batch waits before it's launched, regardless of NodeClaims still
+	// arriving
+	createBatchMaxDuration = time.Second
+)
+
+type createResult struct {
+	nodeClaim *v1beta1.NodeClaim
+	err       error
+}
+
+type createRequest struct {
+	nodeClaim *v1beta1.NodeClaim
+	result    chan createResult
+}
+
+type createBatch struct {
+	requests []*createRequest
+	trigger  chan struct{}
+}
+
+// createBatcher groups concurrent launches of NodeClaims with the same NodeClassRef and requirements into a single
+// CreateBatch call and fans the results back out to the callers
+type createBatcher struct {
+	batchCreator cloudprovider.BatchCreator
+
+	mu      sync.Mutex
+	batches map[uint64]*createBatch
+}
+
+func newCreateBatcher(batchCreator cloudprovider.BatchCreator) *createBatcher {
+	return &createBatcher{
+		batchCreator: batchCreator,
+		batches:      map[uint64]*createBatch{},
+	}
+}
+
+// Create adds the NodeClaim to the batch of NodeClaims that it's compatible with and blocks until the batch is launched
+func (b *createBatcher) Create(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
+	key, err := batchKey(nodeClaim)
+	if err != nil {
+		return nil, fmt.Errorf("hashing nodeclaim, %w", err)
+	}
+	request := &createRequest{nodeClaim: nodeClaim, result: make(chan createResult, 1)}
+
+	b.mu.Lock()
+	batch, ok := b.batches[key]
+	if !ok {
+		batch = &createBatch{trigger: make(chan struct{}, 1)}
+		b.batches[key] = batch
+		// The batch outlives the reconcile that started it, since it launches the NodeClaims of other reconciles too
+		go b.run(context.WithoutCancel(ctx), key, batch)
+	}
+	batch.requests = append(batch.requests, request)
+	b.mu.Unlock()
+
+	// The trigger is idempotently armed. This statement never blocks
+	select {
+	case batch.trigger <- struct{}{}:
+	default:
+	}
+	// We always wait for the result, since the NodeClaim may be launched even if this reconcile no longer needs it
+	result := <-request.result
+	return result.nodeClaim, result.err
+}
+
+// run waits for the batching window to close and then launches the batch
+func (b *createBatcher) run(ctx context.Context, key uint64, batch *createBatch) {
+	timeout := time.NewTimer(createBatchMaxDuration)
+	defer timeout.Stop()
+	idle := time.NewTimer(createBatchIdleDuration)
+	defer idle.Stop()
+	for done := false; !done; {
+		select {
+		case <-batch.trigger:
+			// correct way to reset an active timer per docs
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(createBatchIdleDuration)
+		case <-timeout.C:
+			done = true
+		case <-idle.C:
+			done = true
+		}
+	}
+	// Close the batch so that NodeClaims that arrive from now on start a new one
+	b.mu.Lock()
+	delete(b.batches, key)
+	requests := batch.requests
+	b.mu.Unlock()
+
+	nodeClaims := lo.Map(requests, func(r *createRequest, _ int) *v1beta1.NodeClaim { return r.nodeClaim })
+	logging.FromContext(ctx).With("count", len(nodeClaims)).Debugf("launching batch of nodeclaims")
+	created, errs := b.batchCreator.CreateBatch(ctx, nodeClaims)
+	if len(created) != len(nodeClaims) || len(errs) != len(nodeClaims) {
+		err := fmt.Errorf("launching batch of nodeclaims, expected %d results but got %d nodeclaims and %d errors", len(nodeClaims), len(created), len(errs))
+		for _, r := range requests {
+			r.result <- createResult{err: err}
+		}
+		return
+	}
+	for i, r := range requests {
+		switch {
+		case errs[i] != nil:
+			r.result <- createResult{err: errs[i]}
+		case created[i] == nil:
+			r.result <- createResult{err: fmt.Errorf("launching batch of nodeclaims, no nodeclaim or error was returned for %s", r.nodeClaim.Name)}
+		default:
+			r.result <- createResult{nodeClaim: created[i]}
+		}
+	}
+}
+
+// batchKey returns a key that is equal for NodeClaims that can be launched in the same batch
+func batchKey(nodeClaim *v1beta1.NodeClaim) (uint64, error) {
+	return hashstructure.Hash(struct {
+		NodePool     string
+		NodeClassRef *v1beta1.NodeClassReference
+		Requirements []v1beta1.NodeSelectorRequirementWithMinValues
+	}{
+		NodePool:     nodeClaim.Labels[v1beta1.NodePoolLabelKey],
+		NodeClassRef: nodeClaim.Spec.NodeClassRef,
+		Requirements: nodeClaim.Spec.Requirements,
+	}, hashstructure.FormatV2, &hashstructure.HashOptions{SlicesAsSets: true})
+}
@@ -42,3 +42,63 @@ func NodeClassNotReadyEvent(nodeClaim *v1beta1.NodeClaim, err error) events.Even
 		DedupeValues:   []string{string(nodeClaim.UID)},
 	}
 }
+
+func QuotaExceededEvent(nodeClaim *v1beta1.NodeClaim, err error) events.Event {
+	return events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           v1.EventTypeWarning,
+		Reason:         "QuotaExceeded",
+		Message:        fmt.Sprintf("NodeClaim %s event: %s", nodeClaim.Name, truncateMessage(err.Error())),
+		DedupeValues:   []string{string(nodeClaim.UID)},
+	}
+}
+
+func ThrottledEvent(nodeClaim *v1beta1.NodeClaim, err error) events.Event {
+	return events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           v1.EventTypeWarning,
+		Reason:         "Throttled",
+		Message:        fmt.Sprintf("NodeClaim %s event: %s", nodeClaim.Name, truncateMessage(err.Error())),
+		DedupeValues:   []string{string(nodeClaim.UID)},
+	}
+}
+
+func InvalidConfigurationEvent(nodeClaim *v1beta1.NodeClaim, err error) events.Event {
+	return events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           v1.EventTypeWarning,
+		Reason:         "InvalidConfiguration",
+		Message:        fmt.Sprintf("NodeClaim %s event: %s", nodeClaim.Name, truncateMessage(err.Error())),
+		DedupeValues:   []string{string(nodeClaim.UID)},
+	}
+}
+
+func UnauthorizedEvent(nodeClaim *v1beta1.NodeClaim, err error) events.Event {
+	return events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           v1.EventTypeWarning,
+		Reason:         "Unauthorized",
+		Message:        fmt.Sprintf("NodeClaim %s event: %s", nodeClaim.Name, truncateMessage(err.Error())),
+		DedupeValues:   []string{string(nodeClaim.UID)},
+	}
+}
+
+func InvalidConfigurationNodePoolEvent(nodePool *v1beta1.NodePool, err error) events.Event {
+	return events.Event{
+		InvolvedObject: nodePool,
+		Type:           v1.EventTypeWarning,
+		Reason:         "InvalidConfiguration",
+		Message:        fmt.Sprintf("Failed to launch NodeClaims for NodePool %s: %s", nodePool.Name, truncateMessage(err.Error())),
+		DedupeValues:   []string{string(nodePool.UID)},
+	}
+}
+
+func UnauthorizedNodePoolEvent(nodePool *v1beta1.NodePool, err error) events.Event {
+	return events.Event{
+		InvolvedObject: nodePool,
+		Type:           v1.EventTypeWarning,
+		Reason:         "Unauthorized",
+		Message:        fmt.Sprintf("Failed to launch NodeClaims for NodePool %s: %s", nodePool.Name, truncateMessage(err.Error())),
+		DedupeValues:   []string{string(nodePool.UID)},
+	}
+}
@@ -16,11 +16,14 @@ package lifecycle
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/patrickmn/go-cache"
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
 	"knative.dev/pkg/logging"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -30,6 +33,7 @@ import (
 	"github.com/aws/karpenter-core/pkg/events"
 	"github.com/aws/karpenter-core/pkg/scheduling"
 	nodeclaimutil "github.com/aws/karpenter-core/pkg/utils/nodeclaim"
+	nodepoolutil "github.com/aws/karpenter-core/pkg/utils/nodepool"
 )
 
 type Launch struct {
@@ -37,6 +41,10 @@ type Launch struct {
 	cloudProvider cloudprovider.CloudProvider
 	cache         *cache.Cache // exists due to eventual consistency on the cache
 	recorder      events.Recorder
+	// createBatcher launches NodeClaims in batches, if the CloudProvider supports it
+	createBatcher *createBatcher
+
+	unavailableOfferings *cloudprovider.UnavailableOfferings
 }
 
 func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (reconcile.Result, error) {
@@ -57,9 +65,17 @@ func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (r
 	} else {
 		created, err = l.launchNodeClaim(ctx, nodeClaim)
 	}
-	// Either the Node launch failed or the Node was deleted due to InsufficientCapacity/NotFound
+	// Either the Node launch failed or the Node was deleted due to InsufficientCapacity/QuotaExceeded/InvalidConfiguration/Unauthorized
 	if err != nil || created == nil {
-		if cloudprovider.IsNodeClassNotReadyError(err) {
+		switch {
+		case cloudprovider.IsNodeClassNotReadyError(err):
+			return reconcile.Result{Requeue: true}, nil
+		case cloudprovider.IsThrottledError(err):
+			var throttledErr *cloudprovider.ThrottledError
+			if errors.As(err, &throttledErr) && throttledErr.RetryAfter > 0 {
+				return reconcile.Result{RequeueAfter: throttledErr.RetryAfter}, nil
+			}
+			// Requeue with the controller's exponential backoff
 			return reconcile.Result{Requeue: true}, nil
 		}
 		return reconcile.Result{}, err
@@ -73,21 +89,58 @@ func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (r
 }
 
 func (l *Launch) launchNodeClaim(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
-	created, err := l.cloudProvider.Create(ctx, nodeClaim)
+	created, err := l.create(ctx, nodeClaim)
 	if err != nil {
 		switch {
 		case cloudprovider.IsInsufficientCapacityError(err):
 			l.recorder.Publish(InsufficientCapacityErrorEvent(nodeClaim, err))
 			logging.FromContext(ctx).Error(err)
+			// Record the offerings that failed so that scheduling doesn't immediately pick the same offerings again
+			l.unavailableOfferings.MarkUnavailableForError(ctx, err)
 			if err = nodeclaimutil.Delete(ctx, l.kubeClient, nodeClaim); err != nil {
 				return nil, client.IgnoreNotFound(err)
 			}
 			nodeclaimutil.TerminatedCounter(nodeClaim, "insufficient_capacity").Inc()
 			return nil, nil
+		case cloudprovider.IsQuotaExceededError(err):
+			l.recorder.Publish(QuotaExceededEvent(nodeClaim, err))
+			logging.FromContext(ctx).Error(err)
+			// Record the offerings that exceed the quota so that scheduling picks offerings that can still be launched
+			l.unavailableOfferings.MarkUnavailableForError(ctx, err)
+			if err = nodeclaimutil.Delete(ctx, l.kubeClient, nodeClaim); err != nil {
+				return nil, client.IgnoreNotFound(err)
+			}
+			nodeclaimutil.TerminatedCounter(nodeClaim, "quota_exceeded").Inc()
+			return nil, nil
 		case cloudprovider.IsNodeClassNotReadyError(err):
 			l.recorder.Publish(NodeClassNotReadyEvent(nodeClaim, err))
 			nodeClaim.StatusConditions().MarkFalse(v1beta1.Launched, "LaunchFailed", truncateMessage(err.Error()))
 			return nil, fmt.Errorf("launching nodeclaim, %w", err)
+		case cloudprovider.IsThrottledError(err):
+			l.recorder.Publish(ThrottledEvent(nodeClaim, err))
+			logging.FromContext(ctx).Debugf("launching nodeclaim, %s", err)
+			nodeClaim.StatusConditions().MarkFalse(v1beta1.Launched, "Throttled", truncateMessage(err.Error()))
+			return nil, fmt.Errorf("launching nodeclaim, %w", err)
+		case cloudprovider.IsInvalidConfigurationError(err):
+			// The launch won't succeed until the configuration changes, so we surface the error on the NodePool and delete
+			// the NodeClaim rather than retrying the launch forever
+			l.markNodePoolNotLaunchable(ctx, nodeClaim, "InvalidConfiguration", err, InvalidConfigurationNodePoolEvent)
+			l.recorder.Publish(InvalidConfigurationEvent(nodeClaim, err))
+			logging.FromContext(ctx).Error(err)
+			if err = nodeclaimutil.Delete(ctx, l.kubeClient, nodeClaim); err != nil {
+				return nil, client.IgnoreNotFound(err)
+			}
+			nodeclaimutil.TerminatedCounter(nodeClaim, "invalid_configuration").Inc()
+			return nil, nil
+		case cloudprovider.IsUnauthorizedError(err):
+			l.markNodePoolNotLaunchable(ctx, nodeClaim, "Unauthorized", err, UnauthorizedNodePoolEvent)
+			l.recorder.Publish(UnauthorizedEvent(nodeClaim, err))
+			logging.FromContext(ctx).Error(err)
+			if err = nodeclaimutil.Delete(ctx, l.kubeClient, nodeClaim); err != nil {
+				return nil, client.IgnoreNotFound(err)
+			}
+			nodeclaimutil.TerminatedCounter(nodeClaim, "unauthorized").Inc()
+			return nil, nil
 		default:
 			nodeClaim.StatusConditions().MarkFalse(v1beta1.Launched, "LaunchFailed", truncateMessage(err.Error()))
 			return nil, fmt.Errorf("launching nodeclaim, %w", err)
@@ -99,15 +152,67 @@ func (l *Launch) launchNodeClaim(ctx context.Context, nodeClaim *v1beta1.NodeCla
 		"zone", created.Labels[v1.LabelTopologyZone],
 		"capacity-type", created.Labels[v1beta1.CapacityTypeLabelKey],
 		"allocatable", created.Status.Allocatable).Infof("launched nodeclaim")
+	l.markNodePoolLaunchable(ctx, nodeClaim)
 	return created, nil
 }
 
+func (l *Launch) create(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodeClaim, error) {
+	if l.createBatcher != nil {
+		return l.createBatcher.Create(ctx, nodeClaim)
+	}
+	return l.cloudProvider.Create(ctx, nodeClaim)
+}
+
+// markNodePoolNotLaunchable surfaces an error that affects every NodeClaim of a NodePool on the NodePool's status and
+// events, so that it's visible after the NodeClaim is deleted. The provisioner doesn't launch NodeClaims for the NodePool
+// while the condition is false, so the condition is set anew on each rejected launch to restart its retry period.
+func (l *Launch) markNodePoolNotLaunchable(ctx context.Context, nodeClaim *v1beta1.NodeClaim, reason string, err error, event func(*v1beta1.NodePool, error) events.Event) {
+	nodePool, ok := l.getNodePool(ctx, nodeClaim)
+	if !ok {
+		return
+	}
+	l.recorder.Publish(event(nodePool, err))
+	stored := nodePool.DeepCopy()
+	// drop the previous condition so that it gets a new transition time
+	nodePool.SetConditions(lo.Reject(nodePool.GetConditions(), func(c apis.Condition, _ int) bool { return c.Type == v1beta1.Launchable }))
+	nodePool.StatusConditions().MarkFalse(v1beta1.Launchable, reason, truncateMessage(err.Error()))
+	if e := nodepoolutil.PatchStatus(ctx, l.kubeClient, stored, nodePool); e != nil {
+		logging.FromContext(ctx).Errorf("patching nodepool status, %s", e)
+	}
+}
+
+// markNodePoolLaunchable clears a previous launch error from the NodePool's status once one of its NodeClaims launches
+func (l *Launch) markNodePoolLaunchable(ctx context.Context, nodeClaim *v1beta1.NodeClaim) {
+	nodePool, ok := l.getNodePool(ctx, nodeClaim)
+	if !ok || !nodePool.StatusConditions().GetCondition(v1beta1.Launchable).IsFalse() {
+		return
+	}
+	stored := nodePool.DeepCopy()
+	nodePool.StatusConditions().MarkTrue(v1beta1.Launchable)
+	if e := nodepoolutil.PatchStatus(ctx, l.kubeClient, stored, nodePool); e != nil {
+		logging.FromContext(ctx).Errorf("patching nodepool status, %s", e)
+	}
+}
+
+func (l *Launch) getNodePool(ctx context.Context, nodeClaim *v1beta1.NodeClaim) (*v1beta1.NodePool, bool) {
+	name, ok := nodeClaim.Labels[v1beta1.NodePoolLabelKey]
+	if !ok {
+		return nil, false
+	}
+	nodePool := &v1beta1.NodePool{}
+	if err := l.kubeClient.Get(ctx, types.NamespacedName{Name: name}, nodePool); err != nil {
+		logging.FromContext(ctx).Debugf("getting nodepool, %s", err)
+		return nil, false
+	}
+	return nodePool, true
+}
+
 func PopulateNodeClaimDetails(nodeClaim, retrieved *v1beta1.NodeClaim) *v1beta1.NodeClaim {
 	// These are ordered in priority order so that user-defined nodeClaim labels and requirements trump retrieved labels
 	// or the static nodeClaim labels
 	nodeClaim.Labels = lo.Assign(
 		retrieved.Labels, // CloudProvider-resolved labels
-		scheduling.NewNodeSelectorRequirements(nodeClaim.Spec.Requirements...).Labels(), // Single-value requirement resolved labels
+		scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaim.Spec.Requirements...).Labels(), // Single-value requirement resolved labels
 		nodeClaim.Labels, // User-defined labels
 	)
 	nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, retrieved.Annotations)
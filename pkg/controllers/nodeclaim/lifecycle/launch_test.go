@@ -15,15 +15,22 @@ limitations under the License.
 package lifecycle_test
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/aws/karpenter-core/pkg/apis/v1alpha5"
 	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
 	"github.com/aws/karpenter-core/pkg/cloudprovider"
+	"github.com/aws/karpenter-core/pkg/cloudprovider/fake"
+	nodeclaimlifecycle "github.com/aws/karpenter-core/pkg/controllers/nodeclaim/lifecycle"
+	"github.com/aws/karpenter-core/pkg/operator/controller"
 	"github.com/aws/karpenter-core/pkg/test"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -77,6 +84,22 @@ var _ = Describe("Launch", func() {
 		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
 		ExpectNotFound(ctx, env.Client, nodeClaim)
 	})
+	It("should mark the offerings as unavailable if InsufficientCapacity is returned from the cloudprovider", func() {
+		cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(fmt.Errorf("all instance types were unavailable"),
+			cloudprovider.UnavailableOffering{InstanceType: "default-instance-type", Zone: "test-zone-1", CapacityType: v1beta1.CapacityTypeSpot},
+			cloudprovider.UnavailableOffering{InstanceType: "default-instance-type", Zone: "test-zone-2", CapacityType: v1beta1.CapacityTypeSpot},
+		)
+		nodeClaim := test.NodeClaim()
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+
+		Expect(unavailableOfferings.IsUnavailable("default-instance-type", "test-zone-1", v1beta1.CapacityTypeSpot)).To(BeTrue())
+		Expect(unavailableOfferings.IsUnavailable("default-instance-type", "test-zone-2", v1beta1.CapacityTypeSpot)).To(BeTrue())
+		Expect(unavailableOfferings.IsUnavailable("default-instance-type", "test-zone-3", v1beta1.CapacityTypeSpot)).To(BeFalse())
+		Expect(unavailableOfferings.IsUnavailable("default-instance-type", "test-zone-1", v1beta1.CapacityTypeOnDemand)).To(BeFalse())
+	})
 	It("should requeue with no error if NodeClassNotReady is returned from the cloudprovider", func() {
 		cloudProvider.NextCreateErr = cloudprovider.NewNodeClassNotReadyError(fmt.Errorf("nodeClass isn't ready"))
 		nodeClaim := test.NodeClaim()
@@ -87,4 +110,210 @@ var _ = Describe("Launch", func() {
 		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
 		Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Launched).Status).To(Equal(v1.ConditionFalse))
 	})
+	It("should delete the nodeclaim and mark the offerings as unavailable if QuotaExceeded is returned from the cloudprovider", func() {
+		cloudProvider.NextCreateErr = cloudprovider.NewQuotaExceededError(fmt.Errorf("vcpu limit reached"),
+			cloudprovider.UnavailableOffering{InstanceType: "default-instance-type", Zone: "test-zone-1", CapacityType: v1beta1.CapacityTypeOnDemand},
+		)
+		nodeClaim := test.NodeClaim()
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+
+		Expect(unavailableOfferings.IsUnavailable("default-instance-type", "test-zone-1", v1beta1.CapacityTypeOnDemand)).To(BeTrue())
+		Expect(unavailableOfferings.IsUnavailable("default-instance-type", "test-zone-2", v1beta1.CapacityTypeOnDemand)).To(BeFalse())
+		Expect(recorder.Calls("QuotaExceeded")).To(Equal(1))
+	})
+	It("should requeue after the retry delay if Throttled is returned from the cloudprovider", func() {
+		cloudProvider.NextCreateErr = cloudprovider.NewThrottledError(fmt.Errorf("rate exceeded"), 10*time.Second)
+		nodeClaim := test.NodeClaim()
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		res := ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		Expect(res.RequeueAfter).To(Equal(10 * time.Second))
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		condition := ExpectStatusConditionExists(nodeClaim, v1beta1.Launched)
+		Expect(condition.Status).To(Equal(v1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("Throttled"))
+		Expect(recorder.Calls("Throttled")).To(Equal(1))
+	})
+	It("should requeue with backoff if Throttled is returned from the cloudprovider without a retry delay", func() {
+		cloudProvider.NextCreateErr = cloudprovider.NewThrottledError(fmt.Errorf("rate exceeded"), 0)
+		nodeClaim := test.NodeClaim()
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		res := ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		Expect(res.Requeue).To(BeTrue())
+	})
+	It("should delete the nodeclaim and surface the error on the nodepool if InvalidConfiguration is returned from the cloudprovider", func() {
+		cloudProvider.NextCreateErr = cloudprovider.NewInvalidConfigurationError(fmt.Errorf("image not found"))
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		condition := ExpectStatusConditionExists(nodePool, v1beta1.Launchable)
+		Expect(condition.Status).To(Equal(v1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("InvalidConfiguration"))
+		Expect(recorder.Calls("InvalidConfiguration")).To(Equal(2))
+		Expect(recorder.DetectedEvent(fmt.Sprintf("Failed to launch NodeClaims for NodePool %s: invalid configuration, image not found", nodePool.Name))).To(BeTrue())
+	})
+	It("should delete the nodeclaim and surface the error on the nodepool if Unauthorized is returned from the cloudprovider", func() {
+		cloudProvider.NextCreateErr = cloudprovider.NewUnauthorizedError(fmt.Errorf("access denied"))
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		condition := ExpectStatusConditionExists(nodePool, v1beta1.Launchable)
+		Expect(condition.Status).To(Equal(v1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("Unauthorized"))
+		Expect(recorder.DetectedEvent(fmt.Sprintf("Failed to launch NodeClaims for NodePool %s: unauthorized, access denied", nodePool.Name))).To(BeTrue())
+	})
+	It("should mark the nodepool as launchable once one of its nodeclaims launches", func() {
+		nodePool.StatusConditions().MarkFalse(v1beta1.Launchable, "InvalidConfiguration", "image not found")
+		nodeClaim := test.NodeClaim(v1beta1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1beta1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(ExpectStatusConditionExists(nodePool, v1beta1.Launchable).Status).To(Equal(v1.ConditionTrue))
+	})
+	Context("BatchCreator", func() {
+		var batchCloudProvider *batchCreatorCloudProvider
+		var batchController controller.Controller
+		BeforeEach(func() {
+			batchCloudProvider = &batchCreatorCloudProvider{CloudProvider: cloudProvider}
+			batchController = nodeclaimlifecycle.NewNodeClaimController(fakeClock, env.Client, batchCloudProvider, recorder, unavailableOfferings)
+		})
+		It("should launch compatible NodeClaims that are created together in a single batch", func() {
+			nodeClaims := make([]*v1beta1.NodeClaim, 3)
+			for i := range nodeClaims {
+				nodeClaims[i] = test.NodeClaim(v1beta1.NodeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							v1beta1.NodePoolLabelKey: nodePool.Name,
+						},
+					},
+				})
+				ExpectApplied(ctx, env.Client, nodeClaims[i])
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			Expect(reconcileInParallel(batchController, nodeClaims...)).To(HaveEach(BeNil()))
+
+			Expect(batchCloudProvider.Batches()).To(HaveLen(1))
+			Expect(batchCloudProvider.Batches()[0]).To(HaveLen(3))
+			for _, nodeClaim := range nodeClaims {
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Launched).Status).To(Equal(v1.ConditionTrue))
+				Expect(nodeClaim.Status.ProviderID).ToNot(BeEmpty())
+			}
+		})
+		It("should launch NodeClaims with different requirements in separate batches", func() {
+			nodeClaims := []*v1beta1.NodeClaim{
+				test.NodeClaim(v1beta1.NodeClaim{
+					Spec: v1beta1.NodeClaimSpec{
+						Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{
+							{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}},
+						},
+					},
+				}),
+				test.NodeClaim(v1beta1.NodeClaim{
+					Spec: v1beta1.NodeClaimSpec{
+						Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{
+							{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}},
+						},
+					},
+				}),
+			}
+			ExpectApplied(ctx, env.Client, nodeClaims[0], nodeClaims[1])
+			Expect(reconcileInParallel(batchController, nodeClaims...)).To(HaveEach(BeNil()))
+
+			Expect(batchCloudProvider.Batches()).To(HaveLen(2))
+			Expect(batchCloudProvider.Batches()[0]).To(HaveLen(1))
+			Expect(batchCloudProvider.Batches()[1]).To(HaveLen(1))
+		})
+		It("should handle errors for individual NodeClaims in a batch", func() {
+			nodeClaims := []*v1beta1.NodeClaim{test.NodeClaim(), test.NodeClaim()}
+			ExpectApplied(ctx, env.Client, nodeClaims[0], nodeClaims[1])
+			batchCloudProvider.failNodeClaim = nodeClaims[1].Name
+			errs := reconcileInParallel(batchController, nodeClaims...)
+			Expect(errs[0]).ToNot(HaveOccurred())
+			Expect(errs[1]).To(HaveOccurred())
+			Expect(batchCloudProvider.Batches()).To(HaveLen(1))
+
+			nodeClaim := ExpectExists(ctx, env.Client, nodeClaims[0])
+			Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Launched).Status).To(Equal(v1.ConditionTrue))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaims[1])
+			Expect(ExpectStatusConditionExists(nodeClaim, v1beta1.Launched).Status).To(Equal(v1.ConditionFalse))
+		})
+	})
 })
+
+// reconcileInParallel reconciles the NodeClaims concurrently so that they're launched in one batch and returns the
+// error of each reconcile
+func reconcileInParallel(c controller.Controller, nodeClaims ...*v1beta1.NodeClaim) []error {
+	errs := make([]error, len(nodeClaims))
+	wg := sync.WaitGroup{}
+	for i, nodeClaim := range nodeClaims {
+		wg.Add(1)
+		go func(i int, nodeClaim *v1beta1.NodeClaim) {
+			defer wg.Done()
+			_, errs[i] = c.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nodeClaim)})
+		}(i, nodeClaim)
+	}
+	wg.Wait()
+	return errs
+}
+
+// batchCreatorCloudProvider implements BatchCreator by launching each NodeClaim of a batch with the fake CloudProvider
+type batchCreatorCloudProvider struct {
+	*fake.CloudProvider
+
+	mu            sync.Mutex
+	batches       [][]*v1beta1.NodeClaim
+	failNodeClaim string
+}
+
+func (c *batchCreatorCloudProvider) CreateBatch(ctx context.Context, nodeClaims []*v1beta1.NodeClaim) ([]*v1beta1.NodeClaim, []error) {
+	c.mu.Lock()
+	c.batches = append(c.batches, nodeClaims)
+	c.mu.Unlock()
+	created := make([]*v1beta1.NodeClaim, len(nodeClaims))
+	errs := make([]error, len(nodeClaims))
+	for i, nodeClaim := range nodeClaims {
+		if nodeClaim.Name == c.failNodeClaim {
+			errs[i] = fmt.Errorf("failed to launch %s", nodeClaim.Name)
+			continue
+		}
+		created[i], errs[i] = c.CloudProvider.Create(ctx, nodeClaim)
+	}
+	return created, errs
+}
+
+func (c *batchCreatorCloudProvider) Batches() [][]*v1beta1.NodeClaim {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.batches
+}
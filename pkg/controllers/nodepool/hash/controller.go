@@ -19,6 +19,7 @@ import (
 
 	"github.com/samber/lo"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"knative.dev/pkg/apis"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -53,6 +54,15 @@ func (c *Controller) Reconcile(ctx context.Context, np *v1beta1.NodePool) (recon
 			return reconcile.Result{}, client.IgnoreNotFound(err)
 		}
 	}
+	// The NodePool is only reconciled when its spec changes, which may fix the configuration that the CloudProvider
+	// rejected, so the provisioner should launch NodeClaims for it again
+	if np.StatusConditions().GetCondition(v1beta1.Launchable).IsFalse() {
+		stored = np.DeepCopy()
+		np.SetConditions(lo.Reject(np.GetConditions(), func(cond apis.Condition, _ int) bool { return cond.Type == v1beta1.Launchable }))
+		if err := nodepoolutil.PatchStatus(ctx, c.kubeClient, stored, np); err != nil {
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+	}
 	return reconcile.Result{}, nil
 }
 
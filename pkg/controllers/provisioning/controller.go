@@ -23,8 +23,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
 	"github.com/aws/karpenter-core/pkg/events"
 	corecontroller "github.com/aws/karpenter-core/pkg/operator/controller"
 	"github.com/aws/karpenter-core/pkg/utils/pod"
@@ -72,3 +74,42 @@ func (c *Controller) Builder(_ context.Context, m manager.Manager) corecontrolle
 		WithOptions(controller.Options{MaxConcurrentReconciles: 10}),
 	)
 }
+
+var _ corecontroller.TypedController[*v1beta1.NodePool] = (*NodePoolController)(nil)
+
+// NodePoolController triggers the provisioner for NodePools with headroom, so that the headroom is replenished when
+// it's taken by pods or when the nodes that it was kept on are removed
+type NodePoolController struct {
+	provisioner *Provisioner
+}
+
+// NewNodePoolController constructs a controller instance
+func NewNodePoolController(kubeClient client.Client, provisioner *Provisioner) corecontroller.Controller {
+	return corecontroller.Typed[*v1beta1.NodePool](kubeClient, &NodePoolController{
+		provisioner: provisioner,
+	})
+}
+
+func (c *NodePoolController) Name() string {
+	return "provisioner.trigger.nodepool"
+}
+
+// Reconcile the resource
+func (c *NodePoolController) Reconcile(_ context.Context, np *v1beta1.NodePool) (reconcile.Result, error) {
+	if np.Spec.Headroom == nil || !np.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	c.provisioner.Trigger()
+	// Nothing watches for the headroom being taken, so we periodically check that it's still available. This is less
+	// frequent than for pending pods, as the headroom is only needed by pods that haven't been created yet.
+	return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
+}
+
+func (c *NodePoolController) Builder(_ context.Context, m manager.Manager) corecontroller.Builder {
+	return corecontroller.Adapt(controllerruntime.
+		NewControllerManagedBy(m).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		For(&v1beta1.NodePool{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 10}),
+	)
+}
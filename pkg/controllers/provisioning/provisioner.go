@@ -16,19 +16,23 @@ package provisioning
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/patrickmn/go-cache"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/sets"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	nodev1client "k8s.io/client-go/kubernetes/typed/node/v1"
 	"k8s.io/client-go/util/workqueue"
 	"knative.dev/pkg/logging"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -75,24 +79,34 @@ type Provisioner struct {
 	cloudProvider  cloudprovider.CloudProvider
 	kubeClient     client.Client
 	coreV1Client   corev1.CoreV1Interface
+	nodeV1Client   nodev1client.NodeV1Interface
 	batcher        *Batcher
 	volumeTopology *scheduler.VolumeTopology
 	cluster        *state.Cluster
 	recorder       events.Recorder
 	cm             *pretty.ChangeMonitor
+	// persistedDecisions tracks the pods whose scheduling decision was recently patched so that pods whose
+	// decision changes on every round aren't patched on every round
+	persistedDecisions *cache.Cache
+
+	unavailableOfferings *cloudprovider.UnavailableOfferings
 }
 
-func NewProvisioner(kubeClient client.Client, coreV1Client corev1.CoreV1Interface,
-	recorder events.Recorder, cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster) *Provisioner {
+func NewProvisioner(kubeClient client.Client, coreV1Client corev1.CoreV1Interface, nodeV1Client nodev1client.NodeV1Interface,
+	recorder events.Recorder, cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster,
+	unavailableOfferings *cloudprovider.UnavailableOfferings) *Provisioner {
 	p := &Provisioner{
-		batcher:        NewBatcher(),
-		cloudProvider:  cloudProvider,
-		kubeClient:     kubeClient,
-		coreV1Client:   coreV1Client,
-		volumeTopology: scheduler.NewVolumeTopology(kubeClient),
-		cluster:        cluster,
-		recorder:       recorder,
-		cm:             pretty.NewChangeMonitor(),
+		batcher:              NewBatcher(),
+		cloudProvider:        cloudProvider,
+		kubeClient:           kubeClient,
+		coreV1Client:         coreV1Client,
+		nodeV1Client:         nodeV1Client,
+		volumeTopology:       scheduler.NewVolumeTopology(kubeClient),
+		cluster:              cluster,
+		recorder:             recorder,
+		cm:                   pretty.NewChangeMonitor(),
+		persistedDecisions:   cache.New(decisionPersistInterval, time.Minute),
+		unavailableOfferings: unavailableOfferings,
 	}
 	return p
 }
@@ -127,13 +141,58 @@ func (p *Provisioner) Reconcile(ctx context.Context, _ reconcile.Request) (resul
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	if len(results.NewNodeClaims) == 0 {
-		return reconcile.Result{}, nil
+	if len(results.NewNodeClaims) != 0 {
+		_, err = p.CreateNodeClaims(ctx, results.NewNodeClaims, WithReason(metrics.ProvisioningReason), RecordPodNomination)
 	}
-	_, err = p.CreateNodeClaims(ctx, results.NewNodeClaims, WithReason(metrics.ProvisioningReason), RecordPodNomination)
+	p.PersistDecisions(ctx, results.PodDecisions)
 	return reconcile.Result{}, err
 }
 
+// decisionPersistInterval is the minimum interval between patches of a single pod's scheduling decision
+const decisionPersistInterval = time.Minute
+
+// runtimeClassTimeout bounds reading a RuntimeClass, so that it can't block provisioning
+const runtimeClassTimeout = 5 * time.Second
+
+// notLaunchableRetryPeriod is how long a NodePool that isn't launchable is skipped for before a NodeClaim is launched
+// for it again. Changes to the NodePool reset its condition, but changes to its NodeClass or to the CloudProvider's
+// permissions can only be discovered by launching again.
+const notLaunchableRetryPeriod = 5 * time.Minute
+
+// PersistDecisions annotates the pending pods with the decisions that the scheduler made for them, so that the reasons
+// that pods couldn't schedule can be queried in bulk. Pods are only patched when their decision changes, and at most
+// once every decisionPersistInterval. Failures are logged rather than returned since the decisions are informational.
+func (p *Provisioner) PersistDecisions(ctx context.Context, decisions map[*v1.Pod]*scheduler.Decision) {
+	pods := lo.Filter(lo.Keys(decisions), func(po *v1.Pod, _ int) bool { return pod.IsProvisionable(po) })
+	workqueue.ParallelizeUntil(ctx, 20, len(pods), func(i int) {
+		raw, err := json.Marshal(decisions[pods[i]])
+		if err != nil {
+			logging.FromContext(ctx).With("pod", client.ObjectKeyFromObject(pods[i])).Errorf("serializing scheduling decision, %s", err)
+			return
+		}
+		if pods[i].Annotations[v1beta1.SchedulingDecisionAnnotationKey] == string(raw) {
+			return
+		}
+		if _, ok := p.persistedDecisions.Get(string(pods[i].UID)); ok {
+			return
+		}
+		// We use a raw merge patch so that we only write the annotation and don't conflict with other writers
+		patch := lo.Must(json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]string{v1beta1.SchedulingDecisionAnnotationKey: string(raw)},
+			},
+		}))
+		stored := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: pods[i].Name, Namespace: pods[i].Namespace}}
+		if err := p.kubeClient.Patch(ctx, stored, client.RawPatch(types.MergePatchType, patch)); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				logging.FromContext(ctx).With("pod", client.ObjectKeyFromObject(pods[i])).Errorf("persisting scheduling decision, %s", err)
+			}
+			return
+		}
+		p.persistedDecisions.SetDefault(string(pods[i].UID), struct{}{})
+	})
+}
+
 // CreateNodeClaims launches nodes passed into the function in parallel. It returns a slice of the successfully created node
 // names as well as a multierr of any errors that occurred while launching nodes
 func (p *Provisioner) CreateNodeClaims(ctx context.Context, nodeClaims []*scheduler.NodeClaim, opts ...functional.Option[LaunchOptions]) ([]string, error) {
@@ -175,6 +234,23 @@ func (p *Provisioner) GetPendingPods(ctx context.Context) ([]*v1.Pod, error) {
 	return pods, nil
 }
 
+// GetHeadroomPods returns the virtual pods that represent the headroom of the NodePools, which are scheduled like
+// pending pods so that capacity is kept available for them
+func (p *Provisioner) GetHeadroomPods(ctx context.Context) ([]*v1.Pod, error) {
+	nodePoolList, err := nodepoolutil.List(ctx, p.kubeClient)
+	if err != nil {
+		return nil, fmt.Errorf("listing nodepools, %w", err)
+	}
+	var pods []*v1.Pod
+	for i := range nodePoolList.Items {
+		if !nodePoolList.Items[i].DeletionTimestamp.IsZero() {
+			continue
+		}
+		pods = append(pods, scheduler.HeadroomPods(&nodePoolList.Items[i])...)
+	}
+	return pods, nil
+}
+
 // consolidationWarnings potentially writes logs warning about possible unexpected interactions between scheduling
 // constraints and consolidation
 func (p *Provisioner) consolidationWarnings(ctx context.Context, po v1.Pod) {
@@ -203,7 +279,7 @@ func (p *Provisioner) NewScheduler(ctx context.Context, pods []*v1.Pod, stateNod
 	// Build node templates
 	var nodeClaimTemplates []*scheduler.NodeClaimTemplate
 	instanceTypes := map[string][]*cloudprovider.InstanceType{}
-	domains := map[string]sets.Set[string]{}
+	domainGroups := map[string]scheduler.TopologyDomainGroup{}
 
 	nodePoolList, err := nodepoolutil.List(ctx, p.kubeClient)
 	if err != nil {
@@ -227,6 +303,12 @@ func (p *Provisioner) NewScheduler(ctx context.Context, pods []*v1.Pod, stateNod
 
 	for i := range nodePoolList.Items {
 		nodePool := &nodePoolList.Items[i]
+		// The CloudProvider rejected the configuration or permissions of the NodePool, so launching NodeClaims for it
+		// would fail until the NodePool, its NodeClass or the permissions change
+		if cond := nodePool.StatusConditions().GetCondition(v1beta1.Launchable); cond.IsFalse() && time.Since(cond.LastTransitionTime.Inner.Time) < notLaunchableRetryPeriod {
+			logging.FromContext(ctx).With("nodepool", nodePool.Name).Debugf("skipping, nodepool isn't launchable, %s", cond.Message)
+			continue
+		}
 		// Create node template
 		nodeClaimTemplates = append(nodeClaimTemplates, scheduler.NewNodeClaimTemplate(nodePool))
 		// Get instance type options
@@ -247,7 +329,7 @@ func (p *Provisioner) NewScheduler(ctx context.Context, pods []*v1.Pod, stateNod
 		for _, instanceType := range instanceTypeOptions {
 			// We need to intersect the instance type requirements with the current nodePool requirements.  This
 			// ensures that something like zones from an instance type don't expand the universe of valid domains.
-			requirements := scheduling.NewNodeSelectorRequirements(nodePool.Spec.Template.Spec.Requirements...)
+			requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Spec.Template.Spec.Requirements...)
 			requirements.Add(scheduling.NewLabelRequirements(nodePool.Spec.Template.Labels).Values()...)
 			requirements.Add(instanceType.Requirements.Values()...)
 
@@ -256,23 +338,26 @@ func (p *Provisioner) NewScheduler(ctx context.Context, pods []*v1.Pod, stateNod
 				// The downside of this is that Union is immutable and takes a copy of the set it is executed upon.
 				// This resulted in a lot of memory pressure on the heap and poor performance
 				// https://github.com/aws/karpenter/issues/3565
-				if domains[key] == nil {
-					domains[key] = sets.New(requirement.Values()...)
-				} else {
-					domains[key].Insert(requirement.Values()...)
+				if domainGroups[key] == nil {
+					domainGroups[key] = scheduler.NewTopologyDomainGroup()
+				}
+				// the taints of the NodePool are tracked with its domains for topology spread constraints that honor taints
+				for _, domain := range requirement.Values() {
+					domainGroups[key].Insert(domain, nodePool.Spec.Template.Spec.Taints...)
 				}
 			}
 		}
 
-		requirements := scheduling.NewNodeSelectorRequirements(nodePool.Spec.Template.Spec.Requirements...)
+		requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Spec.Template.Spec.Requirements...)
 		requirements.Add(scheduling.NewLabelRequirements(nodePool.Spec.Template.Labels).Values()...)
 		for key, requirement := range requirements {
 			if requirement.Operator() == v1.NodeSelectorOpIn {
 				//The following is a performance optimisation, for the explanation see the comment above
-				if domains[key] == nil {
-					domains[key] = sets.New(requirement.Values()...)
-				} else {
-					domains[key].Insert(requirement.Values()...)
+				if domainGroups[key] == nil {
+					domainGroups[key] = scheduler.NewTopologyDomainGroup()
+				}
+				for _, domain := range requirement.Values() {
+					domainGroups[key].Insert(domain, nodePool.Spec.Template.Spec.Taints...)
 				}
 			}
 		}
@@ -282,7 +367,7 @@ func (p *Provisioner) NewScheduler(ctx context.Context, pods []*v1.Pod, stateNod
 	pods = p.injectTopology(ctx, pods)
 
 	// Calculate cluster topology
-	topology, err := scheduler.NewTopology(ctx, p.kubeClient, p.cluster, domains, pods)
+	topology, err := scheduler.NewTopology(ctx, p.kubeClient, p.cluster, domainGroups, pods)
 	if err != nil {
 		return nil, fmt.Errorf("tracking topology counts, %w", err)
 	}
@@ -290,7 +375,7 @@ func (p *Provisioner) NewScheduler(ctx context.Context, pods []*v1.Pod, stateNod
 	if err != nil {
 		return nil, fmt.Errorf("getting daemon pods, %w", err)
 	}
-	return scheduler.NewScheduler(ctx, p.kubeClient, nodeClaimTemplates, nodePoolList.Items, p.cluster, stateNodes, topology, instanceTypes, daemonSetPods, p.recorder, opts), nil
+	return scheduler.NewScheduler(ctx, p.kubeClient, nodeClaimTemplates, nodePoolList.Items, p.cluster, stateNodes, topology, instanceTypes, daemonSetPods, p.unavailableOfferings, p.recorder, opts), nil
 }
 
 func (p *Provisioner) Schedule(ctx context.Context) (*scheduler.Results, error) {
@@ -321,6 +406,12 @@ func (p *Provisioner) Schedule(ctx context.Context) (*scheduler.Results, error)
 		return nil, err
 	}
 	pods := append(pendingPods, deletingNodePods...)
+	// the headroom is only kept available once the pods that are actually pending have been scheduled
+	headroomPods, err := p.GetHeadroomPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pods = append(pods, headroomPods...)
 	// nothing to schedule, so just return success
 	if len(pods) == 0 {
 		return &scheduler.Results{}, nil
@@ -350,15 +441,17 @@ func (p *Provisioner) Create(ctx context.Context, n *scheduler.NodeClaim, opts .
 	if err := p.kubeClient.Create(ctx, nodeClaim); err != nil {
 		return "", err
 	}
-	instanceTypeRequirement, _ := lo.Find(nodeClaim.Spec.Requirements, func(req v1.NodeSelectorRequirement) bool { return req.Key == v1.LabelInstanceTypeStable })
+	instanceTypeRequirement, _ := lo.Find(nodeClaim.Spec.Requirements, func(req v1beta1.NodeSelectorRequirementWithMinValues) bool {
+		return req.Key == v1.LabelInstanceTypeStable
+	})
 	logging.FromContext(ctx).With("nodeclaim", nodeClaim.Name, "requests", nodeClaim.Spec.Resources.Requests, "instance-types", instanceTypeList(instanceTypeRequirement.Values)).Infof("created nodeclaim")
 	metrics.NodeClaimsCreatedCounter.With(prometheus.Labels{
 		metrics.ReasonLabel:   options.Reason,
 		metrics.NodePoolLabel: nodeClaim.Labels[v1beta1.NodePoolLabelKey],
 	}).Inc()
 	if functional.ResolveOptions(opts...).RecordPodNomination {
-		for _, pod := range n.Pods {
-			p.recorder.Publish(scheduler.NominatePodEvent(pod, nil, nodeClaim))
+		for _, po := range lo.Reject(n.Pods, func(po *v1.Pod, _ int) bool { return pod.IsHeadroom(po) }) {
+			p.recorder.Publish(scheduler.NominatePodEvent(po, nil, nodeClaim))
 		}
 	}
 	return nodeClaim.Name, nil
@@ -379,16 +472,24 @@ func instanceTypeList(names []string) string {
 	return itSb.String()
 }
 
+// getDaemonSetPods returns a pod for each daemonset to compute daemon overhead. Pod templates that reference a
+// RuntimeClass get its fixed overhead, which requires the controller to be granted get on runtimeclasses.node.k8s.io.
 func (p *Provisioner) getDaemonSetPods(ctx context.Context) ([]*v1.Pod, error) {
 	daemonSetList := &appsv1.DaemonSetList{}
 	if err := p.kubeClient.List(ctx, daemonSetList); err != nil {
 		return nil, fmt.Errorf("listing daemonsets, %w", err)
 	}
 
-	return lo.Map(daemonSetList.Items, func(d appsv1.DaemonSet, _ int) *v1.Pod {
-		pod := p.cluster.GetDaemonSetPod(&d)
+	var pods []*v1.Pod
+	for i := range daemonSetList.Items {
+		d := &daemonSetList.Items[i]
+		pod := p.cluster.GetDaemonSetPod(d)
 		if pod == nil {
 			pod = &v1.Pod{Spec: d.Spec.Template.Spec}
+			// The overhead of the RuntimeClass is populated on pods at admission, so it's missing from the pod template
+			if pod.Spec.RuntimeClassName != nil && pod.Spec.Overhead == nil {
+				pod.Spec.Overhead = p.runtimeClassOverhead(ctx, *pod.Spec.RuntimeClassName)
+			}
 		}
 		// Replacing retrieved pod affinity with daemonset pod template required node affinity since this is overridden
 		// by the daemonset controller during pod creation
@@ -402,8 +503,28 @@ func (p *Provisioner) getDaemonSetPods(ctx context.Context) ([]*v1.Pod, error) {
 			}
 			pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = d.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
 		}
-		return pod
-	}), nil
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+// runtimeClassOverhead returns the fixed pod overhead of the RuntimeClass. The RuntimeClass is read from the API server
+// rather than the cache, since reading it from the cache starts an informer that never syncs when the controller isn't
+// granted list and watch on runtimeclasses.node.k8s.io. If it can't be read, the overhead is ignored.
+func (p *Provisioner) runtimeClassOverhead(ctx context.Context, name string) v1.ResourceList {
+	ctx, cancel := context.WithTimeout(ctx, runtimeClassTimeout)
+	defer cancel()
+	runtimeClass, err := p.nodeV1Client.RuntimeClasses().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			logging.FromContext(ctx).With("runtime-class", name).Warnf("getting runtime class, ignoring its overhead, %s", err)
+		}
+		return nil
+	}
+	if runtimeClass.Overhead == nil {
+		return nil
+	}
+	return runtimeClass.Overhead.PodFixed
 }
 
 func (p *Provisioner) Validate(ctx context.Context, pod *v1.Pod) error {
@@ -0,0 +1,237 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/logging"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/operator/options"
+	"github.com/aws/karpenter-core/pkg/utils/resources"
+)
+
+// BinPacker decides the order that pods are scheduled in and the order that the existing nodes and the NodeClaims that
+// we are about to create are tried for each pod. Pods are always tried against existing nodes first, then against the
+// NodeClaims that we are about to create, and then against new NodeClaims from each NodePool in weight order.
+type BinPacker interface {
+	// SortPods orders the pods before they are scheduled
+	SortPods(pods []*v1.Pod)
+	// SortExistingNodes orders the existing nodes that the pod is tried against
+	SortExistingNodes(pod *v1.Pod, nodes []*ExistingNode)
+	// SortNodeClaims orders the NodeClaims that we are about to create that the pod is tried against
+	SortNodeClaims(pod *v1.Pod, nodeClaims []*NodeClaim)
+}
+
+// NewBinPacker returns the BinPacker for the strategy
+func NewBinPacker(strategy string) (BinPacker, error) {
+	switch strategy {
+	case options.BinPackingStrategyFirstFitDecreasing:
+		return FirstFitDecreasing{}, nil
+	case options.BinPackingStrategyBestFit:
+		return BestFit{}, nil
+	case options.BinPackingStrategyCostAware:
+		return CostAware{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported bin-packing strategy %q", strategy)
+	}
+}
+
+// FirstFitDecreasing schedules the largest pods first, onto existing nodes in the order that they were considered and
+// onto the NodeClaims that we are about to create with the fewest pods first
+type FirstFitDecreasing struct{}
+
+func (FirstFitDecreasing) SortPods(pods []*v1.Pod) {
+	sort.Slice(pods, byCPUAndMemoryDescending(pods))
+}
+
+func (FirstFitDecreasing) SortExistingNodes(*v1.Pod, []*ExistingNode) {}
+
+func (FirstFitDecreasing) SortNodeClaims(_ *v1.Pod, nodeClaims []*NodeClaim) {
+	// Consider using https://pkg.go.dev/container/heap
+	sort.Slice(nodeClaims, func(a, b int) bool { return len(nodeClaims[a].Pods) < len(nodeClaims[b].Pods) })
+}
+
+// BestFit schedules the largest pods first, onto the existing nodes and the NodeClaims that we are about to create
+// that have the least capacity left, so that large pods aren't left without room by fragmented capacity. Initialized
+// nodes are still tried before in-flight nodes.
+type BestFit struct{}
+
+func (BestFit) SortPods(pods []*v1.Pod) {
+	FirstFitDecreasing{}.SortPods(pods)
+}
+
+func (BestFit) SortExistingNodes(_ *v1.Pod, nodes []*ExistingNode) {
+	sortExistingNodesByFit(nodes)
+}
+
+func (BestFit) SortNodeClaims(_ *v1.Pod, nodeClaims []*NodeClaim) {
+	sort.SliceStable(nodeClaims, func(a, b int) bool {
+		return nodeClaims[a].remainingFraction < nodeClaims[b].remainingFraction
+	})
+}
+
+// CostAware schedules the largest pods first, onto the NodeClaims that we are about to create whose estimated price
+// increases the least when the pod is added. Existing nodes are already paid for, so they are packed as tightly as
+// possible to leave whole nodes for consolidation to remove.
+type CostAware struct{}
+
+func (CostAware) SortPods(pods []*v1.Pod) {
+	FirstFitDecreasing{}.SortPods(pods)
+}
+
+func (CostAware) SortExistingNodes(_ *v1.Pod, nodes []*ExistingNode) {
+	sortExistingNodesByFit(nodes)
+}
+
+func (CostAware) SortNodeClaims(pod *v1.Pod, nodeClaims []*NodeClaim) {
+	increases := make(map[*NodeClaim]float64, len(nodeClaims))
+	for _, n := range nodeClaims {
+		increases[n] = n.priceIncrease(pod)
+	}
+	sort.SliceStable(nodeClaims, func(a, b int) bool {
+		if increases[nodeClaims[a]] != increases[nodeClaims[b]] {
+			return increases[nodeClaims[a]] < increases[nodeClaims[b]]
+		}
+		return len(nodeClaims[a].Pods) < len(nodeClaims[b].Pods)
+	})
+}
+
+// sortExistingNodesByFit orders initialized nodes before in-flight nodes, and then the nodes with the least capacity
+// left first
+func sortExistingNodesByFit(nodes []*ExistingNode) {
+	remaining := make(map[*ExistingNode]float64, len(nodes))
+	for _, n := range nodes {
+		remaining[n] = n.remainingFraction()
+	}
+	sort.SliceStable(nodes, func(a, b int) bool {
+		if nodes[a].Initialized() != nodes[b].Initialized() {
+			return nodes[a].Initialized()
+		}
+		return remaining[nodes[a]] < remaining[nodes[b]]
+	})
+}
+
+// binPackers resolves the BinPacker that is used for the pods and for each NodePool. NodePools can override the
+// operator-wide strategy through spec.binPackingStrategy.
+func binPackers(ctx context.Context, nodePools []v1beta1.NodePool) (BinPacker, map[string]BinPacker) {
+	binPacker, err := NewBinPacker(options.FromContext(ctx).BinPackingStrategy)
+	if err != nil {
+		// options are validated when they're parsed, so this only happens if they were constructed directly
+		logging.FromContext(ctx).Errorf("falling back to %s, %s", options.BinPackingStrategyFirstFitDecreasing, err)
+		binPacker = FirstFitDecreasing{}
+	}
+	nodePoolBinPackers := map[string]BinPacker{}
+	for i := range nodePools {
+		if nodePools[i].Spec.BinPackingStrategy == "" {
+			continue
+		}
+		nodePoolBinPacker, err := NewBinPacker(string(nodePools[i].Spec.BinPackingStrategy))
+		if err != nil {
+			// the strategy is validated by the NodePool's schema, so this only happens if it bypassed validation
+			logging.FromContext(ctx).With("nodepool", nodePools[i].Name).Errorf("ignoring bin-packing strategy, %s", err)
+			continue
+		}
+		nodePoolBinPackers[nodePools[i].Name] = nodePoolBinPacker
+	}
+	return binPacker, nodePoolBinPackers
+}
+
+// sortByNodePool orders the items with the operator-wide BinPacker, and then re-orders the items of each NodePool that
+// overrides the strategy among the positions that they were ordered into. This way, a NodePool's strategy only
+// decides the order of its own nodes relative to each other.
+func sortByNodePool[T any](items []T, nodePoolName func(T) string, binPacker BinPacker, nodePoolBinPackers map[string]BinPacker, sortFn func(BinPacker, []T)) {
+	sortFn(binPacker, items)
+	for name, nodePoolBinPacker := range nodePoolBinPackers {
+		var indices []int
+		var owned []T
+		for i, item := range items {
+			if nodePoolName(item) == name {
+				indices = append(indices, i)
+				owned = append(owned, item)
+			}
+		}
+		if len(owned) < 2 {
+			continue
+		}
+		sortFn(nodePoolBinPacker, owned)
+		for i, index := range indices {
+			items[index] = owned[i]
+		}
+	}
+}
+
+// remainingFraction returns the average fraction of the allocatable cpu and memory that is left on the node
+func (n *ExistingNode) remainingFraction() float64 {
+	return remainingFraction(n.allocatable, resources.Merge(n.PodRequests(), n.requests))
+}
+
+func remainingFraction(allocatable, requests v1.ResourceList) float64 {
+	var total float64
+	for _, resourceName := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		capacity := allocatable[resourceName]
+		if capacity.IsZero() {
+			continue
+		}
+		requested := requests[resourceName]
+		total += math.Max(0, 1-requested.AsApproximateFloat64()/capacity.AsApproximateFloat64())
+	}
+	return total / 2
+}
+
+// instanceTypePrice is the cheapest price that an instance type option of a NodeClaim can launch at
+type instanceTypePrice struct {
+	price       float64
+	allocatable v1.ResourceList
+}
+
+// updateBinPackingEstimates recomputes the estimates that the BinPackers order the NodeClaim by. They only change when
+// pods are added to the NodeClaim, so they're computed then rather than each time the NodeClaims are sorted.
+func (n *NodeClaim) updateBinPackingEstimates() {
+	// the remaining fraction assumes that the NodeClaim launches as the largest of its instance type options
+	allocatable := v1.ResourceList{}
+	n.prices = n.prices[:0]
+	for _, it := range n.InstanceTypeOptions {
+		allocatable = resources.MaxResources(allocatable, it.Allocatable())
+		offerings := it.Offerings.Available().Requirements(n.Requirements)
+		if len(offerings) == 0 {
+			continue
+		}
+		n.prices = append(n.prices, instanceTypePrice{price: offerings.Cheapest().Price, allocatable: it.Allocatable()})
+	}
+	sort.SliceStable(n.prices, func(a, b int) bool { return n.prices[a].price < n.prices[b].price })
+	n.remainingFraction = remainingFraction(allocatable, n.Spec.Resources.Requests)
+}
+
+// priceIncrease estimates how much the price of the NodeClaim increases if the pod is added to it, from the cheapest
+// instance type options that fit its requests before and after. The pod's requirements aren't considered, so this is an
+// estimate. If no instance type option fits the pod, the increase is infinite.
+func (n *NodeClaim) priceIncrease(pod *v1.Pod) float64 {
+	if len(n.prices) == 0 {
+		return math.Inf(1)
+	}
+	requests := resources.Merge(n.Spec.Resources.Requests, resources.RequestsForPods(pod))
+	for _, p := range n.prices {
+		if resources.Fits(requests, p.allocatable) {
+			return p.price - n.prices[0].price
+		}
+	}
+	return math.Inf(1)
+}
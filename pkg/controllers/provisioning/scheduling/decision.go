@@ -0,0 +1,109 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"errors"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// RejectionReason is the reason that a NodePool couldn't launch a NodeClaim for a pod
+type RejectionReason string
+
+const (
+	RejectionReasonTaints        RejectionReason = "Taints"
+	RejectionReasonHostPorts     RejectionReason = "HostPorts"
+	RejectionReasonRequirements  RejectionReason = "Requirements"
+	RejectionReasonTopology      RejectionReason = "Topology"
+	RejectionReasonLimits        RejectionReason = "Limits"
+	RejectionReasonInstanceTypes RejectionReason = "InstanceTypes"
+	RejectionReasonMinValues     RejectionReason = "MinValues"
+	RejectionReasonReservations  RejectionReason = "Reservations"
+)
+
+// Decision records how the scheduler decided where a pod schedules, or why it couldn't. It's persisted on pending pods
+// as JSON in the karpenter.sh/scheduling-decision annotation so that it can be queried without interpreting the
+// scheduling errors.
+type Decision struct {
+	// Scheduled is true if the pod schedules to an existing node or to a NodeClaim that will be launched
+	Scheduled bool `json:"scheduled"`
+	// Node is the existing node that the pod schedules to
+	Node string `json:"node,omitempty"`
+	// NodePool is the NodePool of the NodeClaim that the pod schedules to
+	NodePool string `json:"nodePool,omitempty"`
+	// Preempted are the pods, as namespace/name, that kube-scheduler is expected to preempt on the node for the pod
+	Preempted []string `json:"preempted,omitempty"`
+	// Rejections are the NodePools that were considered for the pod and the reason that each was rejected, in the
+	// order that they were considered during the last attempt to schedule the pod
+	Rejections []Rejection `json:"rejections,omitempty"`
+	// RelaxedPreferences are the preferences that were removed from the pod, in the order that they were removed
+	RelaxedPreferences []string `json:"relaxedPreferences,omitempty"`
+}
+
+// Rejection is the reason that a NodePool couldn't launch a NodeClaim for a pod
+type Rejection struct {
+	NodePool string          `json:"nodePool"`
+	Reason   RejectionReason `json:"reason"`
+	Message  string          `json:"message"`
+	// RequirementKeys are the keys of the pod's requirements that conflict with the NodePool's requirements
+	RequirementKeys []string `json:"requirementKeys,omitempty"`
+	// DaemonSetOverhead are the resources requested by the daemonsets that would schedule to the NodeClaim
+	DaemonSetOverhead v1.ResourceList `json:"daemonSetOverhead,omitempty"`
+	// InstanceTypes counts the instance types of the NodePool that passed each filter
+	InstanceTypes *InstanceTypeCounts `json:"instanceTypes,omitempty"`
+}
+
+// InstanceTypeCounts are the number of instance types that passed each of the filters that an instance type must pass
+// for the pod to schedule to it. Each filter is applied independently, so an instance type can be counted by several.
+type InstanceTypeCounts struct {
+	Total           int `json:"total"`
+	RequirementsMet int `json:"requirementsMet"`
+	Fit             int `json:"fit"`
+	HasOffering     int `json:"hasOffering"`
+}
+
+// rejectionError is returned by NodeClaim.Add so that the reason that the pod was rejected can be recorded in its
+// Decision without parsing the error message
+type rejectionError struct {
+	error
+	reason          RejectionReason
+	requirementKeys []string
+	instanceTypes   *InstanceTypeCounts
+}
+
+func (e *rejectionError) Unwrap() error {
+	return e.error
+}
+
+func newRejectionError(reason RejectionReason, err error) *rejectionError {
+	return &rejectionError{error: err, reason: reason}
+}
+
+// newRejection returns the Rejection of the NodePool from the error that the pod failed to be added with
+func newRejection(nodePoolName string, daemonOverhead v1.ResourceList, err error) Rejection {
+	rejection := Rejection{
+		NodePool:          nodePoolName,
+		Message:           err.Error(),
+		DaemonSetOverhead: daemonOverhead,
+	}
+	rejectionErr := &rejectionError{}
+	if errors.As(err, &rejectionErr) {
+		rejection.Reason = rejectionErr.reason
+		rejection.RequirementKeys = rejectionErr.requirementKeys
+		rejection.InstanceTypes = rejectionErr.instanceTypes
+	}
+	return rejection
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -33,9 +34,15 @@ type ExistingNode struct {
 	topology     *Topology
 	requests     v1.ResourceList
 	requirements scheduling.Requirements
+	allocatable  v1.ResourceList
+	boundPods    []*v1.Pod // the pods bound to the node, which are only listed if we try to preempt them
+	victims      []*v1.Pod // the bound pods that we expect kube-scheduler to preempt for the pods added to the node
 }
 
-func NewExistingNode(n *state.StateNode, topology *Topology, daemonResources v1.ResourceList) *ExistingNode {
+// NewExistingNode returns an ExistingNode for the state node. If the expected allocatable of an in-flight node is
+// passed, it caps the allocatable that the state node reports, as that may not account for the kubelet configuration
+// until kubelet reports the allocatable of the node.
+func NewExistingNode(n *state.StateNode, topology *Topology, daemonResources v1.ResourceList, inflightAllocatable v1.ResourceList) *ExistingNode {
 	// The state node passed in here must be a deep copy from cluster state as we modify it
 	// the remaining daemonResources to schedule are the total daemonResources minus what has already scheduled
 	remainingDaemonResources := resources.Subtract(daemonResources, n.DaemonSetRequests())
@@ -53,6 +60,12 @@ func NewExistingNode(n *state.StateNode, topology *Topology, daemonResources v1.
 		topology:     topology,
 		requests:     remainingDaemonResources,
 		requirements: scheduling.NewLabelRequirements(n.Labels()),
+		allocatable:  lo.Assign(n.Allocatable()),
+	}
+	for resourceName, quantity := range inflightAllocatable {
+		if current, ok := node.allocatable[resourceName]; ok && quantity.Cmp(current) < 0 {
+			node.allocatable[resourceName] = quantity
+		}
 	}
 	node.requirements.Add(scheduling.NewRequirement(v1.LabelHostname, v1.NodeSelectorOpIn, n.HostName()))
 	topology.Register(v1.LabelHostname, n.HostName())
@@ -82,7 +95,7 @@ func (n *ExistingNode) Add(ctx context.Context, kubeClient client.Client, pod *v
 	// node, which at this point can't be increased in size
 	requests := resources.Merge(n.requests, resources.RequestsForPods(pod))
 
-	if !resources.Fits(requests, n.Available()) {
+	if !resources.Fits(requests, n.available()) {
 		return fmt.Errorf("exceeds node resources")
 	}
 
@@ -115,8 +128,14 @@ func (n *ExistingNode) Add(ctx context.Context, kubeClient client.Client, pod *v
 	n.Pods = append(n.Pods, pod)
 	n.requests = requests
 	n.requirements = nodeRequirements
-	n.topology.Record(pod, nodeRequirements)
+	n.topology.Record(pod, n.Taints(), nodeRequirements)
 	n.HostPortUsage().Add(pod, hostPorts)
 	n.VolumeUsage().Add(pod, volumes)
 	return nil
 }
+
+// available returns the resources of the node that aren't requested by the pods bound to it, including the resources of
+// the pods that we expect to be preempted
+func (n *ExistingNode) available() v1.ResourceList {
+	return resources.Merge(resources.Subtract(n.allocatable, n.PodRequests()), resources.RequestsForPods(n.victims...))
+}
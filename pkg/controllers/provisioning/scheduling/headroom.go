@@ -0,0 +1,70 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"fmt"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+)
+
+// HeadroomPods returns the virtual pods that represent the headroom of the NodePool. The pods only schedule to the
+// NodePool's nodes, so scheduling them keeps the headroom available on its existing nodes or launches nodes for it.
+// The headroom resources must be free on a single node, so they're represented by a single pod.
+func HeadroomPods(nodePool *v1beta1.NodePool) []*v1.Pod {
+	headroom := nodePool.Spec.Headroom
+	if headroom == nil {
+		return nil
+	}
+	var pods []*v1.Pod
+	if len(headroom.Resources) != 0 {
+		pods = append(pods, newHeadroomPod(nodePool, "resources", headroom.Resources))
+	}
+	if headroom.Pods != nil {
+		for i := 0; i < int(headroom.Pods.Count); i++ {
+			pods = append(pods, newHeadroomPod(nodePool, fmt.Sprintf("pod-%d", i), headroom.Pods.Requests))
+		}
+	}
+	return pods
+}
+
+func newHeadroomPod(nodePool *v1beta1.NodePool, suffix string, requests v1.ResourceList) *v1.Pod {
+	name := fmt.Sprintf("%s-headroom-%s", nodePool.Name, suffix)
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			// the UID only needs to be unique amongst the pods that are scheduled together, and NodePool names are unique
+			UID:         types.UID(name),
+			Annotations: map[string]string{v1beta1.HeadroomAnnotationKey: nodePool.Name},
+		},
+		Spec: v1.PodSpec{
+			NodeSelector: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name},
+			Tolerations: lo.Map(nodePool.Spec.Template.Spec.Taints, func(t v1.Taint, _ int) v1.Toleration {
+				return v1.Toleration{Key: t.Key, Operator: v1.TolerationOpExists, Effect: t.Effect}
+			}),
+			// the headroom yields to real pods, so it never displaces the pods that run on the nodes
+			PreemptionPolicy: lo.ToPtr(v1.PreemptNever),
+			Containers: []v1.Container{{
+				Name:      "headroom",
+				Resources: v1.ResourceRequirements{Requests: requests.DeepCopy()},
+			}},
+		},
+	}
+}
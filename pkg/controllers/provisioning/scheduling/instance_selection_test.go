@@ -21,6 +21,7 @@ import (
 	"github.com/mitchellh/hashstructure/v2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -47,17 +48,17 @@ var _ = Describe("Instance Type Selection", func() {
 			Spec: v1beta1.NodePoolSpec{
 				Template: v1beta1.NodeClaimTemplate{
 					Spec: v1beta1.NodeClaimSpec{
-						Requirements: []v1.NodeSelectorRequirement{
-							{
+						Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{
+							{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 								Key:      v1beta1.CapacityTypeLabelKey,
 								Operator: v1.NodeSelectorOpIn,
 								Values:   []string{v1beta1.CapacityTypeSpot, v1beta1.CapacityTypeOnDemand},
-							},
-							{
+							}},
+							{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 								Key:      v1.LabelArchStable,
 								Operator: v1.NodeSelectorOpIn,
 								Values:   []string{v1beta1.ArchitectureArm64, v1beta1.ArchitectureAmd64},
-							},
+							}},
 						},
 					},
 				},
@@ -112,12 +113,12 @@ var _ = Describe("Instance Type Selection", func() {
 		ExpectInstancesWithLabel(supportedInstanceTypes(cloudProvider.CreateCalls[0]), v1.LabelArchStable, v1beta1.ArchitectureArm64)
 	})
 	It("should schedule on one of the cheapest instances (prov arch = amd64)", func() {
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureAmd64},
-			},
+			}},
 		}
 		ExpectApplied(ctx, env.Client, nodePool)
 		pod := test.UnschedulablePod()
@@ -127,12 +128,12 @@ var _ = Describe("Instance Type Selection", func() {
 		ExpectInstancesWithLabel(supportedInstanceTypes(cloudProvider.CreateCalls[0]), v1.LabelArchStable, v1beta1.ArchitectureAmd64)
 	})
 	It("should schedule on one of the cheapest instances (prov arch = arm64)", func() {
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureArm64},
-			},
+			}},
 		}
 		ExpectApplied(ctx, env.Client, nodePool)
 		pod := test.UnschedulablePod()
@@ -142,12 +143,12 @@ var _ = Describe("Instance Type Selection", func() {
 		ExpectInstancesWithLabel(supportedInstanceTypes(cloudProvider.CreateCalls[0]), v1.LabelArchStable, v1beta1.ArchitectureArm64)
 	})
 	It("should schedule on one of the cheapest instances (prov os = windows)", func() {
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelOSStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{string(v1.Windows)},
-			},
+			}},
 		}
 		ExpectApplied(ctx, env.Client, nodePool)
 		pod := test.UnschedulablePod()
@@ -170,12 +171,12 @@ var _ = Describe("Instance Type Selection", func() {
 		ExpectInstancesWithLabel(supportedInstanceTypes(cloudProvider.CreateCalls[0]), v1.LabelOSStable, string(v1.Windows))
 	})
 	It("should schedule on one of the cheapest instances (prov os = windows)", func() {
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelOSStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{string(v1.Windows)},
-			},
+			}},
 		}
 		ExpectApplied(ctx, env.Client, nodePool)
 		pod := test.UnschedulablePod()
@@ -211,12 +212,12 @@ var _ = Describe("Instance Type Selection", func() {
 		ExpectInstancesWithLabel(supportedInstanceTypes(cloudProvider.CreateCalls[0]), v1.LabelOSStable, string(v1.Linux))
 	})
 	It("should schedule on one of the cheapest instances (prov zone = test-zone-2)", func() {
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelTopologyZone,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{"test-zone-2"},
-			},
+			}},
 		}
 		ExpectApplied(ctx, env.Client, nodePool)
 		pod := test.UnschedulablePod()
@@ -239,12 +240,12 @@ var _ = Describe("Instance Type Selection", func() {
 		ExpectInstancesWithLabel(supportedInstanceTypes(cloudProvider.CreateCalls[0]), v1.LabelTopologyZone, "test-zone-2")
 	})
 	It("should schedule on one of the cheapest instances (prov ct = spot)", func() {
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1beta1.CapacityTypeLabelKey,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.CapacityTypeSpot},
-			},
+			}},
 		}
 		ExpectApplied(ctx, env.Client, nodePool)
 		pod := test.UnschedulablePod()
@@ -267,17 +268,17 @@ var _ = Describe("Instance Type Selection", func() {
 		ExpectInstancesWithLabel(supportedInstanceTypes(cloudProvider.CreateCalls[0]), v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeSpot)
 	})
 	It("should schedule on one of the cheapest instances (prov ct = ondemand, prov zone = test-zone-1)", func() {
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1beta1.CapacityTypeLabelKey,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.CapacityTypeOnDemand},
-			},
-			{
+			}},
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelTopologyZone,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{"test-zone-1"},
-			},
+			}},
 		}
 		ExpectApplied(ctx, env.Client, nodePool)
 		pod := test.UnschedulablePod()
@@ -306,12 +307,12 @@ var _ = Describe("Instance Type Selection", func() {
 		ExpectInstancesWithOffering(supportedInstanceTypes(cloudProvider.CreateCalls[0]), v1beta1.CapacityTypeSpot, "test-zone-1")
 	})
 	It("should schedule on one of the cheapest instances (prov ct = spot, pod zone = test-zone-2)", func() {
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1beta1.CapacityTypeLabelKey,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.CapacityTypeSpot},
-			},
+			}},
 		}
 		ExpectApplied(ctx, env.Client, nodePool)
 		pod := test.UnschedulablePod(
@@ -326,27 +327,27 @@ var _ = Describe("Instance Type Selection", func() {
 		ExpectInstancesWithOffering(supportedInstanceTypes(cloudProvider.CreateCalls[0]), v1beta1.CapacityTypeSpot, "test-zone-2")
 	})
 	It("should schedule on one of the cheapest instances (prov ct = ondemand/test-zone-1/arm64/windows)", func() {
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureArm64},
-			},
-			{
+			}},
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelOSStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{string(v1.Windows)},
-			},
-			{
+			}},
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1beta1.CapacityTypeLabelKey,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.CapacityTypeOnDemand},
-			},
-			{
+			}},
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelTopologyZone,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{"test-zone-1"},
-			},
+			}},
 		}
 		ExpectApplied(ctx, env.Client, nodePool)
 		pod := test.UnschedulablePod()
@@ -358,17 +359,17 @@ var _ = Describe("Instance Type Selection", func() {
 		ExpectInstancesWithLabel(supportedInstanceTypes(cloudProvider.CreateCalls[0]), v1.LabelArchStable, "arm64")
 	})
 	It("should schedule on one of the cheapest instances (prov = spot/test-zone-2, pod = amd64/linux)", func() {
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureAmd64},
-			},
-			{
+			}},
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelOSStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{string(v1.Linux)},
-			},
+			}},
 		}
 		ExpectApplied(ctx, env.Client, nodePool)
 		pod := test.UnschedulablePod(
@@ -483,12 +484,12 @@ var _ = Describe("Instance Type Selection", func() {
 			return true
 		})
 
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureArm64},
-			},
+			}},
 		}
 		Expect(len(cloudProvider.InstanceTypes)).To(BeNumerically(">", 0))
 		ExpectApplied(ctx, env.Client, nodePool)
@@ -587,12 +588,12 @@ var _ = Describe("Instance Type Selection", func() {
 				},
 			}),
 		}
-		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-			{
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1beta1.CapacityTypeLabelKey,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{"on-demand"},
-			},
+			}},
 		}
 
 		ExpectApplied(ctx, env.Client, nodePool)
@@ -601,4 +602,126 @@ var _ = Describe("Instance Type Selection", func() {
 		node := ExpectScheduled(ctx, env.Client, pod)
 		Expect(node.Labels[v1.LabelInstanceTypeStable]).To(Equal("test-instance1"))
 	})
+	Context("Unavailable Offerings", func() {
+		BeforeEach(func() {
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: "test-instance1",
+					Resources: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("1"),
+						v1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+					Offerings: []cloudprovider.Offering{
+						{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 1.0, Available: true},
+					},
+				}),
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name: "test-instance2",
+					Resources: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("1"),
+						v1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+					Offerings: []cloudprovider.Offering{
+						{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 2.0, Available: true},
+					},
+				}),
+			}
+		})
+		It("should not schedule on offerings that are marked as unavailable", func() {
+			unavailableOfferings.MarkUnavailable(ctx, "test", cloudprovider.UnavailableOffering{
+				InstanceType: "test-instance1", Zone: "test-zone-1", CapacityType: v1beta1.CapacityTypeOnDemand,
+			})
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels[v1.LabelInstanceTypeStable]).To(Equal("test-instance2"))
+			Expect(supportedInstanceTypes(cloudProvider.CreateCalls[0])).To(HaveLen(1))
+		})
+		It("should not schedule if all offerings are marked as unavailable", func() {
+			unavailableOfferings.MarkUnavailable(ctx, "test",
+				cloudprovider.UnavailableOffering{InstanceType: "test-instance1", Zone: "test-zone-1", CapacityType: v1beta1.CapacityTypeOnDemand},
+				cloudprovider.UnavailableOffering{InstanceType: "test-instance2", Zone: "test-zone-1", CapacityType: v1beta1.CapacityTypeOnDemand},
+			)
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should schedule on offerings again once they are no longer marked as unavailable", func() {
+			unavailableOfferings.MarkUnavailable(ctx, "test", cloudprovider.UnavailableOffering{
+				InstanceType: "test-instance1", Zone: "test-zone-1", CapacityType: v1beta1.CapacityTypeOnDemand,
+			})
+			unavailableOfferings.Flush()
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels[v1.LabelInstanceTypeStable]).To(Equal("test-instance1"))
+		})
+	})
+	Context("MinValues", func() {
+		BeforeEach(func() {
+			cloudProvider.InstanceTypes = fake.InstanceTypes(5)
+		})
+		It("should launch with at least minValues instance types", func() {
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+				{
+					NodeSelectorRequirement: v1.NodeSelectorRequirement{
+						Key:      v1.LabelInstanceTypeStable,
+						Operator: v1.NodeSelectorOpExists,
+					},
+					MinValues: lo.ToPtr(3),
+				},
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+			Expect(len(supportedInstanceTypes(cloudProvider.CreateCalls[0]))).To(BeNumerically(">=", 3))
+			requirement, ok := lo.Find(cloudProvider.CreateCalls[0].Spec.Requirements, func(r v1beta1.NodeSelectorRequirementWithMinValues) bool {
+				return r.Key == v1.LabelInstanceTypeStable
+			})
+			Expect(ok).To(BeTrue())
+			Expect(requirement.MinValues).To(Equal(lo.ToPtr(3)))
+		})
+		It("should not schedule if the pod's resources leave fewer than minValues instance types", func() {
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+				{
+					NodeSelectorRequirement: v1.NodeSelectorRequirement{
+						Key:      v1.LabelInstanceTypeStable,
+						Operator: v1.NodeSelectorOpExists,
+					},
+					MinValues: lo.ToPtr(3),
+				},
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod(test.PodOptions{
+				ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3.5")}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should not schedule if the pod's requirements leave fewer than minValues instance types", func() {
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+				{
+					NodeSelectorRequirement: v1.NodeSelectorRequirement{
+						Key:      v1.LabelInstanceTypeStable,
+						Operator: v1.NodeSelectorOpExists,
+					},
+					MinValues: lo.ToPtr(3),
+				},
+			}
+			ExpectApplied(ctx, env.Client, nodePool)
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeRequirements: []v1.NodeSelectorRequirement{{
+					Key:      v1.LabelInstanceTypeStable,
+					Operator: v1.NodeSelectorOpIn,
+					Values:   []string{"fake-it-0", "fake-it-1"},
+				}},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+	})
 })
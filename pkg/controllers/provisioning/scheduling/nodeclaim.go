@@ -19,6 +19,7 @@ import (
 	"strings"
 	"sync/atomic"
 
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 
@@ -33,15 +34,22 @@ import (
 type NodeClaim struct {
 	NodeClaimTemplate
 
-	Pods            []*v1.Pod
-	topology        *Topology
-	hostPortUsage   *scheduling.HostPortUsage
-	daemonResources v1.ResourceList
+	Pods               []*v1.Pod
+	topology           *Topology
+	hostPortUsage      *scheduling.HostPortUsage
+	daemonResources    v1.ResourceList
+	reservationManager *ReservationManager
+	hostname           string
+
+	// remainingFraction and prices are the estimates that the BinPackers order the NodeClaim by
+	remainingFraction float64
+	prices            []instanceTypePrice
 }
 
 var nodeID int64
 
-func NewNodeClaim(nodeClaimTemplate *NodeClaimTemplate, topology *Topology, daemonResources v1.ResourceList, instanceTypes []*cloudprovider.InstanceType) *NodeClaim {
+func NewNodeClaim(nodeClaimTemplate *NodeClaimTemplate, topology *Topology, daemonResources v1.ResourceList, instanceTypes []*cloudprovider.InstanceType,
+	reservationManager *ReservationManager) *NodeClaim {
 	// Copy the template, and add hostname
 	hostname := fmt.Sprintf("hostname-placeholder-%04d", atomic.AddInt64(&nodeID, 1))
 	topology.Register(v1.LabelHostname, hostname)
@@ -52,24 +60,28 @@ func NewNodeClaim(nodeClaimTemplate *NodeClaimTemplate, topology *Topology, daem
 	template.InstanceTypeOptions = instanceTypes
 	template.Spec.Resources.Requests = daemonResources
 
-	return &NodeClaim{
-		NodeClaimTemplate: template,
-		hostPortUsage:     scheduling.NewHostPortUsage(),
-		topology:          topology,
-		daemonResources:   daemonResources,
+	n := &NodeClaim{
+		NodeClaimTemplate:  template,
+		hostPortUsage:      scheduling.NewHostPortUsage(),
+		topology:           topology,
+		daemonResources:    daemonResources,
+		reservationManager: reservationManager,
+		hostname:           hostname,
 	}
+	n.updateBinPackingEstimates()
+	return n
 }
 
 func (n *NodeClaim) Add(pod *v1.Pod) error {
 	// Check Taints
 	if err := scheduling.Taints(n.Spec.Taints).Tolerates(pod); err != nil {
-		return err
+		return newRejectionError(RejectionReasonTaints, err)
 	}
 
 	// exposed host ports on the node
 	hostPorts := scheduling.GetHostPorts(pod)
 	if err := n.hostPortUsage.Conflicts(pod, hostPorts); err != nil {
-		return fmt.Errorf("checking host port usage, %w", err)
+		return newRejectionError(RejectionReasonHostPorts, fmt.Errorf("checking host port usage, %w", err))
 	}
 
 	nodeClaimRequirements := scheduling.NewRequirements(n.Requirements.Values()...)
@@ -77,7 +89,9 @@ func (n *NodeClaim) Add(pod *v1.Pod) error {
 
 	// Check NodeClaim Affinity Requirements
 	if err := nodeClaimRequirements.Compatible(podRequirements, scheduling.AllowUndefinedWellKnownLabels); err != nil {
-		return fmt.Errorf("incompatible requirements, %w", err)
+		rejectionErr := newRejectionError(RejectionReasonRequirements, fmt.Errorf("incompatible requirements, %w", err))
+		rejectionErr.requirementKeys = nodeClaimRequirements.IncompatibleKeys(podRequirements, scheduling.AllowUndefinedWellKnownLabels)
+		return rejectionErr
 	}
 	nodeClaimRequirements.Add(podRequirements.Values()...)
 
@@ -90,10 +104,12 @@ func (n *NodeClaim) Add(pod *v1.Pod) error {
 	// Check Topology Requirements
 	topologyRequirements, err := n.topology.AddRequirements(strictPodRequirements, nodeClaimRequirements, pod, scheduling.AllowUndefinedWellKnownLabels)
 	if err != nil {
-		return err
+		return newRejectionError(RejectionReasonTopology, err)
 	}
 	if err = nodeClaimRequirements.Compatible(topologyRequirements, scheduling.AllowUndefinedWellKnownLabels); err != nil {
-		return err
+		rejectionErr := newRejectionError(RejectionReasonTopology, err)
+		rejectionErr.requirementKeys = nodeClaimRequirements.IncompatibleKeys(topologyRequirements, scheduling.AllowUndefinedWellKnownLabels)
+		return rejectionErr
 	}
 	nodeClaimRequirements.Add(topologyRequirements.Values()...)
 
@@ -103,19 +119,111 @@ func (n *NodeClaim) Add(pod *v1.Pod) error {
 	if len(filtered.remaining) == 0 {
 		// log the total resources being requested (daemonset + the pod)
 		cumulativeResources := resources.Merge(n.daemonResources, resources.RequestsForPods(pod))
-		return fmt.Errorf("no instance type satisfied resources %s and requirements %s (%s)", resources.String(cumulativeResources), nodeClaimRequirements, filtered.FailureReason())
+		rejectionErr := newRejectionError(RejectionReasonInstanceTypes, fmt.Errorf("no instance type satisfied resources %s and requirements %s (%s)",
+			resources.String(cumulativeResources), nodeClaimRequirements, filtered.FailureReason()))
+		rejectionErr.instanceTypes = &filtered.counts
+		return rejectionErr
+	}
+	// the instance types that we launch with are truncated, so we need to make sure that the truncated instance types
+	// still satisfy any minValues requirements
+	if nodeClaimRequirements.HasMinValues() {
+		if _, err = cloudprovider.InstanceTypes(filtered.remaining).Truncate(nodeClaimRequirements, MaxInstanceTypes); err != nil {
+			return newRejectionError(RejectionReasonMinValues, fmt.Errorf("no instance type combination satisfied requirements %s, %w", nodeClaimRequirements, err))
+		}
+	}
+	remaining, err := n.reserveOfferings(filtered.remaining, nodeClaimRequirements)
+	if err != nil {
+		return newRejectionError(RejectionReasonReservations, err)
 	}
 
 	// Update node
 	n.Pods = append(n.Pods, pod)
-	n.InstanceTypeOptions = filtered.remaining
+	n.InstanceTypeOptions = remaining
 	n.Spec.Resources.Requests = requests
 	n.Requirements = nodeClaimRequirements
-	n.topology.Record(pod, nodeClaimRequirements, scheduling.AllowUndefinedWellKnownLabels)
+	n.topology.Record(pod, n.Spec.Taints, nodeClaimRequirements, scheduling.AllowUndefinedWellKnownLabels)
 	n.hostPortUsage.Add(pod, hostPorts)
+	n.updateBinPackingEstimates()
 	return nil
 }
 
+// reserveOfferings fills capacity reservations before any other capacity is launched. If the NodeClaim can launch
+// into a reserved offering that has room left, it holds a reservation of that offering and is restricted to it, so that
+// the NodeClaim only counts against the reservation and the NodePool's limits that it'll actually use. If the
+// reservations that it could launch into are used up by other NodeClaims, it's restricted to the other capacity types
+// so that it doesn't launch into a reservation that it doesn't hold. The requirements are updated in place, and the
+// instance types that remain are returned. Reservations are only changed once the pod is known to fit, so that a
+// failed Add leaves the NodeClaim's reservation as it was.
+func (n *NodeClaim) reserveOfferings(instanceTypes []*cloudprovider.InstanceType, requirements scheduling.Requirements) ([]*cloudprovider.InstanceType, error) {
+	if n.reservationManager == nil {
+		return instanceTypes, nil
+	}
+	if !requirements.Get(v1beta1.CapacityTypeLabelKey).Has(v1beta1.CapacityTypeReserved) {
+		n.reservationManager.Release(n.hostname)
+		return instanceTypes, nil
+	}
+	compatible := reservedOfferings(instanceTypes, requirements)
+	if held, ok := n.reservationManager.Reserved(n.hostname); ok {
+		// The NodeClaim is restricted to the reservation that it holds
+		if !lo.Contains(compatible, held) {
+			return nil, fmt.Errorf("the reserved offering that the nodeclaim holds didn't satisfy requirements %s", requirements)
+		}
+		restrictToReservation(requirements, held)
+		return filterByReservation(instanceTypes, held), nil
+	}
+	if len(compatible) == 0 {
+		return instanceTypes, nil
+	}
+	if held, ok := n.reservationManager.Reserve(n.hostname, compatible...); ok {
+		reserved := filterByReservation(instanceTypes, held)
+		reservedRequirements := scheduling.NewRequirements(requirements.Values()...)
+		restrictToReservation(reservedRequirements, held)
+		if _, err := cloudprovider.InstanceTypes(reserved).SatisfiesMinValues(reservedRequirements); err == nil {
+			requirements.Add(reservedRequirements.Values()...)
+			return reserved, nil
+		}
+		// The reservation doesn't offer the instance type flexibility that is required, so we launch other capacity
+		n.reservationManager.Release(n.hostname)
+	}
+	requirements.Add(scheduling.NewRequirement(v1beta1.CapacityTypeLabelKey, v1.NodeSelectorOpNotIn, v1beta1.CapacityTypeReserved))
+	remaining := lo.Filter(instanceTypes, func(it *cloudprovider.InstanceType, _ int) bool { return hasOffering(it, requirements) })
+	if len(remaining) == 0 {
+		return nil, fmt.Errorf("no reservations remain for the reserved offerings that satisfied requirements %s", requirements)
+	}
+	return remaining, nil
+}
+
+// reservedOfferings returns the reservation keys of the available reserved offerings that satisfy the requirements,
+// in the order of the instance types
+func reservedOfferings(instanceTypes []*cloudprovider.InstanceType, requirements scheduling.Requirements) []string {
+	var keys []string
+	for _, it := range instanceTypes {
+		for _, o := range it.Offerings.Available() {
+			if o.CapacityType == v1beta1.CapacityTypeReserved && requirements.Get(v1.LabelTopologyZone).Has(o.Zone) {
+				keys = append(keys, reservationKey(it.Name, o.Zone))
+			}
+		}
+	}
+	return lo.Uniq(keys)
+}
+
+// restrictToReservation restricts the requirements to the capacity type and zone of the reservation
+func restrictToReservation(requirements scheduling.Requirements, key string) {
+	requirements.Add(
+		scheduling.NewRequirement(v1beta1.CapacityTypeLabelKey, v1.NodeSelectorOpIn, v1beta1.CapacityTypeReserved),
+		scheduling.NewRequirement(v1.LabelTopologyZone, v1.NodeSelectorOpIn, key[strings.LastIndex(key, "/")+1:]),
+	)
+}
+
+// filterByReservation returns the instance type of the reservation
+func filterByReservation(instanceTypes []*cloudprovider.InstanceType, key string) []*cloudprovider.InstanceType {
+	return lo.Filter(instanceTypes, func(it *cloudprovider.InstanceType, _ int) bool {
+		return lo.ContainsBy(it.Offerings.Available(), func(o cloudprovider.Offering) bool {
+			return o.CapacityType == v1beta1.CapacityTypeReserved && reservationKey(it.Name, o.Zone) == key
+		})
+	})
+}
+
 // FinalizeScheduling is called once all scheduling has completed and allows the node to perform any cleanup
 // necessary before its requirements are used for instance launching
 func (n *NodeClaim) FinalizeScheduling() {
@@ -153,6 +261,9 @@ type filterResults struct {
 	// fitsAndOffering indicates if a single instance type had enough resources and was a required offering
 	fitsAndOffering bool
 
+	// counts tracks how many instance types met each criteria
+	counts InstanceTypeCounts
+
 	requests v1.ResourceList
 }
 
@@ -230,6 +341,8 @@ func filterInstanceTypesByRequirements(instanceTypes []*cloudprovider.InstanceTy
 		requirementsAndFits:     false,
 		requirementsAndOffering: false,
 		fitsAndOffering:         false,
+
+		counts: InstanceTypeCounts{Total: len(instanceTypes)},
 	}
 	for _, it := range instanceTypes {
 		// the tradeoff to not short circuiting on the filtering is that we can report much better error messages
@@ -242,6 +355,9 @@ func filterInstanceTypesByRequirements(instanceTypes []*cloudprovider.InstanceTy
 		results.requirementsMet = results.requirementsMet || itCompat
 		results.fits = results.fits || itFits
 		results.hasOffering = results.hasOffering || itHasOffering
+		results.counts.RequirementsMet += lo.Ternary(itCompat, 1, 0)
+		results.counts.Fit += lo.Ternary(itFits, 1, 0)
+		results.counts.HasOffering += lo.Ternary(itHasOffering, 1, 0)
 
 		// track if any single instance type met the three pairs of criteria
 		results.requirementsAndFits = results.requirementsAndFits || (itCompat && itFits && !itHasOffering)
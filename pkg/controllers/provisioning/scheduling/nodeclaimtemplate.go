@@ -27,6 +27,9 @@ import (
 	"github.com/aws/karpenter-core/pkg/scheduling"
 )
 
+// MaxInstanceTypes is the maximum number of instance types that are passed as requirements when launching a NodeClaim
+const MaxInstanceTypes = 100
+
 // NodeClaimTemplate encapsulates the fields required to create a node and mirrors
 // the fields in NodePool. These structs are maintained separately in order
 // for fields like Requirements to be able to be stored more efficiently.
@@ -45,14 +48,15 @@ func NewNodeClaimTemplate(nodePool *v1beta1.NodePool) *NodeClaimTemplate {
 		Requirements:      scheduling.NewRequirements(),
 	}
 	nct.Labels = lo.Assign(nct.Labels, map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name})
-	nct.Requirements.Add(scheduling.NewNodeSelectorRequirements(nct.Spec.Requirements...).Values()...)
+	nct.Requirements.Add(scheduling.NewNodeSelectorRequirementsWithMinValues(nct.Spec.Requirements...).Values()...)
 	nct.Requirements.Add(scheduling.NewLabelRequirements(nct.Labels).Values()...)
 	return nct
 }
 
 func (i *NodeClaimTemplate) ToNodeClaim(nodePool *v1beta1.NodePool) *v1beta1.NodeClaim {
-	// Order the instance types by price and only take the first 100 of them to decrease the instance type size in the requirements
-	instanceTypes := lo.Slice(i.InstanceTypeOptions.OrderByPrice(i.Requirements), 0, 100)
+	// Order the instance types by price and only take the first MaxInstanceTypes of them to decrease the instance type size in the
+	// requirements. Scheduling has already ensured that the truncated instance types satisfy any minValues requirements.
+	instanceTypes := lo.Slice(i.InstanceTypeOptions.OrderByPrice(i.Requirements), 0, MaxInstanceTypes)
 	i.Requirements.Add(scheduling.NewRequirement(v1.LabelInstanceTypeStable, v1.NodeSelectorOpIn, lo.Map(instanceTypes, func(i *cloudprovider.InstanceType, _ int) string {
 		return i.Name
 	})...))
@@ -0,0 +1,180 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aws/karpenter-core/pkg/scheduling"
+	"github.com/aws/karpenter-core/pkg/utils/resources"
+)
+
+// canPreempt returns true if kube-scheduler may preempt lower priority pods to schedule the pod. The priority and the
+// preemption policy are populated on the pod from its PriorityClass at admission.
+func canPreempt(pod *v1.Pod) bool {
+	return lo.FromPtrOr(pod.Spec.PreemptionPolicy, v1.PreemptLowerPriority) != v1.PreemptNever
+}
+
+func podPriority(pod *v1.Pod) int32 {
+	return lo.FromPtr(pod.Spec.Priority)
+}
+
+// disruptionBudgets tracks the disruptions that PodDisruptionBudgets allow so that we only expect pods to be preempted
+// if it doesn't violate their budgets. kube-scheduler only violates budgets as a last resort, so we don't expect it to.
+type disruptionBudgets []*disruptionBudget
+
+type disruptionBudget struct {
+	namespace string
+	selector  labels.Selector
+	allowed   int32
+}
+
+func newDisruptionBudgets(ctx context.Context, kubeClient client.Client) (disruptionBudgets, error) {
+	pdbList := &policyv1.PodDisruptionBudgetList{}
+	if err := kubeClient.List(ctx, pdbList); err != nil {
+		return nil, fmt.Errorf("listing pod disruption budgets, %w", err)
+	}
+	var budgets disruptionBudgets
+	for i := range pdbList.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdbList.Items[i].Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("parsing selector of pod disruption budget %s, %w", client.ObjectKeyFromObject(&pdbList.Items[i]), err)
+		}
+		budgets = append(budgets, &disruptionBudget{
+			namespace: pdbList.Items[i].Namespace,
+			selector:  selector,
+			allowed:   pdbList.Items[i].Status.DisruptionsAllowed,
+		})
+	}
+	return budgets, nil
+}
+
+// allow returns true if the pods can all be disrupted without violating any budget
+func (d disruptionBudgets) allow(pods ...*v1.Pod) bool {
+	for _, budget := range d {
+		if int32(lo.CountBy(pods, budget.matches)) > budget.allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// disrupt consumes the disruptions of the budgets that the pods match
+func (d disruptionBudgets) disrupt(pods ...*v1.Pod) {
+	for _, budget := range d {
+		budget.allowed -= int32(lo.CountBy(pods, budget.matches))
+	}
+}
+
+func (d *disruptionBudget) matches(pod *v1.Pod) bool {
+	return pod.Namespace == d.namespace && d.selector.Matches(labels.Set(pod.Labels))
+}
+
+// preemptionVictims returns the pods that kube-scheduler is expected to preempt so that the pod fits on the node, or an
+// error if preempting doesn't make room for it. Like kube-scheduler, only pods with a lower priority are considered and
+// the victims are minimized by reprieving the pods with the highest priority first. Only resources are checked here;
+// the node must still be able to preempt the victims for the pod.
+func (n *ExistingNode) preemptionVictims(ctx context.Context, kubeClient client.Client, pod *v1.Pod, budgets disruptionBudgets) ([]*v1.Pod, error) {
+	// kube-scheduler only preempts pods that are bound to nodes that it can schedule to
+	if n.Node == nil || !n.Initialized() {
+		return nil, fmt.Errorf("node is not initialized")
+	}
+	if err := scheduling.Taints(n.Taints()).Tolerates(pod); err != nil {
+		return nil, err
+	}
+	if err := n.requirements.Compatible(scheduling.NewPodRequirements(pod)); err != nil {
+		return nil, err
+	}
+	if n.boundPods == nil {
+		pods, err := n.StateNode.Pods(ctx, kubeClient)
+		if err != nil {
+			return nil, fmt.Errorf("getting node pods, %w", err)
+		}
+		n.boundPods = lo.Ternary(pods == nil, []*v1.Pod{}, pods)
+	}
+	candidates := lo.Filter(n.boundPods, func(p *v1.Pod, _ int) bool {
+		return podPriority(p) < podPriority(pod) && !lo.Contains(n.victims, p) && budgets.allow(p)
+	})
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no lower priority pods to preempt")
+	}
+	requests := resources.Merge(n.requests, resources.RequestsForPods(pod))
+	available := n.available()
+	if resources.Fits(requests, available) {
+		return nil, fmt.Errorf("fits without preempting pods")
+	}
+	if !resources.Fits(requests, resources.Merge(available, resources.RequestsForPods(candidates...))) {
+		return nil, fmt.Errorf("exceeds node resources after preempting lower priority pods")
+	}
+	// reprieve the most important pods first, and preempt them only if the pod doesn't fit without preempting them
+	sort.SliceStable(candidates, func(a, b int) bool {
+		if podPriority(candidates[a]) != podPriority(candidates[b]) {
+			return podPriority(candidates[a]) > podPriority(candidates[b])
+		}
+		return candidates[a].CreationTimestamp.Before(&candidates[b].CreationTimestamp)
+	})
+	var victims []*v1.Pod
+	for i, candidate := range candidates {
+		if !resources.Fits(requests, resources.Merge(available, resources.RequestsForPods(victims...), resources.RequestsForPods(candidates[i+1:]...))) {
+			victims = append(victims, candidate)
+		}
+	}
+	if !budgets.allow(victims...) {
+		return nil, fmt.Errorf("preempting %d pods violates pod disruption budgets", len(victims))
+	}
+	return victims, nil
+}
+
+// preempt adds the pod to the node, assuming that the victims are preempted to make room for it
+func (n *ExistingNode) preempt(ctx context.Context, kubeClient client.Client, pod *v1.Pod, victims []*v1.Pod) error {
+	previous := n.victims
+	n.victims = append(n.victims, victims...)
+	if err := n.Add(ctx, kubeClient, pod); err != nil {
+		n.victims = previous
+		return err
+	}
+	return nil
+}
+
+// preemption is the preemption of pods on an existing node that the scheduler expects a pod to cause
+type preemption struct {
+	node    *ExistingNode
+	victims []*v1.Pod
+}
+
+// lessDisruptive orders preemptions the way that kube-scheduler picks the node to preempt pods on: the lowest highest
+// victim priority first, then the lowest sum of victim priorities, and then the fewest victims
+func lessDisruptive(a, b preemption) bool {
+	maxA, maxB := lo.Max(lo.Map(a.victims, func(p *v1.Pod, _ int) int32 { return podPriority(p) })),
+		lo.Max(lo.Map(b.victims, func(p *v1.Pod, _ int) int32 { return podPriority(p) }))
+	if maxA != maxB {
+		return maxA < maxB
+	}
+	sumA, sumB := lo.SumBy(a.victims, func(p *v1.Pod) int64 { return int64(podPriority(p)) }),
+		lo.SumBy(b.victims, func(p *v1.Pod) int64 { return int64(podPriority(p)) })
+	if sumA != sumB {
+		return sumA < sumB
+	}
+	return len(a.victims) < len(b.victims)
+}
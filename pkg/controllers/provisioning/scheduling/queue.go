@@ -15,8 +15,6 @@ limitations under the License.
 package scheduling
 
 import (
-	"sort"
-
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -31,9 +29,10 @@ type Queue struct {
 	lastLen map[types.UID]int
 }
 
-// NewQueue constructs a new queue given the input pods, sorting them to optimize for bin-packing into nodes.
-func NewQueue(pods ...*v1.Pod) *Queue {
-	sort.Slice(pods, byCPUAndMemoryDescending(pods))
+// NewQueue constructs a new queue given the input pods, sorting them with the BinPacker to optimize for bin-packing
+// into nodes.
+func NewQueue(binPacker BinPacker, pods ...*v1.Pod) *Queue {
+	binPacker.SortPods(pods)
 	return &Queue{
 		pods:    pods,
 		lastLen: map[types.UID]int{},
@@ -67,6 +66,12 @@ func (q *Queue) Push(pod *v1.Pod, relaxed bool) {
 	}
 }
 
+// Add appends pods that weren't in the queue, which is progress as the pods haven't been tried yet
+func (q *Queue) Add(pods ...*v1.Pod) {
+	q.pods = append(q.pods, pods...)
+	q.lastLen = map[types.UID]int{}
+}
+
 func (q *Queue) List() []*v1.Pod {
 	return q.pods
 }
@@ -0,0 +1,91 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"fmt"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
+)
+
+// ReservationManager tracks the reservations that the NodeClaims of a scheduling simulation hold against the reserved
+// offerings of the instance types, so that we never schedule more NodeClaims into a reservation than it has room for.
+// A NodeClaim holds at most one reservation, since it launches into a single offering.
+type ReservationManager struct {
+	remaining    map[string]int    // reservation key -> remaining reservations
+	reservations map[string]string // hostname -> reservation key held by the NodeClaim
+}
+
+// NewReservationManager returns a ReservationManager for the reserved offerings of the passed instance types.
+// Reservations are shared by every NodePool that can launch the instance type.
+func NewReservationManager(instanceTypes map[string][]*cloudprovider.InstanceType) *ReservationManager {
+	rm := &ReservationManager{
+		remaining:    map[string]int{},
+		reservations: map[string]string{},
+	}
+	for _, its := range instanceTypes {
+		for _, it := range its {
+			for _, o := range it.Offerings {
+				if o.CapacityType == v1beta1.CapacityTypeReserved && o.Available {
+					rm.remaining[reservationKey(it.Name, o.Zone)] = o.ReservationCapacity
+				}
+			}
+		}
+	}
+	return rm
+}
+
+// Reserve reserves the first of the passed reservations that has room left for the NodeClaim with the hostname, and
+// returns it. If the NodeClaim already holds a reservation, that reservation is returned instead.
+func (rm *ReservationManager) Reserve(hostname string, keys ...string) (string, bool) {
+	if held, ok := rm.reservations[hostname]; ok {
+		return held, true
+	}
+	for _, key := range keys {
+		if rm.remaining[key] <= 0 {
+			continue
+		}
+		rm.remaining[key]--
+		rm.reservations[hostname] = key
+		return key, true
+	}
+	return "", false
+}
+
+// Release releases the reservation of the NodeClaim with the hostname, if it holds one
+func (rm *ReservationManager) Release(hostname string) {
+	held, ok := rm.reservations[hostname]
+	if !ok {
+		return
+	}
+	rm.remaining[held]++
+	delete(rm.reservations, hostname)
+}
+
+// Reserved returns the reservation that is held by the NodeClaim with the hostname
+func (rm *ReservationManager) Reserved(hostname string) (string, bool) {
+	held, ok := rm.reservations[hostname]
+	return held, ok
+}
+
+// Remaining returns the number of reservations of an offering that aren't held by any NodeClaim
+func (rm *ReservationManager) Remaining(instanceType, zone string) int {
+	return rm.remaining[reservationKey(instanceType, zone)]
+}
+
+func reservationKey(instanceType, zone string) string {
+	return fmt.Sprintf("%s/%s", instanceType, zone)
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/aws/karpenter-core/pkg/controllers/state"
 	"github.com/aws/karpenter-core/pkg/events"
 	"github.com/aws/karpenter-core/pkg/metrics"
+	"github.com/aws/karpenter-core/pkg/operator/options"
 	"github.com/aws/karpenter-core/pkg/scheduling"
 	"github.com/aws/karpenter-core/pkg/utils/pod"
 	"github.com/aws/karpenter-core/pkg/utils/pretty"
@@ -47,7 +48,7 @@ type SchedulerOptions struct {
 func NewScheduler(ctx context.Context, kubeClient client.Client, nodeClaimTemplates []*NodeClaimTemplate,
 	nodePools []v1beta1.NodePool, cluster *state.Cluster, stateNodes []*state.StateNode, topology *Topology,
 	instanceTypes map[string][]*cloudprovider.InstanceType, daemonSetPods []*v1.Pod,
-	recorder events.Recorder, opts SchedulerOptions) *Scheduler {
+	unavailableOfferings *cloudprovider.UnavailableOfferings, recorder events.Recorder, opts SchedulerOptions) *Scheduler {
 
 	// if any of the nodePools add a taint with a prefer no schedule effect, we add a toleration for the taint
 	// during preference relaxation
@@ -66,16 +67,27 @@ func NewScheduler(ctx context.Context, kubeClient client.Client, nodeClaimTempla
 		nodeClaimTemplates: nodeClaimTemplates,
 		topology:           topology,
 		cluster:            cluster,
-		instanceTypes:      instanceTypes,
+		instanceTypes:      map[string][]*cloudprovider.InstanceType{},
 		daemonOverhead:     getDaemonOverhead(nodeClaimTemplates, daemonSetPods),
 		recorder:           recorder,
 		opts:               opts,
 		preferences:        &Preferences{ToleratePreferNoSchedule: toleratePreferNoSchedule},
 		remainingResources: map[string]v1.ResourceList{},
+		kubeletConfigs:     map[string]*v1beta1.KubeletConfiguration{},
+		decisions:          map[*v1.Pod]*Decision{},
 	}
 	for _, nodePool := range nodePools {
 		s.remainingResources[nodePool.Name] = v1.ResourceList(nodePool.Spec.Limits)
+		s.kubeletConfigs[nodePool.Name] = nodePool.Spec.Template.Spec.Kubelet
 	}
+	// Offerings that recently failed to launch due to insufficient capacity are treated as unavailable so that we
+	// don't continually select the same offerings until they expire from the cache. The allocatable of the instance
+	// types is computed from the kubelet configuration of the NodePool, as that's what the launched nodes will report.
+	for nodePoolName, its := range instanceTypes {
+		s.instanceTypes[nodePoolName] = cloudprovider.ApplyKubeletConfiguration(unavailableOfferings.Apply(its), s.kubeletConfigs[nodePoolName])
+	}
+	s.reservationManager = NewReservationManager(s.instanceTypes)
+	s.binPacker, s.nodePoolBinPackers = binPackers(ctx, nodePools)
 	s.calculateExistingNodeClaims(stateNodes, daemonSetPods)
 	return s
 }
@@ -88,7 +100,14 @@ type Scheduler struct {
 	remainingResources map[string]v1.ResourceList               // (NodePool name) -> remaining resources for that NodePool
 	instanceTypes      map[string][]*cloudprovider.InstanceType // (NodePool name) -> instance types for NodePool
 	daemonOverhead     map[*NodeClaimTemplate]v1.ResourceList
+	kubeletConfigs     map[string]*v1beta1.KubeletConfiguration // (NodePool name) -> kubelet configuration for NodePool
+	reservationManager *ReservationManager
+	binPacker          BinPacker
+	nodePoolBinPackers map[string]BinPacker // (NodePool name) -> bin-packer that overrides the operator-wide one
 	preferences        *Preferences
+	decisions          map[*v1.Pod]*Decision
+	budgets            disruptionBudgets // listed when a pod first tries to preempt pods
+	preempted          []*v1.Pod         // pods that are expected to be preempted and need to be rescheduled
 	topology           *Topology
 	cluster            *state.Cluster
 	recorder           events.Recorder
@@ -101,6 +120,7 @@ type Results struct {
 	NewNodeClaims []*NodeClaim
 	ExistingNodes []*ExistingNode
 	PodErrors     map[*v1.Pod]error
+	PodDecisions  map[*v1.Pod]*Decision
 }
 
 // AllNonPendingPodsScheduled returns true if all pods scheduled.
@@ -138,13 +158,43 @@ func (r Results) NonPendingPodSchedulingErrors() string {
 func (s *Scheduler) Solve(ctx context.Context, pods []*v1.Pod) *Results {
 	defer metrics.Measure(schedulingSimulationDuration)()
 	schedulingStart := time.Now()
+	errors := map[*v1.Pod]error{}
+	// the headroom of the NodePools is scheduled after the pending pods so that it never takes capacity from them
+	q := NewQueue(s.binPacker, lo.Reject(pods, func(p *v1.Pod, _ int) bool { return pod.IsHeadroom(p) })...)
+	s.solve(ctx, q, errors)
+	headroom := NewQueue(s.binPacker, lo.Filter(pods, func(p *v1.Pod, _ int) bool { return pod.IsHeadroom(p) })...)
+	s.solve(ctx, headroom, errors)
+
+	for _, m := range s.newNodeClaims {
+		m.FinalizeScheduling()
+	}
+	if !s.opts.SimulationMode {
+		s.recordSchedulingResults(ctx, pods, append(q.List(), headroom.List()...), errors, time.Since(schedulingStart))
+	}
+	// clear any nil errors so we can know that len(PodErrors) == 0 => all pods scheduled
+	for k, v := range errors {
+		if v == nil {
+			delete(errors, k)
+		}
+	}
+	for p, decision := range s.decisions {
+		decision.Scheduled = errors[p] == nil
+	}
+	return &Results{
+		NewNodeClaims: s.newNodeClaims,
+		ExistingNodes: s.existingNodes,
+		PodErrors:     errors,
+		PodDecisions:  s.decisions,
+	}
+}
+
+// solve schedules the pods in the queue for as long as it's making progress, recording the error of each attempt
+func (s *Scheduler) solve(ctx context.Context, q *Queue, errors map[*v1.Pod]error) {
 	// We loop trying to schedule unschedulable pods as long as we are making progress.  This solves a few
 	// issues including pods with affinity to another pod in the batch. We could topo-sort to solve this, but it wouldn't
 	// solve the problem of scheduling pods where a particular order is needed to prevent a max-skew violation. E.g. if we
 	// had 5xA pods and 5xB pods were they have a zonal topology spread, but A can only go in one zone and B in another.
 	// We need to schedule them alternating, A, B, A, B, .... and this solution also solves that as well.
-	errors := map[*v1.Pod]error{}
-	q := NewQueue(pods...)
 	for {
 		// Try the next pod
 		pod, ok := q.Pop()
@@ -154,51 +204,50 @@ func (s *Scheduler) Solve(ctx context.Context, pods []*v1.Pod) *Results {
 
 		// Schedule to existing nodes or create a new node
 		if errors[pod] = s.add(ctx, pod); errors[pod] == nil {
+			// the pods that we expect to be preempted for the pod need to schedule elsewhere
+			for _, p := range s.preempted {
+				if err := s.topology.Update(ctx, p); err != nil {
+					logging.FromContext(ctx).Errorf("updating topology, %s", err)
+				}
+			}
+			q.Add(s.preempted...)
+			s.preempted = nil
 			continue
 		}
 
 		// If unsuccessful, relax the pod and recompute topology
 		relaxed := s.preferences.Relax(ctx, pod)
-		q.Push(pod, relaxed)
-		if relaxed {
+		q.Push(pod, relaxed != nil)
+		if relaxed != nil {
+			s.decisions[pod].RelaxedPreferences = append(s.decisions[pod].RelaxedPreferences, *relaxed)
 			if err := s.topology.Update(ctx, pod); err != nil {
 				logging.FromContext(ctx).Errorf("updating topology, %s", err)
 			}
 		}
 	}
-
-	for _, m := range s.newNodeClaims {
-		m.FinalizeScheduling()
-	}
-	if !s.opts.SimulationMode {
-		s.recordSchedulingResults(ctx, pods, q.List(), errors, time.Since(schedulingStart))
-	}
-	// clear any nil errors so we can know that len(PodErrors) == 0 => all pods scheduled
-	for k, v := range errors {
-		if v == nil {
-			delete(errors, k)
-		}
-	}
-	return &Results{
-		NewNodeClaims: s.newNodeClaims,
-		ExistingNodes: s.existingNodes,
-		PodErrors:     errors,
-	}
 }
 
 func (s *Scheduler) recordSchedulingResults(ctx context.Context, pods []*v1.Pod, failedToSchedule []*v1.Pod, errors map[*v1.Pod]error, schedulingDuration time.Duration) {
 	// Report failures and nominations
-	for _, pod := range failedToSchedule {
-		logging.FromContext(ctx).With("pod", client.ObjectKeyFromObject(pod)).Errorf("Could not schedule pod, %s", errors[pod])
-		s.recorder.Publish(PodFailedToScheduleEvent(pod, errors[pod]))
+	for _, p := range failedToSchedule {
+		// headroom pods don't exist in the cluster, so there's nothing to publish events for
+		if pod.IsHeadroom(p) {
+			logging.FromContext(ctx).With("nodepool", p.Annotations[v1beta1.HeadroomAnnotationKey]).Errorf("Could not schedule headroom, %s", errors[p])
+			continue
+		}
+		logging.FromContext(ctx).With("pod", client.ObjectKeyFromObject(p)).Errorf("Could not schedule pod, %s", errors[p])
+		s.recorder.Publish(PodFailedToScheduleEvent(p, errors[p]))
 	}
 
 	for _, existing := range s.existingNodes {
-		if len(existing.Pods) > 0 {
+		// nodes are only nominated for pods that will bind to them, as a nomination for the headroom of a NodePool
+		// would keep its nodes from ever being disrupted
+		pods := lo.Reject(existing.Pods, func(p *v1.Pod, _ int) bool { return pod.IsHeadroom(p) })
+		if len(pods) > 0 {
 			s.cluster.NominateNodeForPod(ctx, existing.ProviderID())
 		}
-		for _, pod := range existing.Pods {
-			s.recorder.Publish(NominatePodEvent(pod, existing.Node, existing.NodeClaim))
+		for _, p := range pods {
+			s.recorder.Publish(NominatePodEvent(p, existing.Node, existing.NodeClaim))
 		}
 	}
 
@@ -234,19 +283,38 @@ func (s *Scheduler) recordSchedulingResults(ctx context.Context, pods []*v1.Pod,
 }
 
 func (s *Scheduler) add(ctx context.Context, pod *v1.Pod) error {
+	// only the outcome of the last attempt is recorded, as the pod may have been relaxed since the previous attempt,
+	// while the relaxed preferences accumulate across attempts
+	decision := &Decision{}
+	if previous, ok := s.decisions[pod]; ok {
+		decision.RelaxedPreferences = previous.RelaxedPreferences
+	}
+	s.decisions[pod] = decision
+
 	// first try to schedule against an in-flight real node
+	sortByNodePool(s.existingNodes, func(n *ExistingNode) string { return n.Labels()[v1beta1.NodePoolLabelKey] },
+		s.binPacker, s.nodePoolBinPackers, func(b BinPacker, nodes []*ExistingNode) { b.SortExistingNodes(pod, nodes) })
 	for _, node := range s.existingNodes {
 		if err := node.Add(ctx, s.kubeClient, pod); err == nil {
+			decision.Node = node.Name()
 			return nil
 		}
 	}
 
-	// Consider using https://pkg.go.dev/container/heap
-	sort.Slice(s.newNodeClaims, func(a, b int) bool { return len(s.newNodeClaims[a].Pods) < len(s.newNodeClaims[b].Pods) })
+	// kube-scheduler preempts lower priority pods if the pod doesn't fit on any node, which happens well before a node
+	// that we launch for the pod would be ready
+	if node, victims, ok := s.preempt(ctx, pod); ok {
+		decision.Node = node.Name()
+		decision.Preempted = lo.Map(victims, func(p *v1.Pod, _ int) string { return client.ObjectKeyFromObject(p).String() })
+		return nil
+	}
 
 	// Pick existing node that we are about to create
+	sortByNodePool(s.newNodeClaims, func(n *NodeClaim) string { return n.NodePoolName },
+		s.binPacker, s.nodePoolBinPackers, func(b BinPacker, nodeClaims []*NodeClaim) { b.SortNodeClaims(pod, nodeClaims) })
 	for _, nodeClaim := range s.newNodeClaims {
 		if err := nodeClaim.Add(pod); err == nil {
+			decision.NodePool = nodeClaim.NodePoolName
 			return nil
 		}
 	}
@@ -259,29 +327,71 @@ func (s *Scheduler) add(ctx context.Context, pod *v1.Pod) error {
 		if remaining, ok := s.remainingResources[nodeClaimTemplate.NodePoolName]; ok {
 			instanceTypes = filterByRemainingResources(s.instanceTypes[nodeClaimTemplate.NodePoolName], remaining)
 			if len(instanceTypes) == 0 {
-				errs = multierr.Append(errs, fmt.Errorf("all available instance types exceed limits for nodepool: %q", nodeClaimTemplate.NodePoolName))
+				err := fmt.Errorf("all available instance types exceed limits for nodepool: %q", nodeClaimTemplate.NodePoolName)
+				errs = multierr.Append(errs, err)
+				decision.Rejections = append(decision.Rejections, newRejection(nodeClaimTemplate.NodePoolName, s.daemonOverhead[nodeClaimTemplate],
+					newRejectionError(RejectionReasonLimits, err)))
 				continue
 			} else if len(s.instanceTypes[nodeClaimTemplate.NodePoolName]) != len(instanceTypes) && !s.opts.SimulationMode {
 				logging.FromContext(ctx).With("nodepool", nodeClaimTemplate.NodePoolName).Debugf("%d out of %d instance types were excluded because they would breach limits",
 					len(s.instanceTypes[nodeClaimTemplate.NodePoolName])-len(instanceTypes), len(s.instanceTypes[nodeClaimTemplate.NodePoolName]))
 			}
 		}
-		nodeClaim := NewNodeClaim(nodeClaimTemplate, s.topology, s.daemonOverhead[nodeClaimTemplate], instanceTypes)
+		nodeClaim := NewNodeClaim(nodeClaimTemplate, s.topology, s.daemonOverhead[nodeClaimTemplate], instanceTypes, s.reservationManager)
 		if err := nodeClaim.Add(pod); err != nil {
 			errs = multierr.Append(errs, fmt.Errorf("incompatible with nodepool %q, daemonset overhead=%s, %w",
 				nodeClaimTemplate.NodePoolName,
 				resources.String(s.daemonOverhead[nodeClaimTemplate]),
 				err))
+			decision.Rejections = append(decision.Rejections, newRejection(nodeClaimTemplate.NodePoolName, s.daemonOverhead[nodeClaimTemplate], err))
 			continue
 		}
 		// we will launch this nodeClaim and need to track its maximum possible resource usage against our remaining resources
 		s.newNodeClaims = append(s.newNodeClaims, nodeClaim)
 		s.remainingResources[nodeClaimTemplate.NodePoolName] = subtractMax(s.remainingResources[nodeClaimTemplate.NodePoolName], nodeClaim.InstanceTypeOptions)
+		decision.NodePool = nodeClaimTemplate.NodePoolName
 		return nil
 	}
 	return errs
 }
 
+// preempt adds the pod to the existing node that kube-scheduler is expected to preempt pods on to schedule the pod,
+// preferring the node where the least important pods are preempted. The preempted pods are rescheduled unless their
+// priority is below the expendable pods priority cutoff, in which case they don't warrant new capacity.
+func (s *Scheduler) preempt(ctx context.Context, pod *v1.Pod) (*ExistingNode, []*v1.Pod, bool) {
+	// consolidation doesn't preempt, as the pods that it reschedules must fit without displacing other pods
+	if s.opts.SimulationMode || s.kubeClient == nil || !canPreempt(pod) {
+		return nil, nil, false
+	}
+	if s.budgets == nil {
+		budgets, err := newDisruptionBudgets(ctx, s.kubeClient)
+		if err != nil {
+			logging.FromContext(ctx).Errorf("simulating preemption, %s", err)
+			return nil, nil, false
+		}
+		s.budgets = lo.Ternary(budgets == nil, disruptionBudgets{}, budgets)
+	}
+	var preemptions []preemption
+	for _, node := range s.existingNodes {
+		if victims, err := node.preemptionVictims(ctx, s.kubeClient, pod, s.budgets); err == nil {
+			preemptions = append(preemptions, preemption{node: node, victims: victims})
+		}
+	}
+	sort.SliceStable(preemptions, func(a, b int) bool { return lessDisruptive(preemptions[a], preemptions[b]) })
+	for _, p := range preemptions {
+		if err := p.node.preempt(ctx, s.kubeClient, pod, p.victims); err != nil {
+			continue
+		}
+		s.budgets.disrupt(p.victims...)
+		s.preempted = append(s.preempted, lo.Filter(p.victims, func(victim *v1.Pod, _ int) bool {
+			return podPriority(victim) >= int32(options.FromContext(ctx).ExpendablePodsPriorityCutoff)
+		})...)
+		logging.FromContext(ctx).With("pod", client.ObjectKeyFromObject(pod), "node", p.node.Name()).Debugf("expecting pod to preempt %d lower priority pod(s)", len(p.victims))
+		return p.node, p.victims, true
+	}
+	return nil, nil, false
+}
+
 func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, daemonSetPods []*v1.Pod) {
 	// create our existing nodes
 	for _, node := range stateNodes {
@@ -296,7 +406,7 @@ func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, d
 			}
 			daemons = append(daemons, p)
 		}
-		s.existingNodes = append(s.existingNodes, NewExistingNode(node, s.topology, resources.RequestsForPods(daemons...)))
+		s.existingNodes = append(s.existingNodes, NewExistingNode(node, s.topology, resources.RequestsForPods(daemons...), s.inflightAllocatable(node)))
 
 		// We don't use the status field and instead recompute the remaining resources to ensure we have a consistent view
 		// of the cluster during scheduling.  Depending on how node creation falls out, this will also work for cases where
@@ -319,6 +429,22 @@ func (s *Scheduler) calculateExistingNodeClaims(stateNodes []*state.StateNode, d
 	})
 }
 
+// inflightAllocatable returns the allocatable that an in-flight node is expected to have once kubelet reports it, based
+// on the kubelet configuration of its NodePool, or nil if the node is initialized, its NodePool doesn't configure
+// kubelet or its instance type isn't known
+func (s *Scheduler) inflightAllocatable(node *state.StateNode) v1.ResourceList {
+	if node.Initialized() || s.kubeletConfigs[node.Labels()[v1beta1.NodePoolLabelKey]] == nil {
+		return nil
+	}
+	instanceType, ok := lo.Find(s.instanceTypes[node.Labels()[v1beta1.NodePoolLabelKey]], func(it *cloudprovider.InstanceType) bool {
+		return it.Name == node.Labels()[v1.LabelInstanceTypeStable]
+	})
+	if !ok {
+		return nil
+	}
+	return instanceType.Allocatable()
+}
+
 func getDaemonOverhead(nodeClaimTemplates []*NodeClaimTemplate, daemonSetPods []*v1.Pod) map[*NodeClaimTemplate]v1.ResourceList {
 	overhead := map[*NodeClaimTemplate]v1.ResourceList{}
 
@@ -26,9 +26,12 @@ import (
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	nodev1 "k8s.io/api/node/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	cloudproviderapi "k8s.io/cloud-provider/api"
@@ -65,6 +68,7 @@ var env *test.Environment
 var fakeClock *clock.FakeClock
 var cluster *state.Cluster
 var cloudProvider *fake.CloudProvider
+var unavailableOfferings *cloudprovider.UnavailableOfferings
 var nodeStateController controller.Controller
 var nodeClaimStateController controller.Controller
 var podStateController controller.Controller
@@ -89,7 +93,8 @@ var _ = BeforeSuite(func() {
 	nodeStateController = informer.NewNodeController(env.Client, cluster)
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cluster)
 	podStateController = informer.NewPodController(env.Client, cluster)
-	prov = provisioning.NewProvisioner(env.Client, env.KubernetesInterface.CoreV1(), events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster)
+	unavailableOfferings = cloudprovider.NewUnavailableOfferings()
+	prov = provisioning.NewProvisioner(env.Client, env.KubernetesInterface.CoreV1(), env.KubernetesInterface.NodeV1(), events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, unavailableOfferings)
 })
 
 var _ = AfterSuite(func() {
@@ -101,6 +106,7 @@ var _ = BeforeEach(func() {
 	newCP := fake.CloudProvider{}
 	cloudProvider.InstanceTypes, _ = newCP.GetInstanceTypes(ctx, nil)
 	cloudProvider.CreateCalls = nil
+	unavailableOfferings.Flush()
 	pscheduling.ResetDefaultStorageClass()
 })
 
@@ -116,12 +122,12 @@ var _ = Context("NodePool", func() {
 			Spec: v1beta1.NodePoolSpec{
 				Template: v1beta1.NodeClaimTemplate{
 					Spec: v1beta1.NodeClaimSpec{
-						Requirements: []v1.NodeSelectorRequirement{
-							{
+						Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{
+							{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 								Key:      v1beta1.CapacityTypeLabelKey,
 								Operator: v1.NodeSelectorOpIn,
 								Values:   []string{v1beta1.CapacityTypeSpot, v1beta1.CapacityTypeOnDemand},
-							},
+							}},
 						},
 					},
 				},
@@ -182,8 +188,8 @@ var _ = Context("NodePool", func() {
 		})
 		Context("Well Known Labels", func() {
 			It("should use NodePool constraints", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod()
 				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
@@ -191,8 +197,8 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-2"))
 			})
 			It("should use node selectors", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeSelector: map[string]string{v1.LabelTopologyZone: "test-zone-2"}},
@@ -210,8 +216,8 @@ var _ = Context("NodePool", func() {
 				ExpectNotScheduled(ctx, env.Client, pod)
 			})
 			It("should not schedule the pod if nodeselector unknown", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeSelector: map[string]string{v1.LabelTopologyZone: "unknown"}},
@@ -220,8 +226,8 @@ var _ = Context("NodePool", func() {
 				ExpectNotScheduled(ctx, env.Client, pod)
 			})
 			It("should not schedule if node selector outside of NodePool constraints", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeSelector: map[string]string{v1.LabelTopologyZone: "test-zone-2"}},
@@ -241,9 +247,9 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-3"))
 			})
 			It("should schedule compatible requirements with Operator=Gt", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{{
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 					Key: fake.IntegerInstanceLabelKey, Operator: v1.NodeSelectorOpGt, Values: []string{"8"},
-				}}
+				}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod()
 				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
@@ -251,9 +257,9 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue(fake.IntegerInstanceLabelKey, "16"))
 			})
 			It("should schedule compatible requirements with Operator=Lt", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{{
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 					Key: fake.IntegerInstanceLabelKey, Operator: v1.NodeSelectorOpLt, Values: []string{"8"},
-				}}
+				}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod()
 				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
@@ -409,9 +415,9 @@ var _ = Context("NodePool", func() {
 				}
 			})
 			It("should schedule pods that have node selectors with label in restricted domains exceptions list", func() {
-				var requirements []v1.NodeSelectorRequirement
+				var requirements []v1beta1.NodeSelectorRequirementWithMinValues
 				for domain := range v1beta1.LabelDomainExceptions {
-					requirements = append(requirements, v1.NodeSelectorRequirement{Key: domain + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}})
+					requirements = append(requirements, v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: domain + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}})
 				}
 				nodePool.Spec.Template.Spec.Requirements = requirements
 				ExpectApplied(ctx, env.Client, nodePool)
@@ -423,9 +429,9 @@ var _ = Context("NodePool", func() {
 				}
 			})
 			It("should schedule pods that have node selectors with label in subdomain from restricted domains exceptions list", func() {
-				var requirements []v1.NodeSelectorRequirement
+				var requirements []v1beta1.NodeSelectorRequirementWithMinValues
 				for domain := range v1beta1.LabelDomainExceptions {
-					requirements = append(requirements, v1.NodeSelectorRequirement{Key: "subdomain." + domain + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}})
+					requirements = append(requirements, v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "subdomain." + domain + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}})
 				}
 				nodePool.Spec.Template.Spec.Requirements = requirements
 				ExpectApplied(ctx, env.Client, nodePool)
@@ -496,8 +502,8 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).ToNot(HaveKey("test-key"))
 			})
 			It("should schedule unconstrained pods that don't have matching node selectors", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod()
 				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
@@ -505,8 +511,8 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue("test-key", "test-value"))
 			})
 			It("should schedule pods that have node selectors with matching value and In operator", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -517,8 +523,8 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue("test-key", "test-value"))
 			})
 			It("should not schedule pods that have node selectors with matching value and NotIn operator", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -528,8 +534,8 @@ var _ = Context("NodePool", func() {
 				ExpectNotScheduled(ctx, env.Client, pod)
 			})
 			It("should schedule the pod with Exists operator and defined key", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -540,8 +546,8 @@ var _ = Context("NodePool", func() {
 				ExpectScheduled(ctx, env.Client, pod)
 			})
 			It("should not schedule the pod with DoesNotExists operator and defined key", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -552,8 +558,8 @@ var _ = Context("NodePool", func() {
 				ExpectNotScheduled(ctx, env.Client, pod)
 			})
 			It("should not schedule pods that have node selectors with different value and In operator", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -563,8 +569,8 @@ var _ = Context("NodePool", func() {
 				ExpectNotScheduled(ctx, env.Client, pod)
 			})
 			It("should schedule pods that have node selectors with different value and NotIn operator", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -575,8 +581,8 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue("test-key", "test-value"))
 			})
 			It("should schedule compatible pods to the same node", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value", "another-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value", "another-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pods := []*v1.Pod{
 					test.UnschedulablePod(
@@ -595,8 +601,8 @@ var _ = Context("NodePool", func() {
 				Expect(node1.Name).To(Equal(node2.Name))
 			})
 			It("should schedule incompatible pods to the different node", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value", "another-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value", "another-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pods := []*v1.Pod{
 					test.UnschedulablePod(
@@ -628,8 +634,8 @@ var _ = Context("NodePool", func() {
 		})
 		Context("Well Known Labels", func() {
 			It("should use NodePool constraints", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-2"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod()
 				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
@@ -637,8 +643,8 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-2"))
 			})
 			It("should use node selectors", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeSelector: map[string]string{v1.LabelTopologyZone: "test-zone-2"}},
@@ -656,8 +662,8 @@ var _ = Context("NodePool", func() {
 				ExpectNotScheduled(ctx, env.Client, pod)
 			})
 			It("should not schedule the pod if nodeselector unknown", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeSelector: map[string]string{v1.LabelTopologyZone: "unknown"}},
@@ -666,8 +672,8 @@ var _ = Context("NodePool", func() {
 				ExpectNotScheduled(ctx, env.Client, pod)
 			})
 			It("should not schedule if node selector outside of NodePool constraints", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeSelector: map[string]string{v1.LabelTopologyZone: "test-zone-2"}},
@@ -687,9 +693,9 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-3"))
 			})
 			It("should schedule compatible requirements with Operator=Gt", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{{
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 					Key: fake.IntegerInstanceLabelKey, Operator: v1.NodeSelectorOpGt, Values: []string{"8"},
-				}}
+				}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod()
 				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
@@ -697,9 +703,9 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue(fake.IntegerInstanceLabelKey, "16"))
 			})
 			It("should schedule compatible requirements with Operator=Lt", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{{
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 					Key: fake.IntegerInstanceLabelKey, Operator: v1.NodeSelectorOpLt, Values: []string{"8"},
-				}}
+				}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod()
 				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
@@ -855,9 +861,9 @@ var _ = Context("NodePool", func() {
 				}
 			})
 			It("should schedule pods that have node selectors with label in restricted domains exceptions list", func() {
-				var requirements []v1.NodeSelectorRequirement
+				var requirements []v1beta1.NodeSelectorRequirementWithMinValues
 				for domain := range v1beta1.LabelDomainExceptions {
-					requirements = append(requirements, v1.NodeSelectorRequirement{Key: domain + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}})
+					requirements = append(requirements, v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: domain + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}})
 				}
 				nodePool.Spec.Template.Spec.Requirements = requirements
 				ExpectApplied(ctx, env.Client, nodePool)
@@ -869,9 +875,9 @@ var _ = Context("NodePool", func() {
 				}
 			})
 			It("should schedule pods that have node selectors with label in subdomain from restricted domains exceptions list", func() {
-				var requirements []v1.NodeSelectorRequirement
+				var requirements []v1beta1.NodeSelectorRequirementWithMinValues
 				for domain := range v1beta1.LabelDomainExceptions {
-					requirements = append(requirements, v1.NodeSelectorRequirement{Key: "subdomain." + domain + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}})
+					requirements = append(requirements, v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "subdomain." + domain + "/test", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}})
 				}
 				nodePool.Spec.Template.Spec.Requirements = requirements
 				ExpectApplied(ctx, env.Client, nodePool)
@@ -942,8 +948,8 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).ToNot(HaveKey("test-key"))
 			})
 			It("should schedule unconstrained pods that don't have matching node selectors", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod()
 				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
@@ -951,8 +957,8 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue("test-key", "test-value"))
 			})
 			It("should schedule pods that have node selectors with matching value and In operator", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -963,8 +969,8 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue("test-key", "test-value"))
 			})
 			It("should not schedule pods that have node selectors with matching value and NotIn operator", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -974,8 +980,8 @@ var _ = Context("NodePool", func() {
 				ExpectNotScheduled(ctx, env.Client, pod)
 			})
 			It("should schedule the pod with Exists operator and defined key", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -986,8 +992,8 @@ var _ = Context("NodePool", func() {
 				ExpectScheduled(ctx, env.Client, pod)
 			})
 			It("should not schedule the pod with DoesNotExists operator and defined key", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -998,8 +1004,8 @@ var _ = Context("NodePool", func() {
 				ExpectNotScheduled(ctx, env.Client, pod)
 			})
 			It("should not schedule pods that have node selectors with different value and In operator", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -1009,8 +1015,8 @@ var _ = Context("NodePool", func() {
 				ExpectNotScheduled(ctx, env.Client, pod)
 			})
 			It("should schedule pods that have node selectors with different value and NotIn operator", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pod := test.UnschedulablePod(
 					test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
@@ -1021,8 +1027,8 @@ var _ = Context("NodePool", func() {
 				Expect(node.Labels).To(HaveKeyWithValue("test-key", "test-value"))
 			})
 			It("should schedule compatible pods to the same node", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value", "another-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value", "another-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pods := []*v1.Pod{
 					test.UnschedulablePod(
@@ -1041,8 +1047,8 @@ var _ = Context("NodePool", func() {
 				Expect(node1.Name).To(Equal(node2.Name))
 			})
 			It("should schedule incompatible pods to the different node", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value", "another-value"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value", "another-value"}}}}
 				ExpectApplied(ctx, env.Client, nodePool)
 				pods := []*v1.Pod{
 					test.UnschedulablePod(
@@ -1079,9 +1085,9 @@ var _ = Context("NodePool", func() {
 	Describe("Preferential Fallback", func() {
 		Context("Required", func() {
 			It("should not relax the final term", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}},
-					{Key: v1.LabelInstanceTypeStable, Operator: v1.NodeSelectorOpIn, Values: []string{"default-instance-type"}},
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}},
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelInstanceTypeStable, Operator: v1.NodeSelectorOpIn, Values: []string{"default-instance-type"}}},
 				}
 				pod := test.UnschedulablePod()
 				pod.Spec.Affinity = &v1.Affinity{NodeAffinity: &v1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{
@@ -1138,8 +1144,8 @@ var _ = Context("NodePool", func() {
 				ExpectScheduled(ctx, env.Client, pod)
 			})
 			It("should relax to use lighter weights", func() {
-				nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{
-					{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2"}}}
+				nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2"}}}}
 				pod := test.UnschedulablePod()
 				pod.Spec.Affinity = &v1.Affinity{NodeAffinity: &v1.NodeAffinity{PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{
 					{
@@ -1210,11 +1216,11 @@ var _ = Context("NodePool", func() {
 			ExpectNotScheduled(ctx, env.Client, pod)
 		})
 		It("should launch pods with different archs on different instances", func() {
-			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{{
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureArm64, v1beta1.ArchitectureAmd64},
-			}}
+			}}}
 			nodeNames := sets.NewString()
 			ExpectApplied(ctx, env.Client, nodePool)
 			pods := []*v1.Pod{
@@ -1233,11 +1239,11 @@ var _ = Context("NodePool", func() {
 			Expect(nodeNames.Len()).To(Equal(2))
 		})
 		It("should exclude instance types that are not supported by the pod constraints (node affinity/instance type)", func() {
-			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{{
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureAmd64},
-			}}
+			}}}
 			ExpectApplied(ctx, env.Client, nodePool)
 			pod := test.UnschedulablePod(test.PodOptions{
 				NodeRequirements: []v1.NodeSelectorRequirement{
@@ -1252,11 +1258,11 @@ var _ = Context("NodePool", func() {
 			ExpectNotScheduled(ctx, env.Client, pod)
 		})
 		It("should exclude instance types that are not supported by the pod constraints (node affinity/operating system)", func() {
-			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{{
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureAmd64},
-			}}
+			}}}
 			ExpectApplied(ctx, env.Client, nodePool)
 			pod := test.UnschedulablePod(test.PodOptions{
 				NodeRequirements: []v1.NodeSelectorRequirement{
@@ -1272,11 +1278,11 @@ var _ = Context("NodePool", func() {
 			ExpectNotScheduled(ctx, env.Client, pod)
 		})
 		It("should exclude instance types that are not supported by the provider constraints (arch)", func() {
-			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{{
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureAmd64},
-			}}
+			}}}
 			ExpectApplied(ctx, env.Client, nodePool)
 			pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
 				Limits: map[v1.ResourceName]resource.Quantity{v1.ResourceCPU: resource.MustParse("14")}}})
@@ -1285,11 +1291,11 @@ var _ = Context("NodePool", func() {
 			ExpectNotScheduled(ctx, env.Client, pod)
 		})
 		It("should launch pods with different operating systems on different instances", func() {
-			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{{
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureArm64, v1beta1.ArchitectureAmd64},
-			}}
+			}}}
 			nodeNames := sets.NewString()
 			ExpectApplied(ctx, env.Client, nodePool)
 			pods := []*v1.Pod{
@@ -1308,11 +1314,11 @@ var _ = Context("NodePool", func() {
 			Expect(nodeNames.Len()).To(Equal(2))
 		})
 		It("should launch pods with different instance type node selectors on different instances", func() {
-			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{{
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureArm64, v1beta1.ArchitectureAmd64},
-			}}
+			}}}
 			nodeNames := sets.NewString()
 			ExpectApplied(ctx, env.Client, nodePool)
 			pods := []*v1.Pod{
@@ -1331,11 +1337,11 @@ var _ = Context("NodePool", func() {
 			Expect(nodeNames.Len()).To(Equal(2))
 		})
 		It("should launch pods with different zone selectors on different instances", func() {
-			nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirement{{
+			nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 				Key:      v1.LabelArchStable,
 				Operator: v1.NodeSelectorOpIn,
 				Values:   []string{v1beta1.ArchitectureArm64, v1beta1.ArchitectureAmd64},
-			}}
+			}}}
 			nodeNames := sets.NewString()
 			ExpectApplied(ctx, env.Client, nodePool)
 			pods := []*v1.Pod{
@@ -1757,7 +1763,7 @@ var _ = Context("NodePool", func() {
 			// large is the cheapest, so we should pick it, but the other two types are also valid options
 			Expect(node.Labels[v1.LabelInstanceTypeStable]).To(Equal("large"))
 			// all three options should be passed to the cloud provider
-			possibleInstanceType := sets.NewString(pscheduling.NewNodeSelectorRequirements(cloudProvider.CreateCalls[0].Spec.Requirements...).Get(v1.LabelInstanceTypeStable).Values()...)
+			possibleInstanceType := sets.NewString(pscheduling.NewNodeSelectorRequirementsWithMinValues(cloudProvider.CreateCalls[0].Spec.Requirements...).Get(v1.LabelInstanceTypeStable).Values()...)
 			Expect(possibleInstanceType).To(Equal(sets.NewString("small", "medium", "large")))
 		})
 	})
@@ -3271,6 +3277,355 @@ var _ = Context("NodePool", func() {
 	})
 })
 
+var _ = Context("Reserved Capacity", func() {
+	var nodePool *v1beta1.NodePool
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: "reserved-instance-type",
+				Resources: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("4"),
+					v1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+				Offerings: []cloudprovider.Offering{
+					// The reservation is already paid for, so its sunk price is more than the on-demand price
+					{CapacityType: v1beta1.CapacityTypeReserved, Zone: "test-zone-1", Price: 2.00, Available: true, ReservationCapacity: 1},
+					{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-1", Price: 1.00, Available: true},
+				},
+			}),
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: "on-demand-instance-type",
+				Resources: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("4"),
+					v1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+				Offerings: []cloudprovider.Offering{
+					{CapacityType: v1beta1.CapacityTypeOnDemand, Zone: "test-zone-2", Price: 0.50, Available: true},
+				},
+			}),
+		}
+	})
+	// pod returns a pod that needs a node of its own
+	pod := func(options ...test.PodOptions) *v1.Pod {
+		return test.UnschedulablePod(append([]test.PodOptions{{
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}},
+		}}, options...)...)
+	}
+
+	It("should launch into reservations before any other capacity", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		p := pod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, p)
+		node := ExpectScheduled(ctx, env.Client, p)
+		Expect(node.Labels).To(HaveKeyWithValue(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeReserved))
+		Expect(node.Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "reserved-instance-type"))
+		Expect(node.Labels).To(HaveKeyWithValue(v1.LabelTopologyZone, "test-zone-1"))
+	})
+	It("should not launch more nodes into a reservation than it has room for", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		pods := []*v1.Pod{pod(), pod(), pod()}
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+		capacityTypes := lo.Map(pods, func(p *v1.Pod, _ int) string {
+			return ExpectScheduled(ctx, env.Client, p).Labels[v1beta1.CapacityTypeLabelKey]
+		})
+		Expect(capacityTypes).To(ConsistOf(v1beta1.CapacityTypeReserved, v1beta1.CapacityTypeOnDemand, v1beta1.CapacityTypeOnDemand))
+	})
+	It("should not launch into reservations that have no room left", func() {
+		cloudProvider.InstanceTypes[0].Offerings[0].ReservationCapacity = 0
+		ExpectApplied(ctx, env.Client, nodePool)
+		p := pod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, p)
+		node := ExpectScheduled(ctx, env.Client, p)
+		Expect(node.Labels).To(HaveKeyWithValue(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeOnDemand))
+	})
+	It("should not launch into reservations when the nodepool doesn't allow the reserved capacity type", func() {
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1beta1.CapacityTypeLabelKey, Operator: v1.NodeSelectorOpIn, Values: []string{v1beta1.CapacityTypeOnDemand}}},
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+		p := pod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, p)
+		node := ExpectScheduled(ctx, env.Client, p)
+		Expect(node.Labels).To(HaveKeyWithValue(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeOnDemand))
+	})
+	It("should launch other capacity for pods that can't run in the zones of the reservations", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		p := pod(test.PodOptions{NodeSelector: map[string]string{v1.LabelTopologyZone: "test-zone-2"}})
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, p)
+		node := ExpectScheduled(ctx, env.Client, p)
+		Expect(node.Labels).To(HaveKeyWithValue(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeOnDemand))
+		Expect(node.Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "on-demand-instance-type"))
+	})
+	It("should pack pods onto a node that holds a reservation", func() {
+		ExpectApplied(ctx, env.Client, nodePool)
+		small := v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}}
+		pods := []*v1.Pod{
+			test.UnschedulablePod(test.PodOptions{ResourceRequirements: small}),
+			test.UnschedulablePod(test.PodOptions{ResourceRequirements: small}),
+		}
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+		node := ExpectScheduled(ctx, env.Client, pods[0])
+		Expect(ExpectScheduled(ctx, env.Client, pods[1]).Name).To(Equal(node.Name))
+		Expect(node.Labels).To(HaveKeyWithValue(v1beta1.CapacityTypeLabelKey, v1beta1.CapacityTypeReserved))
+	})
+	Context("Multiple Reservations", func() {
+		// reservedInstanceType returns an instance type with a single reservation in each of test-zone-1 and test-zone-2
+		reservedInstanceType := func(name string, cpu string) *cloudprovider.InstanceType {
+			return fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: name,
+				Resources: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse(cpu),
+					v1.ResourceMemory: resource.MustParse("8Gi"),
+				},
+				Offerings: []cloudprovider.Offering{
+					{CapacityType: v1beta1.CapacityTypeReserved, Zone: "test-zone-1", Price: 4.00, Available: true, ReservationCapacity: 1},
+					{CapacityType: v1beta1.CapacityTypeReserved, Zone: "test-zone-2", Price: 4.00, Available: true, ReservationCapacity: 1},
+				},
+			})
+		}
+		It("should only hold a single reservation for each node", func() {
+			cloudProvider.InstanceTypes = append(cloudProvider.InstanceTypes, reservedInstanceType("other-reserved-instance-type", "4"))
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := []*v1.Pod{pod(), pod(), pod(), pod()}
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			nodes := lo.Map(pods, func(p *v1.Pod, _ int) *v1.Node { return ExpectScheduled(ctx, env.Client, p) })
+			Expect(lo.Uniq(lo.Map(nodes, func(n *v1.Node, _ int) string { return n.Name }))).To(HaveLen(4))
+			// every reservation is filled before other capacity is launched
+			Expect(lo.Map(nodes, func(n *v1.Node, _ int) string { return n.Labels[v1beta1.CapacityTypeLabelKey] })).To(ConsistOf(
+				v1beta1.CapacityTypeReserved, v1beta1.CapacityTypeReserved, v1beta1.CapacityTypeReserved, v1beta1.CapacityTypeOnDemand,
+			))
+			reserved := lo.Filter(nodes, func(n *v1.Node, _ int) bool {
+				return n.Labels[v1beta1.CapacityTypeLabelKey] == v1beta1.CapacityTypeReserved
+			})
+			Expect(lo.Map(reserved, func(n *v1.Node, _ int) string {
+				return fmt.Sprintf("%s/%s", n.Labels[v1.LabelInstanceTypeStable], n.Labels[v1.LabelTopologyZone])
+			})).To(ConsistOf(
+				"reserved-instance-type/test-zone-1",
+				"other-reserved-instance-type/test-zone-1",
+				"other-reserved-instance-type/test-zone-2",
+			))
+		})
+		It("should only count the instance type of the held reservation against the nodepool's limits", func() {
+			cloudProvider.InstanceTypes = append(cloudProvider.InstanceTypes, reservedInstanceType("large-reserved-instance-type", "8"))
+			nodePool.Spec.Limits = v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")})
+			ExpectApplied(ctx, env.Client, nodePool)
+			pods := []*v1.Pod{pod(), pod()}
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+			nodes := lo.Map(pods, func(p *v1.Pod, _ int) *v1.Node { return ExpectScheduled(ctx, env.Client, p) })
+			Expect(nodes[0].Name).ToNot(Equal(nodes[1].Name))
+			Expect(lo.Map(nodes, func(n *v1.Node, _ int) string { return n.Labels[v1.LabelInstanceTypeStable] })).ToNot(ContainElement("large-reserved-instance-type"))
+		})
+	})
+})
+
+var _ = Context("Kubelet Configuration", func() {
+	var nodePool *v1beta1.NodePool
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: "small-instance-type",
+				Resources: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("4"),
+					v1.ResourceMemory: resource.MustParse("4Gi"),
+					v1.ResourcePods:   resource.MustParse("10"),
+				},
+			}),
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: "large-instance-type",
+				Resources: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("8"),
+					v1.ResourceMemory: resource.MustParse("8Gi"),
+					v1.ResourcePods:   resource.MustParse("10"),
+				},
+			}),
+		}
+	})
+	// nodeNames returns the names of the nodes that the pods scheduled to
+	nodeNames := func(pods ...*v1.Pod) sets.Set[string] {
+		return sets.New(lo.Map(pods, func(p *v1.Pod, _ int) string { return ExpectScheduled(ctx, env.Client, p).Name })...)
+	}
+
+	It("should limit the pods on a node by maxPods", func() {
+		nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{MaxPods: ptr.Int32(2)}
+		ExpectApplied(ctx, env.Client, nodePool)
+		pods := []*v1.Pod{test.UnschedulablePod(), test.UnschedulablePod(), test.UnschedulablePod(), test.UnschedulablePod()}
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+		Expect(nodeNames(pods...)).To(HaveLen(2))
+	})
+	It("should limit the pods on a node by podsPerCore", func() {
+		nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{PodsPerCore: ptr.Int32(1)}
+		nodePool.Spec.Template.Spec.Requirements = []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{
+			Key:      v1.LabelInstanceTypeStable,
+			Operator: v1.NodeSelectorOpIn,
+			Values:   []string{"small-instance-type"},
+		}}}
+		ExpectApplied(ctx, env.Client, nodePool)
+		pods := lo.Times(8, func(_ int) *v1.Pod { return test.UnschedulablePod() })
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+		Expect(nodeNames(pods...)).To(HaveLen(2))
+	})
+	It("should limit podsPerCore by maxPods", func() {
+		nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{MaxPods: ptr.Int32(2), PodsPerCore: ptr.Int32(10)}
+		ExpectApplied(ctx, env.Client, nodePool)
+		pods := []*v1.Pod{test.UnschedulablePod(), test.UnschedulablePod(), test.UnschedulablePod(), test.UnschedulablePod()}
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+		Expect(nodeNames(pods...)).To(HaveLen(2))
+	})
+	It("should subtract kubeReserved and systemReserved from the allocatable of the instance types", func() {
+		nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{
+			KubeReserved:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+			SystemReserved: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3.5")},
+		}})
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		node := ExpectScheduled(ctx, env.Client, pod)
+		Expect(node.Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "large-instance-type"))
+	})
+	It("should subtract the hard eviction threshold from the allocatable of the instance types", func() {
+		nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{
+			EvictionHard: map[string]string{"memory.available": "50%"},
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("3Gi")},
+		}})
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		node := ExpectScheduled(ctx, env.Client, pod)
+		Expect(node.Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "large-instance-type"))
+	})
+	It("should subtract the larger of the hard and soft eviction thresholds from the allocatable of the instance types", func() {
+		nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{
+			EvictionHard: map[string]string{"memory.available": "100Mi"},
+			EvictionSoft: map[string]string{"memory.available": "50%"},
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("3Gi")},
+		}})
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		node := ExpectScheduled(ctx, env.Client, pod)
+		Expect(node.Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "large-instance-type"))
+	})
+	It("should keep the eviction threshold of the cloudprovider when the kubelet configuration doesn't set one", func() {
+		for _, it := range cloudProvider.InstanceTypes {
+			it.Overhead.EvictionThreshold = v1.ResourceList{v1.ResourceMemory: resource.MustParse("2Gi")}
+		}
+		nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{
+			EvictionHard: map[string]string{"nodefs.available": "10%"},
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("3Gi")},
+		}})
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		node := ExpectScheduled(ctx, env.Client, pod)
+		Expect(node.Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "large-instance-type"))
+	})
+	It("should limit the pods on an in-flight node by maxPods", func() {
+		nodePool.Spec.Template.Spec.Kubelet = &v1beta1.KubeletConfiguration{MaxPods: ptr.Int32(1)}
+		ExpectApplied(ctx, env.Client, nodePool)
+		initialPod := test.UnschedulablePod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, initialPod)
+		node1 := ExpectScheduled(ctx, env.Client, initialPod)
+		ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node1))
+
+		secondPod := test.UnschedulablePod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, secondPod)
+		node2 := ExpectScheduled(ctx, env.Client, secondPod)
+		Expect(node1.Name).ToNot(Equal(node2.Name))
+	})
+})
+
+var _ = Context("Bin-Packing Strategies", func() {
+	var nodePool *v1beta1.NodePool
+	var largeNode, smallNode *v1.Node
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		// the large node is ordered first, so first-fit tries it before the small node
+		largeNode = test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-large-node"},
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("10"),
+				v1.ResourceMemory: resource.MustParse("10Gi"),
+				v1.ResourcePods:   resource.MustParse("110"),
+			},
+		})
+		smallNode = test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Name: "b-small-node"},
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+				v1.ResourcePods:   resource.MustParse("110"),
+			},
+		})
+	})
+	AfterEach(func() {
+		ctx = options.ToContext(ctx, test.Options())
+	})
+	// expectScheduledTo provisions a pod that fits onto either node and returns the node that it scheduled to
+	expectScheduledTo := func() *v1.Node {
+		GinkgoHelper()
+		ExpectApplied(ctx, env.Client, nodePool, largeNode, smallNode)
+		ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(largeNode))
+		ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(smallNode))
+		pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+		}})
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		return ExpectScheduled(ctx, env.Client, pod)
+	}
+
+	It("should schedule to the first existing node that fits with FirstFitDecreasing", func() {
+		Expect(expectScheduledTo().Name).To(Equal(largeNode.Name))
+	})
+	It("should schedule to the existing node with the least capacity left with BestFit", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{BinPackingStrategy: lo.ToPtr(options.BinPackingStrategyBestFit)}))
+		Expect(expectScheduledTo().Name).To(Equal(smallNode.Name))
+	})
+	It("should schedule to the existing node with the least capacity left with CostAware", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{BinPackingStrategy: lo.ToPtr(options.BinPackingStrategyCostAware)}))
+		Expect(expectScheduledTo().Name).To(Equal(smallNode.Name))
+	})
+	It("should use the bin-packing strategy of the NodePool for its nodes", func() {
+		nodePool.Spec.BinPackingStrategy = v1beta1.BinPackingStrategyBestFit
+		for _, node := range []*v1.Node{largeNode, smallNode} {
+			node.Labels = lo.Assign(node.Labels, map[string]string{
+				v1beta1.NodePoolLabelKey:        nodePool.Name,
+				v1beta1.NodeInitializedLabelKey: "true",
+			})
+		}
+		Expect(expectScheduledTo().Name).To(Equal(smallNode.Name))
+	})
+	It("should launch new nodes into the NodeClaim whose price increases the least with CostAware", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{BinPackingStrategy: lo.ToPtr(options.BinPackingStrategyCostAware)}))
+		cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name:      "small-instance-type",
+				Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourcePods: resource.MustParse("10")},
+			}),
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name:      "large-instance-type",
+				Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("8"), v1.ResourcePods: resource.MustParse("10")},
+			}),
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+		// the 7 CPU pod needs the large instance type, which has room for the 0.5 CPU pod without costing more
+		pods := []*v1.Pod{
+			test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("7")}}}),
+			test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1.5")}}}),
+			test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("0.5")}}}),
+		}
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+		Expect(ExpectScheduled(ctx, env.Client, pods[2]).Name).To(Equal(ExpectScheduled(ctx, env.Client, pods[0]).Name))
+		Expect(ExpectScheduled(ctx, env.Client, pods[1]).Labels).To(HaveKeyWithValue(v1.LabelInstanceTypeStable, "small-instance-type"))
+	})
+})
+
 // nolint:gocyclo
 func ExpectMaxSkew(ctx context.Context, c client.Client, namespace string, constraint *v1.TopologySpreadConstraint) Assertion {
 	GinkgoHelper()
@@ -3333,7 +3688,7 @@ func ExpectDeleteAllUnscheduledPods(ctx2 context.Context, c client.Client) {
 // Functions below this line are used for the instance type selection testing
 // -----------
 func supportedInstanceTypes(nodeClaim *v1beta1.NodeClaim) (res []*cloudprovider.InstanceType) {
-	reqs := pscheduling.NewNodeSelectorRequirements(nodeClaim.Spec.Requirements...)
+	reqs := pscheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaim.Spec.Requirements...)
 	return lo.Filter(cloudProvider.InstanceTypes, func(i *cloudprovider.InstanceType, _ int) bool {
 		return reqs.Get(v1.LabelInstanceTypeStable).Has(i.Name)
 	})
@@ -3411,3 +3766,188 @@ func ExpectInstancesWithLabel(instanceTypes []*cloudprovider.InstanceType, label
 		}
 	}
 }
+
+var _ = Context("Scheduling Decisions", func() {
+	// expectDecisions schedules the pods and returns the decision that was made for each of them
+	expectDecisions := func(pods ...*v1.Pod) map[string]*scheduling.Decision {
+		GinkgoHelper()
+		for _, p := range pods {
+			ExpectApplied(ctx, env.Client, p)
+		}
+		results, err := prov.Schedule(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		decisions := map[string]*scheduling.Decision{}
+		for p, decision := range results.PodDecisions {
+			decisions[p.Name] = decision
+		}
+		return decisions
+	}
+
+	It("should record the NodePool that the pod schedules to and the NodePools that rejected it", func() {
+		tainted := test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+			Weight: ptr.Int32(100),
+			Template: v1beta1.NodeClaimTemplate{Spec: v1beta1.NodeClaimSpec{
+				Taints: []v1.Taint{{Key: "foo", Value: "bar", Effect: v1.TaintEffectNoSchedule}},
+			}},
+		}})
+		zonal := test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+			Weight: ptr.Int32(50),
+			Template: v1beta1.NodeClaimTemplate{Spec: v1beta1.NodeClaimSpec{
+				Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{
+					{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}},
+				},
+			}},
+		}})
+		nodePool := test.NodePool()
+		ExpectApplied(ctx, env.Client, tainted, zonal, nodePool)
+		pod := test.UnschedulablePod(test.PodOptions{NodeRequirements: []v1.NodeSelectorRequirement{
+			{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-2"}},
+		}})
+		decision := expectDecisions(pod)[pod.Name]
+		Expect(decision.Scheduled).To(BeTrue())
+		Expect(decision.NodePool).To(Equal(nodePool.Name))
+		Expect(decision.Rejections).To(HaveLen(2))
+		Expect(decision.Rejections[0].NodePool).To(Equal(tainted.Name))
+		Expect(decision.Rejections[0].Reason).To(Equal(scheduling.RejectionReasonTaints))
+		Expect(decision.Rejections[1].NodePool).To(Equal(zonal.Name))
+		Expect(decision.Rejections[1].Reason).To(Equal(scheduling.RejectionReasonRequirements))
+		Expect(decision.Rejections[1].RequirementKeys).To(ConsistOf(v1.LabelTopologyZone))
+	})
+	It("should record the number of instance types that passed each filter when no instance type fits", func() {
+		nodePool := test.NodePool()
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("10000")},
+		}})
+		decision := expectDecisions(pod)[pod.Name]
+		Expect(decision.Scheduled).To(BeFalse())
+		Expect(decision.Rejections).To(HaveLen(1))
+		Expect(decision.Rejections[0].Reason).To(Equal(scheduling.RejectionReasonInstanceTypes))
+		Expect(decision.Rejections[0].InstanceTypes).ToNot(BeNil())
+		Expect(decision.Rejections[0].InstanceTypes.Total).To(Equal(len(cloudProvider.InstanceTypes)))
+		Expect(decision.Rejections[0].InstanceTypes.Fit).To(BeZero())
+		Expect(decision.Rejections[0].InstanceTypes.RequirementsMet).To(BeNumerically(">", 0))
+	})
+	It("should record the NodePools that were rejected due to limits and the daemonset overhead", func() {
+		nodePool := test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+			Limits: v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")}),
+		}})
+		ExpectApplied(ctx, env.Client, nodePool, test.DaemonSet(test.DaemonSetOptions{PodOptions: test.PodOptions{
+			ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+		}}))
+		pod := test.UnschedulablePod()
+		decision := expectDecisions(pod)[pod.Name]
+		Expect(decision.Scheduled).To(BeFalse())
+		Expect(decision.Rejections).To(HaveLen(1))
+		Expect(decision.Rejections[0].Reason).To(Equal(scheduling.RejectionReasonLimits))
+		Expect(decision.Rejections[0].DaemonSetOverhead.Cpu().String()).To(Equal("1"))
+	})
+	It("should record the preferences that were relaxed", func() {
+		nodePool := test.NodePool()
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod(test.PodOptions{NodePreferences: []v1.NodeSelectorRequirement{
+			{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"invalid"}},
+		}})
+		decision := expectDecisions(pod)[pod.Name]
+		Expect(decision.Scheduled).To(BeTrue())
+		Expect(decision.RelaxedPreferences).To(HaveLen(1))
+		Expect(decision.RelaxedPreferences[0]).To(ContainSubstring("preferredDuringSchedulingIgnoredDuringExecution"))
+	})
+})
+
+var _ = Context("Preemption", func() {
+	var node *v1.Node
+	var lowPriority, highPriority *schedulingv1.PriorityClass
+	// podWithPriority returns a pod with the priority and preemption policy that admission populates from its PriorityClass
+	podWithPriority := func(priorityClass *schedulingv1.PriorityClass, opts test.PodOptions) *v1.Pod {
+		opts.PriorityClassName = priorityClass.Name
+		opts.ResourceRequirements = v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1.5")}}
+		pod := lo.Ternary(opts.NodeName == "", test.UnschedulablePod(opts), test.Pod(opts))
+		pod.Spec.Priority = lo.ToPtr(priorityClass.Value)
+		pod.Spec.PreemptionPolicy = priorityClass.PreemptionPolicy
+		return pod
+	}
+	// expectBound binds lower priority pods to the node and returns them
+	expectBound := func(priorityClass *schedulingv1.PriorityClass, count int, opts test.PodOptions) []*v1.Pod {
+		GinkgoHelper()
+		var pods []*v1.Pod
+		for i := 0; i < count; i++ {
+			opts.NodeName = node.Name
+			pod := podWithPriority(priorityClass, opts)
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectReconcileSucceeded(ctx, podStateController, client.ObjectKeyFromObject(pod))
+			pods = append(pods, pod)
+		}
+		return pods
+	}
+
+	BeforeEach(func() {
+		node = test.Node(test.NodeOptions{Allocatable: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("4"),
+			v1.ResourceMemory: resource.MustParse("4Gi"),
+			v1.ResourcePods:   resource.MustParse("10"),
+		}})
+		lowPriority = &schedulingv1.PriorityClass{ObjectMeta: test.ObjectMeta(), Value: 0}
+		highPriority = &schedulingv1.PriorityClass{ObjectMeta: test.ObjectMeta(), Value: 1000}
+		ExpectApplied(ctx, env.Client, test.NodePool(), node, lowPriority, highPriority)
+		ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+	})
+	AfterEach(func() {
+		ExpectDeleted(ctx, env.Client, lowPriority, highPriority)
+	})
+
+	It("should not launch capacity for a pod that preempts lower priority pods on an existing node", func() {
+		bound := expectBound(lowPriority, 2, test.PodOptions{})
+		pod := podWithPriority(highPriority, test.PodOptions{})
+		ExpectApplied(ctx, env.Client, pod)
+		results, err := prov.Schedule(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		existing, ok := lo.Find(results.ExistingNodes, func(n *scheduling.ExistingNode) bool { return n.Name() == node.Name })
+		Expect(ok).To(BeTrue())
+		Expect(existing.Pods).To(ConsistOf(pod))
+		decision := results.PodDecisions[pod]
+		Expect(decision.Node).To(Equal(node.Name))
+		Expect(decision.Preempted).To(HaveLen(1))
+		// the preempted pod still gets capacity, as its priority isn't below the expendable pods priority cutoff
+		Expect(results.NewNodeClaims).To(HaveLen(1))
+		Expect(results.NewNodeClaims[0].Pods).To(HaveLen(1))
+		Expect(lo.Map(bound, func(p *v1.Pod, _ int) string { return client.ObjectKeyFromObject(p).String() })).
+			To(ContainElement(decision.Preempted[0]))
+		Expect(client.ObjectKeyFromObject(results.NewNodeClaims[0].Pods[0]).String()).To(Equal(decision.Preempted[0]))
+	})
+	It("should launch capacity for a pod whose preemption policy is Never", func() {
+		nonPreempting := &schedulingv1.PriorityClass{ObjectMeta: test.ObjectMeta(), Value: 1000, PreemptionPolicy: lo.ToPtr(v1.PreemptNever)}
+		ExpectApplied(ctx, env.Client, nonPreempting)
+		DeferCleanup(func() { ExpectDeleted(ctx, env.Client, nonPreempting) })
+		expectBound(lowPriority, 2, test.PodOptions{})
+		pod := podWithPriority(nonPreempting, test.PodOptions{})
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		Expect(ExpectScheduled(ctx, env.Client, pod).Name).ToNot(Equal(node.Name))
+	})
+	It("should launch capacity for a pod if preempting lower priority pods violates their PodDisruptionBudget", func() {
+		labels := map[string]string{"app": "low-priority"}
+		ExpectApplied(ctx, env.Client, test.PodDisruptionBudget(test.PDBOptions{
+			Labels:       labels,
+			MinAvailable: lo.ToPtr(intstr.FromInt(2)),
+			Status:       &policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+		}))
+		expectBound(lowPriority, 2, test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: labels}})
+		pod := podWithPriority(highPriority, test.PodOptions{})
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		Expect(ExpectScheduled(ctx, env.Client, pod).Name).ToNot(Equal(node.Name))
+	})
+	It("should not launch capacity for preempted pods with a priority below the expendable pods priority cutoff", func() {
+		expendable := &schedulingv1.PriorityClass{ObjectMeta: test.ObjectMeta(), Value: -20}
+		ExpectApplied(ctx, env.Client, expendable)
+		DeferCleanup(func() { ExpectDeleted(ctx, env.Client, expendable) })
+		expectBound(expendable, 2, test.PodOptions{})
+		pod := podWithPriority(highPriority, test.PodOptions{})
+		ExpectApplied(ctx, env.Client, pod)
+		results, err := prov.Schedule(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.PodDecisions[pod].Node).To(Equal(node.Name))
+		Expect(results.PodDecisions[pod].Preempted).To(HaveLen(1))
+		Expect(results.NewNodeClaims).To(BeEmpty())
+	})
+})
@@ -49,18 +49,18 @@ type Topology struct {
 	// in some cases.
 	inverseTopologies map[uint64]*TopologyGroup
 	// The universe of domains by topology key
-	domains map[string]sets.Set[string]
+	domainGroups map[string]TopologyDomainGroup
 	// excludedPods are the pod UIDs of pods that are excluded from counting.  This is used so we can simulate
 	// moving pods to prevent them from being double counted.
 	excludedPods sets.Set[string]
 	cluster      *state.Cluster
 }
 
-func NewTopology(ctx context.Context, kubeClient client.Client, cluster *state.Cluster, domains map[string]sets.Set[string], pods []*v1.Pod) (*Topology, error) {
+func NewTopology(ctx context.Context, kubeClient client.Client, cluster *state.Cluster, domainGroups map[string]TopologyDomainGroup, pods []*v1.Pod) (*Topology, error) {
 	t := &Topology{
 		kubeClient:        kubeClient,
 		cluster:           cluster,
-		domains:           domains,
+		domainGroups:      domainGroups,
 		topologies:        map[uint64]*TopologyGroup{},
 		inverseTopologies: map[uint64]*TopologyGroup{},
 		excludedPods:      sets.New[string](),
@@ -119,11 +119,11 @@ func (t *Topology) Update(ctx context.Context, p *v1.Pod) error {
 	return nil
 }
 
-// Record records the topology changes given that pod p schedule on a node with the given requirements
-func (t *Topology) Record(p *v1.Pod, requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatabilityOptions]) {
+// Record records the topology changes given that pod p schedule on a node with the given taints and requirements
+func (t *Topology) Record(p *v1.Pod, taints []v1.Taint, requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatabilityOptions]) {
 	// once we've committed to a domain, we record the usage in every topology that cares about it
 	for _, tc := range t.topologies {
-		if tc.Counts(p, requirements, compatabilityOptions...) {
+		if tc.Counts(p, taints, requirements, compatabilityOptions...) {
 			domains := requirements.Get(tc.Key)
 			if tc.Type == TopologyTypePodAntiAffinity {
 				// for anti-affinity topologies we need to block out all possible domains that the pod could land in
@@ -213,7 +213,7 @@ func (t *Topology) updateInverseAntiAffinity(ctx context.Context, pod *v1.Pod, d
 			return err
 		}
 
-		tg := NewTopologyGroup(TopologyTypePodAntiAffinity, term.TopologyKey, pod, namespaces, term.LabelSelector, math.MaxInt32, nil, t.domains[term.TopologyKey])
+		tg := NewTopologyGroup(TopologyTypePodAntiAffinity, term.TopologyKey, pod, namespaces, term.LabelSelector, math.MaxInt32, nil, nil, nil, t.domainGroups[term.TopologyKey])
 
 		hash := tg.Hash()
 		if existing, ok := t.inverseTopologies[hash]; !ok {
@@ -279,7 +279,8 @@ func (t *Topology) countDomains(ctx context.Context, tg *TopologyGroup) error {
 			continue // Don't include pods if node doesn't contain domain https://kubernetes.io/docs/concepts/workloads/pods/pod-topology-spread-constraints/#conventions
 		}
 		// nodes may or may not be considered for counting purposes for topology spread constraints depending on if they
-		// are selected by the pod's node selectors and required node affinities.  If these are unset, the node always counts.
+		// are selected by the pod's node selectors and required node affinities, and if the pod tolerates their taints,
+		// according to the node inclusion policies.  If these are unset, the node always counts.
 		if !tg.nodeFilter.Matches(node) {
 			continue
 		}
@@ -291,11 +292,31 @@ func (t *Topology) countDomains(ctx context.Context, tg *TopologyGroup) error {
 func (t *Topology) newForTopologies(p *v1.Pod) []*TopologyGroup {
 	var topologyGroups []*TopologyGroup
 	for _, cs := range p.Spec.TopologySpreadConstraints {
-		topologyGroups = append(topologyGroups, NewTopologyGroup(TopologyTypeSpread, cs.TopologyKey, p, sets.New(p.Namespace), cs.LabelSelector, cs.MaxSkew, cs.MinDomains, t.domains[cs.TopologyKey]))
+		topologyGroups = append(topologyGroups, NewTopologyGroup(TopologyTypeSpread, cs.TopologyKey, p, sets.New(p.Namespace), spreadLabelSelector(p, cs),
+			cs.MaxSkew, cs.MinDomains, cs.NodeAffinityPolicy, cs.NodeTaintsPolicy, t.domainGroups[cs.TopologyKey]))
 	}
 	return topologyGroups
 }
 
+// spreadLabelSelector returns the label selector of the topology spread constraint, which also selects the pods with
+// the same values as the pod for its matchLabelKeys. Like kube-scheduler, keys that the pod doesn't have are ignored.
+func spreadLabelSelector(p *v1.Pod, cs v1.TopologySpreadConstraint) *metav1.LabelSelector {
+	if cs.LabelSelector == nil || len(cs.MatchLabelKeys) == 0 {
+		return cs.LabelSelector
+	}
+	selector := cs.LabelSelector.DeepCopy()
+	for _, key := range cs.MatchLabelKeys {
+		if value, ok := p.Labels[key]; ok {
+			selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+				Key:      key,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{value},
+			})
+		}
+	}
+	return selector
+}
+
 // newForAffinities returns a list of topology groups that have been constructed based on the input pod and required/preferred affinity terms
 func (t *Topology) newForAffinities(ctx context.Context, p *v1.Pod) ([]*TopologyGroup, error) {
 	var topologyGroups []*TopologyGroup
@@ -328,7 +349,7 @@ func (t *Topology) newForAffinities(ctx context.Context, p *v1.Pod) ([]*Topology
 			if err != nil {
 				return nil, err
 			}
-			topologyGroups = append(topologyGroups, NewTopologyGroup(topologyType, term.TopologyKey, p, namespaces, term.LabelSelector, math.MaxInt32, nil, t.domains[term.TopologyKey]))
+			topologyGroups = append(topologyGroups, NewTopologyGroup(topologyType, term.TopologyKey, p, namespaces, term.LabelSelector, math.MaxInt32, nil, nil, nil, t.domainGroups[term.TopologyKey]))
 		}
 	}
 	return topologyGroups, nil
@@ -368,8 +389,9 @@ func (t *Topology) getMatchingTopologies(p *v1.Pod, requirements scheduling.Requ
 			matchingTopologies = append(matchingTopologies, tc)
 		}
 	}
+	// inverse topologies are only tracked for anti-affinities, which count across all nodes regardless of their taints
 	for _, tc := range t.inverseTopologies {
-		if tc.Counts(p, requirements, compatabilityOptions...) {
+		if tc.Counts(p, nil, requirements, compatabilityOptions...) {
 			matchingTopologies = append(matchingTopologies, tc)
 		}
 	}
@@ -0,0 +1,57 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+)
+
+// TopologyDomainGroup tracks the domains of a single topology key, along with the distinct taints of the NodePools
+// that can launch nodes into each domain. The taints are needed so that topology spread constraints with the Honor
+// node taints policy only consider the domains that the pod can schedule to.
+type TopologyDomainGroup map[string][][]v1.Taint
+
+func NewTopologyDomainGroup() TopologyDomainGroup {
+	return map[string][][]v1.Taint{}
+}
+
+// Insert adds a domain that a NodePool with the taints can launch nodes into
+func (t TopologyDomainGroup) Insert(domain string, taints ...v1.Taint) {
+	if lo.ContainsBy(t[domain], func(existing []v1.Taint) bool {
+		return len(existing) == 0 || (len(existing) == len(taints) && lo.Every(existing, taints))
+	}) {
+		return
+	}
+	// if any NodePool without taints can launch into the domain, every pod can schedule to it, so we only keep that
+	if len(taints) == 0 {
+		t[domain] = [][]v1.Taint{{}}
+		return
+	}
+	t[domain] = append(t[domain], taints)
+}
+
+// ForEachDomain calls f for each domain in the group. If the node taints policy is Honor, the domains that the pod
+// can only reach through NodePools with taints that it doesn't tolerate are skipped.
+func (t TopologyDomainGroup) ForEachDomain(pod *v1.Pod, nodeTaintsPolicy v1.NodeInclusionPolicy, f func(domain string)) {
+	for domain, taintGroups := range t {
+		if nodeTaintsPolicy == v1.NodeInclusionPolicyHonor && !lo.ContainsBy(taintGroups, func(taints []v1.Taint) bool {
+			return toleratesTaints(pod.Spec.Tolerations, taints)
+		}) {
+			continue
+		}
+		f(domain)
+	}
+}
@@ -65,16 +65,18 @@ type TopologyGroup struct {
 	domains map[string]int32       // TODO(ellistarn) explore replacing with a minheap
 }
 
-func NewTopologyGroup(topologyType TopologyType, topologyKey string, pod *v1.Pod, namespaces sets.Set[string], labelSelector *metav1.LabelSelector, maxSkew int32, minDomains *int32, domains sets.Set[string]) *TopologyGroup {
-	domainCounts := map[string]int32{}
-	for domain := range domains {
-		domainCounts[domain] = 0
-	}
-	// the nil *TopologyNodeFilter always passes which is what we need for affinity/anti-affinity
+func NewTopologyGroup(topologyType TopologyType, topologyKey string, pod *v1.Pod, namespaces sets.Set[string], labelSelector *metav1.LabelSelector, maxSkew int32, minDomains *int32,
+	nodeAffinityPolicy, nodeTaintsPolicy *v1.NodeInclusionPolicy, domainGroup TopologyDomainGroup) *TopologyGroup {
+	// the zero-value TopologyNodeFilter always passes which is what we need for affinity/anti-affinity
 	var nodeSelector TopologyNodeFilter
 	if topologyType == TopologyTypeSpread {
-		nodeSelector = MakeTopologyNodeFilter(pod)
+		nodeSelector = MakeTopologyNodeFilter(pod, nodeAffinityPolicy, nodeTaintsPolicy)
 	}
+	// domains that the pod can't schedule to due to taints don't participate in the topology if the taints are honored
+	domainCounts := map[string]int32{}
+	domainGroup.ForEachDomain(pod, nodeSelector.NodeTaintsPolicy, func(domain string) {
+		domainCounts[domain] = 0
+	})
 	return &TopologyGroup{
 		Type:       topologyType,
 		Key:        topologyKey,
@@ -108,9 +110,9 @@ func (t *TopologyGroup) Record(domains ...string) {
 }
 
 // Counts returns true if the pod would count for the topology, given that it schedule to a node with the provided
-// requirements
-func (t *TopologyGroup) Counts(pod *v1.Pod, requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatabilityOptions]) bool {
-	return t.selects(pod) && t.nodeFilter.MatchesRequirements(requirements, compatabilityOptions...)
+// taints and requirements
+func (t *TopologyGroup) Counts(pod *v1.Pod, taints []v1.Taint, requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatabilityOptions]) bool {
+	return t.selects(pod) && t.nodeFilter.MatchesRequirements(requirements, taints, compatabilityOptions...)
 }
 
 // Register ensures that the topology is aware of the given domain names.
@@ -159,8 +161,12 @@ func (t *TopologyGroup) Hash() uint64 {
 // If there are multiple eligible domains, we return any random domain that satisfies the `maxSkew` configuration.
 // If there are no eligible domains, we return a `DoesNotExist` requirement, implying that we could not satisfy the topologySpread requirement.
 func (t *TopologyGroup) nextDomainTopologySpread(pod *v1.Pod, podDomains, nodeDomains *scheduling.Requirement) *scheduling.Requirement {
-	// min count is calculated across all domains
-	min := t.domainMinCount(podDomains)
+	// min count is calculated across all domains, which are limited to the pod's domains if its node affinity is honored
+	minDomains := podDomains
+	if t.nodeFilter.NodeAffinityPolicy == v1.NodeInclusionPolicyIgnore {
+		minDomains = scheduling.NewRequirement(podDomains.Key, v1.NodeSelectorOpExists)
+	}
+	min := t.domainMinCount(minDomains)
 	selfSelecting := t.selects(pod)
 
 	minDomain := ""
@@ -15,6 +15,7 @@ limitations under the License.
 package scheduling
 
 import (
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 
 	"github.com/aws/karpenter-core/pkg/scheduling"
@@ -22,27 +23,46 @@ import (
 )
 
 // TopologyNodeFilter is used to determine if a given actual node or scheduling node matches the pod's node selectors
-// and required node affinity terms.  This is used with topology spread constraints to determine if the node should be
+// and required node affinity terms, and tolerates the node's taints, depending on the node inclusion policies of the
+// topology spread constraint.  This is used with topology spread constraints to determine if the node should be
 // included for topology counting purposes. This is only used with topology spread constraints as affinities/anti-affinities
-// always count across all nodes. A nil or zero-value TopologyNodeFilter behaves well and the filter returns true for
-// all nodes.
-type TopologyNodeFilter []scheduling.Requirements
+// always count across all nodes. A zero-value TopologyNodeFilter behaves well and the filter returns true for all nodes.
+type TopologyNodeFilter struct {
+	// Requirements are OR'd together, and a node matches if it's compatible with any of them
+	Requirements       []scheduling.Requirements
+	NodeAffinityPolicy v1.NodeInclusionPolicy
+	NodeTaintsPolicy   v1.NodeInclusionPolicy
+	Tolerations        []v1.Toleration
+}
 
-func MakeTopologyNodeFilter(p *v1.Pod) TopologyNodeFilter {
+// MakeTopologyNodeFilter returns the filter for a topology spread constraint of the pod. Like kube-scheduler, the pod's
+// node selectors and required node affinity are honored unless the node affinity policy is Ignore, and node taints are
+// ignored unless the node taints policy is Honor.
+func MakeTopologyNodeFilter(p *v1.Pod, nodeAffinityPolicy, nodeTaintsPolicy *v1.NodeInclusionPolicy) TopologyNodeFilter {
+	filter := TopologyNodeFilter{
+		NodeAffinityPolicy: lo.FromPtrOr(nodeAffinityPolicy, v1.NodeInclusionPolicyHonor),
+		NodeTaintsPolicy:   lo.FromPtrOr(nodeTaintsPolicy, v1.NodeInclusionPolicyIgnore),
+	}
+	if filter.NodeTaintsPolicy == v1.NodeInclusionPolicyHonor {
+		filter.Tolerations = p.Spec.Tolerations
+	}
+	if filter.NodeAffinityPolicy == v1.NodeInclusionPolicyIgnore {
+		return filter
+	}
 	nodeSelectorRequirements := scheduling.NewLabelRequirements(p.Spec.NodeSelector)
 	// if we only have a label selector, that's the only requirement that must match
 	if p.Spec.Affinity == nil || p.Spec.Affinity.NodeAffinity == nil || p.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
-		return TopologyNodeFilter{nodeSelectorRequirements}
+		filter.Requirements = []scheduling.Requirements{nodeSelectorRequirements}
+		return filter
 	}
 
 	// otherwise, we need to match the combination of label selector and any term of the required node affinities since
 	// those terms are OR'd together
-	var filter TopologyNodeFilter
 	for _, term := range p.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
 		requirements := scheduling.NewRequirements()
 		requirements.Add(nodeSelectorRequirements.Values()...)
 		requirements.Add(scheduling.NewNodeSelectorRequirements(term.MatchExpressions...).Values()...)
-		filter = append(filter, requirements)
+		filter.Requirements = append(filter.Requirements, requirements)
 	}
 
 	return filter
@@ -50,22 +70,39 @@ func MakeTopologyNodeFilter(p *v1.Pod) TopologyNodeFilter {
 
 // Matches returns true if the TopologyNodeFilter doesn't prohibit node from the participating in the topology
 func (t TopologyNodeFilter) Matches(node *v1.Node) bool {
-	return t.MatchesRequirements(scheduling.NewLabelRequirements(node.Labels))
+	return t.MatchesRequirements(scheduling.NewLabelRequirements(node.Labels), node.Spec.Taints)
 }
 
-// MatchesRequirements returns true if the TopologyNodeFilter doesn't prohibit a node with the requirements from
-// participating in the topology. This method allows checking the requirements from a scheduling.NodeClaim to see if the
-// node we will soon create participates in this topology.
-func (t TopologyNodeFilter) MatchesRequirements(requirements scheduling.Requirements, compatabilityOptions ...functional.Option[scheduling.CompatabilityOptions]) bool {
+// MatchesRequirements returns true if the TopologyNodeFilter doesn't prohibit a node with the requirements and taints
+// from participating in the topology. This method allows checking the requirements from a scheduling.NodeClaim to see
+// if the node we will soon create participates in this topology.
+func (t TopologyNodeFilter) MatchesRequirements(requirements scheduling.Requirements, taints []v1.Taint, compatabilityOptions ...functional.Option[scheduling.CompatabilityOptions]) bool {
+	if t.NodeTaintsPolicy == v1.NodeInclusionPolicyHonor && !toleratesTaints(t.Tolerations, taints) {
+		return false
+	}
 	// no requirements, so it always matches
-	if len(t) == 0 {
+	if len(t.Requirements) == 0 {
 		return true
 	}
 	// these are an OR, so if any passes the filter passes
-	for _, req := range t {
+	for _, req := range t.Requirements {
 		if err := requirements.Compatible(req, compatabilityOptions...); err == nil {
 			return true
 		}
 	}
 	return false
 }
+
+// toleratesTaints returns true if the tolerations tolerate all the taints that prevent pods from scheduling. Like
+// kube-scheduler, PreferNoSchedule taints don't exclude a node from topology spread.
+func toleratesTaints(tolerations []v1.Toleration, taints []v1.Taint) bool {
+	for i := range taints {
+		if taints[i].Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !lo.ContainsBy(tolerations, func(t v1.Toleration) bool { return t.ToleratesTaint(&taints[i]) }) {
+			return false
+		}
+	}
+	return true
+}
@@ -16,6 +16,7 @@ package provisioning_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -24,13 +25,19 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 	clock "k8s.io/utils/clock/testing"
+	knativeapis "knative.dev/pkg/apis"
 	. "knative.dev/pkg/logging/testing"
 	"knative.dev/pkg/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,6 +47,7 @@ import (
 	"github.com/aws/karpenter-core/pkg/cloudprovider"
 	"github.com/aws/karpenter-core/pkg/cloudprovider/fake"
 	"github.com/aws/karpenter-core/pkg/controllers/provisioning"
+	"github.com/aws/karpenter-core/pkg/controllers/provisioning/scheduling"
 	"github.com/aws/karpenter-core/pkg/controllers/state"
 	"github.com/aws/karpenter-core/pkg/controllers/state/informer"
 	"github.com/aws/karpenter-core/pkg/events"
@@ -73,7 +81,7 @@ var _ = BeforeSuite(func() {
 	fakeClock = clock.NewFakeClock(time.Now())
 	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
 	nodeController = informer.NewNodeController(env.Client, cluster)
-	prov = provisioning.NewProvisioner(env.Client, corev1.NewForConfigOrDie(env.Config), events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster)
+	prov = provisioning.NewProvisioner(env.Client, corev1.NewForConfigOrDie(env.Config), env.KubernetesInterface.NodeV1(), events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, cloudprovider.NewUnavailableOfferings())
 	daemonsetController = informer.NewDaemonSetController(env.Client, cluster)
 	instanceTypes, _ := cloudProvider.GetInstanceTypes(ctx, nil)
 	instanceTypeMap = map[string]*cloudprovider.InstanceType{}
@@ -118,6 +126,29 @@ var _ = Describe("Provisioning", func() {
 		Expect(len(nodes.Items)).To(Equal(0))
 		ExpectNotScheduled(ctx, env.Client, pod)
 	})
+	It("should ignore NodePools that aren't launchable", func() {
+		nodePool := test.NodePool()
+		nodePool.StatusConditions().MarkFalse(v1beta1.Launchable, "InvalidConfiguration", "invalid configuration")
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		Expect(cloudProvider.CreateCalls).To(HaveLen(0))
+		ExpectNotScheduled(ctx, env.Client, pod)
+	})
+	It("should provision nodes for NodePools that haven't been launchable for the retry period", func() {
+		nodePool := test.NodePool()
+		nodePool.Status.Conditions = knativeapis.Conditions{{
+			Type:               v1beta1.Launchable,
+			Status:             v1.ConditionFalse,
+			Reason:             "InvalidConfiguration",
+			LastTransitionTime: knativeapis.VolatileTime{Inner: metav1.NewTime(time.Now().Add(-time.Minute * 10))},
+		}}
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+		ExpectScheduled(ctx, env.Client, pod)
+	})
 	It("should provision nodes for pods with supported node selectors", func() {
 		nodePool := test.NodePool()
 		schedulable := []*v1.Pod{
@@ -214,19 +245,17 @@ var _ = Describe("Provisioning", func() {
 		}
 	})
 	It("should provision multiple nodes when maxPods is set", func() {
-		// Kubelet is actually not observed here, the scheduler is relying on the
-		// pods resource value which is statically set in the fake cloudprovider
 		ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{
 			Spec: v1beta1.NodePoolSpec{
 				Template: v1beta1.NodeClaimTemplate{
 					Spec: v1beta1.NodeClaimSpec{
 						Kubelet: &v1beta1.KubeletConfiguration{MaxPods: ptr.Int32(1)},
-						Requirements: []v1.NodeSelectorRequirement{
-							{
+						Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{
+							{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 								Key:      v1.LabelInstanceTypeStable,
 								Operator: v1.NodeSelectorOpIn,
 								Values:   []string{"single-pod-instance-type"},
-							},
+							}},
 						},
 					},
 				},
@@ -282,6 +311,115 @@ var _ = Describe("Provisioning", func() {
 			Expect(n.Node.Name).ToNot(Equal(node.Name))
 		}
 	})
+	Context("Scheduling Decisions", func() {
+		It("should annotate pending pods with their scheduling decision", func() {
+			nodePool := test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+				Limits: v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")}),
+			}})
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, nodePool, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			prov.PersistDecisions(ctx, results.PodDecisions)
+
+			pod = ExpectExists(ctx, env.Client, pod)
+			Expect(pod.Annotations).To(HaveKey(v1beta1.SchedulingDecisionAnnotationKey))
+			decision := &scheduling.Decision{}
+			Expect(json.Unmarshal([]byte(pod.Annotations[v1beta1.SchedulingDecisionAnnotationKey]), decision)).To(Succeed())
+			Expect(decision.Scheduled).To(BeFalse())
+			Expect(decision.Rejections).To(HaveLen(1))
+			Expect(decision.Rejections[0].NodePool).To(Equal(nodePool.Name))
+			Expect(decision.Rejections[0].Reason).To(Equal(scheduling.RejectionReasonLimits))
+		})
+		It("should not patch pods whose scheduling decision is unchanged", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+				Limits: v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")}),
+			}}))
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			prov.PersistDecisions(ctx, results.PodDecisions)
+			pod = ExpectExists(ctx, env.Client, pod)
+
+			results, err = prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			prov.PersistDecisions(ctx, results.PodDecisions)
+			Expect(ExpectExists(ctx, env.Client, pod).ResourceVersion).To(Equal(pod.ResourceVersion))
+		})
+		It("should not re-patch pods whose scheduling decision changes within the persist interval", func() {
+			nodePool := test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+				Limits: v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")}),
+			}})
+			pod := test.UnschedulablePod()
+			ExpectApplied(ctx, env.Client, nodePool, pod)
+			results, err := prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			prov.PersistDecisions(ctx, results.PodDecisions)
+			pod = ExpectExists(ctx, env.Client, pod)
+
+			// Replace the nodepool so that the pod is rejected by a different nodepool
+			ExpectDeleted(ctx, env.Client, nodePool)
+			ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+				Limits: v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("0")}),
+			}}))
+			results, err = prov.Schedule(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			prov.PersistDecisions(ctx, results.PodDecisions)
+			Expect(ExpectExists(ctx, env.Client, pod).ResourceVersion).To(Equal(pod.ResourceVersion))
+		})
+	})
+	Context("Headroom", func() {
+		It("should launch capacity for the headroom of a nodepool without pending pods", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+				Headroom: &v1beta1.Headroom{Pods: &v1beta1.HeadroomPods{
+					Count:    2,
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Mi")},
+				}},
+			}}))
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			Expect(cloudProvider.CreateCalls[0].Spec.Resources.Requests.Cpu().String()).To(Equal("2"))
+			Expect(cloudProvider.CreateCalls[0].Spec.Resources.Requests.Memory().String()).To(Equal("2Mi"))
+			Expect(cloudProvider.CreateCalls[0].Spec.Resources.Requests.Pods().String()).To(Equal("2"))
+		})
+		It("should not launch capacity if the headroom is available on existing nodes", func() {
+			ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+				Headroom: &v1beta1.Headroom{Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+			}}))
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+		})
+		It("should only keep the headroom of a nodepool on its own nodes", func() {
+			nodePool := test.NodePool()
+			headroomNodePool := test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+				Headroom: &v1beta1.Headroom{Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+			}})
+			ExpectApplied(ctx, env.Client, nodePool, headroomNodePool)
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{v1beta1.NodePoolLabelKey: nodePool.Name}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(2))
+			Expect(lo.Map(cloudProvider.CreateCalls, func(nc *v1beta1.NodeClaim, _ int) string { return nc.Labels[v1beta1.NodePoolLabelKey] })).
+				To(ConsistOf(nodePool.Name, headroomNodePool.Name))
+		})
+		It("should schedule pending pods before the headroom", func() {
+			// the limits only allow a single node, which can't fit both the pod and the headroom
+			ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{Spec: v1beta1.NodePoolSpec{
+				Limits:   v1beta1.Limits(v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}),
+				Headroom: &v1beta1.Headroom{Resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}},
+			}}))
+			pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			Expect(cloudProvider.CreateCalls[0].Spec.Resources.Requests.Cpu().String()).To(Equal("2"))
+		})
+	})
 	Context("Resource Limits", func() {
 		It("should not schedule when limits are exceeded", func() {
 			ExpectApplied(ctx, env.Client, test.NodePool(v1beta1.NodePool{
@@ -518,6 +656,63 @@ var _ = Describe("Provisioning", func() {
 			Expect(*allocatable.Cpu()).To(Equal(resource.MustParse("4")))
 			Expect(*allocatable.Memory()).To(Equal(resource.MustParse("4Gi")))
 		})
+		It("should account for the pod overhead of the daemonset's runtime class", func() {
+			runtimeClass := &nodev1.RuntimeClass{
+				ObjectMeta: test.ObjectMeta(),
+				Handler:    "default",
+				Overhead:   &nodev1.Overhead{PodFixed: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}},
+			}
+			daemonset := test.DaemonSet(
+				test.DaemonSetOptions{PodOptions: test.PodOptions{
+					ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")}},
+				}},
+			)
+			daemonset.Spec.Template.Spec.RuntimeClassName = &runtimeClass.Name
+			ExpectApplied(ctx, env.Client, test.NodePool(), runtimeClass, daemonset)
+			pod := test.UnschedulablePod(
+				test.PodOptions{
+					ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")}},
+				},
+			)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+
+			// the daemonset and the pod fit on a 4 cpu instance type, but not with the overhead of the runtime class
+			allocatable := instanceTypeMap[node.Labels[v1.LabelInstanceTypeStable]].Capacity
+			Expect(allocatable.Cpu().Cmp(resource.MustParse("4"))).To(Equal(1))
+		})
+		It("should ignore the overhead of the daemonset's runtime class when it can't be read", func() {
+			// the controller isn't granted access to runtime classes
+			clientset := fakeclientset.NewSimpleClientset()
+			clientset.PrependReactor("get", "runtimeclasses", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(nodev1.Resource("runtimeclasses"), action.(clienttesting.GetAction).GetName(), fmt.Errorf("missing permissions"))
+			})
+			forbiddenProv := provisioning.NewProvisioner(env.Client, corev1.NewForConfigOrDie(env.Config), clientset.NodeV1(), events.NewRecorder(&record.FakeRecorder{}), cloudProvider, cluster, cloudprovider.NewUnavailableOfferings())
+
+			runtimeClass := &nodev1.RuntimeClass{
+				ObjectMeta: test.ObjectMeta(),
+				Handler:    "default",
+				Overhead:   &nodev1.Overhead{PodFixed: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}},
+			}
+			daemonset := test.DaemonSet(
+				test.DaemonSetOptions{PodOptions: test.PodOptions{
+					ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")}},
+				}},
+			)
+			daemonset.Spec.Template.Spec.RuntimeClassName = &runtimeClass.Name
+			ExpectApplied(ctx, env.Client, test.NodePool(), runtimeClass, daemonset)
+			pod := test.UnschedulablePod(
+				test.PodOptions{
+					ResourceRequirements: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")}},
+				},
+			)
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, forbiddenProv, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+
+			// provisioning isn't blocked, and the daemonset and the pod fit on a 4 cpu instance type without the overhead
+			allocatable := instanceTypeMap[node.Labels[v1.LabelInstanceTypeStable]].Capacity
+			Expect(*allocatable.Cpu()).To(Equal(resource.MustParse("4")))
+		})
 		It("should not schedule if resource requests are not defined and limits (requests) are too large", func() {
 			ExpectApplied(ctx, env.Client, test.NodePool(), test.DaemonSet(
 				test.DaemonSetOptions{PodOptions: test.PodOptions{
@@ -763,13 +958,13 @@ var _ = Describe("Provisioning", func() {
 							Labels: map[string]string{"test-key-1": "test-value-1"},
 						},
 						Spec: v1beta1.NodeClaimSpec{
-							Requirements: []v1.NodeSelectorRequirement{
-								{Key: "test-key-2", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value-2"}},
-								{Key: "test-key-3", Operator: v1.NodeSelectorOpNotIn, Values: []string{"test-value-3"}},
-								{Key: "test-key-4", Operator: v1.NodeSelectorOpLt, Values: []string{"4"}},
-								{Key: "test-key-5", Operator: v1.NodeSelectorOpGt, Values: []string{"5"}},
-								{Key: "test-key-6", Operator: v1.NodeSelectorOpExists},
-								{Key: "test-key-7", Operator: v1.NodeSelectorOpDoesNotExist},
+							Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{
+								{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key-2", Operator: v1.NodeSelectorOpIn, Values: []string{"test-value-2"}}},
+								{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key-3", Operator: v1.NodeSelectorOpNotIn, Values: []string{"test-value-3"}}},
+								{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key-4", Operator: v1.NodeSelectorOpLt, Values: []string{"4"}}},
+								{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key-5", Operator: v1.NodeSelectorOpGt, Values: []string{"5"}}},
+								{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key-6", Operator: v1.NodeSelectorOpExists}},
+								{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "test-key-7", Operator: v1.NodeSelectorOpDoesNotExist}},
 							},
 						},
 					},
@@ -911,17 +1106,17 @@ var _ = Describe("Provisioning", func() {
 				Spec: v1beta1.NodePoolSpec{
 					Template: v1beta1.NodeClaimTemplate{
 						Spec: v1beta1.NodeClaimSpec{
-							Requirements: []v1.NodeSelectorRequirement{
-								{
+							Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{
+								{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 									Key:      "custom-requirement-key",
 									Operator: v1.NodeSelectorOpIn,
 									Values:   []string{"value"},
-								},
-								{
+								}},
+								{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 									Key:      "custom-requirement-key2",
 									Operator: v1.NodeSelectorOpIn,
 									Values:   []string{"value"},
-								},
+								}},
 							},
 						},
 					},
@@ -961,12 +1156,12 @@ var _ = Describe("Provisioning", func() {
 				Spec: v1beta1.NodePoolSpec{
 					Template: v1beta1.NodeClaimTemplate{
 						Spec: v1beta1.NodeClaimSpec{
-							Requirements: []v1.NodeSelectorRequirement{
-								{
+							Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{
+								{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 									Key:      v1.LabelArchStable,
 									Operator: v1.NodeSelectorOpIn,
 									Values:   []string{"arm64"},
-								},
+								}},
 							},
 						},
 					},
@@ -998,12 +1193,12 @@ var _ = Describe("Provisioning", func() {
 				Spec: v1beta1.NodePoolSpec{
 					Template: v1beta1.NodeClaimTemplate{
 						Spec: v1beta1.NodeClaimSpec{
-							Requirements: []v1.NodeSelectorRequirement{
-								{
+							Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{
+								{NodeSelectorRequirement: v1.NodeSelectorRequirement{
 									Key:      v1.LabelOSStable,
 									Operator: v1.NodeSelectorOpIn,
 									Values:   []string{"ios"},
-								},
+								}},
 							},
 						},
 					},
@@ -1394,7 +1589,7 @@ var _ = Describe("Provisioning", func() {
 					Spec: v1beta1.NodePoolSpec{
 						Template: v1beta1.NodeClaimTemplate{
 							Spec: v1beta1.NodeClaimSpec{
-								Requirements: []v1.NodeSelectorRequirement{{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}},
+								Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}}},
 							},
 						},
 					},
@@ -1470,7 +1665,7 @@ var _ = Describe("Provisioning", func() {
 					Spec: v1beta1.NodePoolSpec{
 						Template: v1beta1.NodeClaimTemplate{
 							Spec: v1beta1.NodeClaimSpec{
-								Requirements: []v1.NodeSelectorRequirement{{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2"}}},
+								Requirements: []v1beta1.NodeSelectorRequirementWithMinValues{{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"test-zone-1", "test-zone-2"}}}},
 							},
 						},
 					},
@@ -1589,7 +1784,7 @@ var _ = Describe("Provisioning", func() {
 func ExpectNodeClaimRequirements(nodeClaim *v1beta1.NodeClaim, requirements ...v1.NodeSelectorRequirement) {
 	GinkgoHelper()
 	for _, requirement := range requirements {
-		req, ok := lo.Find(nodeClaim.Spec.Requirements, func(r v1.NodeSelectorRequirement) bool {
+		req, ok := lo.Find(nodeClaim.Spec.Requirements, func(r v1beta1.NodeSelectorRequirementWithMinValues) bool {
 			return r.Key == requirement.Key && r.Operator == requirement.Operator
 		})
 		Expect(ok).To(BeTrue())
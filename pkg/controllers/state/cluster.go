@@ -509,6 +509,7 @@ func (c *Cluster) populateInflight(ctx context.Context, n *StateNode) error {
 	if err != nil {
 		return err
 	}
+	instanceTypes = cloudprovider.ApplyKubeletConfiguration(instanceTypes, nodePool.Spec.Template.Spec.Kubelet)
 	instanceType, ok := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool {
 		return it.Name == n.Labels()[v1.LabelInstanceTypeStable]
 	})
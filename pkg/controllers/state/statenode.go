@@ -394,8 +394,10 @@ func RequireNoScheduleTaint(ctx context.Context, kubeClient client.Client, addTa
 			continue
 		}
 		node := &v1.Node{}
-		if err := kubeClient.Get(ctx, client.ObjectKey{Name: n.Node.Name}, node); client.IgnoreNotFound(err) != nil {
-			multiErr = multierr.Append(multiErr, fmt.Errorf("getting node, %w", err))
+		if err := kubeClient.Get(ctx, client.ObjectKey{Name: n.Node.Name}, node); err != nil {
+			// The node is already gone, so there's no taint to change
+			multiErr = multierr.Append(multiErr, client.IgnoreNotFound(fmt.Errorf("getting node, %w", err)))
+			continue
 		}
 		// If the node already has the taint, continue to the next
 		_, hasTaint := lo.Find(node.Spec.Taints, func(taint v1.Taint) bool {
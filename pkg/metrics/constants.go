@@ -28,6 +28,7 @@ const (
 	NodePoolLabel    = "nodepool"
 	ReasonLabel      = "reason"
 	TypeLabel        = "type"
+	FieldLabel       = "field"
 
 	// Reasons for CREATE/DELETE shared metrics
 	ConsolidationReason = "consolidation"
@@ -35,6 +36,7 @@ const (
 	ExpirationReason    = "expiration"
 	EmptinessReason     = "emptiness"
 	DriftReason         = "drift"
+	RepairReason        = "repair"
 )
 
 // DurationBuckets returns a []float64 of default threshold values for duration histograms.
@@ -99,10 +99,11 @@ var (
 			Namespace: Namespace,
 			Subsystem: nodeClaimSubsystem,
 			Name:      "drifted",
-			Help:      "Number of nodeclaims drifted reasons in total by Karpenter. Labeled by drift type of the nodeclaim and the owning nodepool.",
+			Help:      "Number of nodeclaims drifted reasons in total by Karpenter. Labeled by drift type of the nodeclaim, the drifted field and the owning nodepool.",
 		},
 		[]string{
 			TypeLabel,
+			FieldLabel,
 			NodePoolLabel,
 		},
 	)
@@ -24,14 +24,23 @@ import (
 	"time"
 
 	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
 	cliflag "k8s.io/component-base/cli/flag"
 
 	"github.com/aws/karpenter-core/pkg/apis/settings"
 	"github.com/aws/karpenter-core/pkg/utils/env"
 )
 
+// Bin-packing strategies that the scheduler can use to pack pods onto nodes
+const (
+	BinPackingStrategyFirstFitDecreasing = "FirstFitDecreasing"
+	BinPackingStrategyBestFit            = "BestFit"
+	BinPackingStrategyCostAware          = "CostAware"
+)
+
 var (
-	validLogLevels = []string{"", "debug", "info", "error"}
+	validLogLevels            = []string{"", "debug", "info", "error"}
+	validBinPackingStrategies = []string{BinPackingStrategyFirstFitDecreasing, BinPackingStrategyBestFit, BinPackingStrategyCostAware}
 
 	Injectables = []Injectable{&Options{}}
 )
@@ -39,29 +48,47 @@ var (
 type optionsKey struct{}
 
 type FeatureGates struct {
-	Drift    bool
-	inputStr string
+	Drift                   bool
+	SpotToSpotConsolidation bool
+	NodeRepair              bool
+	inputStr                string
+}
+
+// NodeRepairCondition is a node condition that makes a node unhealthy once the condition has had the status for longer
+// than the toleration duration
+type NodeRepairCondition struct {
+	Type               v1.NodeConditionType
+	Status             v1.ConditionStatus
+	TolerationDuration time.Duration
 }
 
 // Options contains all CLI flags / env vars for karpenter-core. It adheres to the options.Injectable interface.
 type Options struct {
-	ServiceName          string
-	DisableWebhook       bool
-	WebhookPort          int
-	MetricsPort          int
-	WebhookMetricsPort   int
-	HealthProbePort      int
-	KubeClientQPS        int
-	KubeClientBurst      int
-	EnableProfiling      bool
-	EnableLeaderElection bool
-	MemoryLimit          int64
-	LogLevel             string
-	BatchMaxDuration     time.Duration
-	BatchIdleDuration    time.Duration
-	FeatureGates         FeatureGates
-
-	setFlags map[string]bool
+	ServiceName                  string
+	DisableWebhook               bool
+	WebhookPort                  int
+	MetricsPort                  int
+	WebhookMetricsPort           int
+	HealthProbePort              int
+	KubeClientQPS                int
+	KubeClientBurst              int
+	EnableProfiling              bool
+	EnableLeaderElection         bool
+	MemoryLimit                  int64
+	LogLevel                     string
+	BatchMaxDuration             time.Duration
+	BatchIdleDuration            time.Duration
+	MaxConcurrentDisruptions     int
+	DisruptionDryRun             bool
+	DisruptionRecordTTL          time.Duration
+	NodeRepairConditions         []NodeRepairCondition
+	NodeRepairMaxPercentage      int
+	BinPackingStrategy           string
+	ExpendablePodsPriorityCutoff int
+	FeatureGates                 FeatureGates
+
+	nodeRepairConditionsStr string
+	setFlags                map[string]bool
 }
 
 type FlagSet struct {
@@ -96,7 +123,14 @@ func (o *Options) AddFlags(fs *FlagSet) {
 	fs.StringVar(&o.LogLevel, "log-level", env.WithDefaultString("LOG_LEVEL", ""), "Log verbosity level. Can be one of 'debug', 'info', or 'error'")
 	fs.DurationVar(&o.BatchMaxDuration, "batch-max-duration", env.WithDefaultDuration("BATCH_MAX_DURATION", 10*time.Second), "The maximum length of a batch window. The longer this is, the more pods we can consider for provisioning at one time which usually results in fewer but larger nodes.")
 	fs.DurationVar(&o.BatchIdleDuration, "batch-idle-duration", env.WithDefaultDuration("BATCH_IDLE_DURATION", time.Second), "The maximum amount of time with no new pending pods that if exceeded ends the current batching window. If pods arrive faster than this time, the batching window will be extended up to the maxDuration. If they arrive slower, the pods will be batched separately.")
-	fs.StringVar(&o.FeatureGates.inputStr, "feature-gates", env.WithDefaultString("FEATURE_GATES", "Drift=false"), "Optional features can be enabled / disabled using feature gates. Current options are: Drift")
+	fs.IntVar(&o.MaxConcurrentDisruptions, "max-concurrent-disruptions", env.WithDefaultInt("MAX_CONCURRENT_DISRUPTIONS", 10), "The maximum number of disruption commands that can execute in parallel across all NodePools. Commands are only executed in parallel if they disrupt different nodes.")
+	fs.BoolVarWithEnv(&o.DisruptionDryRun, "disruption-dry-run", "DISRUPTION_DRY_RUN", false, "Compute disruption decisions without executing them. Decisions are published as events, logs and metrics instead. NodePools can override this with spec.disruption.dryRun.")
+	fs.DurationVar(&o.DisruptionRecordTTL, "disruption-record-ttl", env.WithDefaultDuration("DISRUPTION_RECORD_TTL", 7*24*time.Hour), "The amount of time that a DisruptionRecord is retained after the disruption command it describes has finished.")
+	fs.StringVar(&o.nodeRepairConditionsStr, "node-repair-conditions", env.WithDefaultString("NODE_REPAIR_CONDITIONS", "Ready=False:30m,Ready=Unknown:30m"), "The node conditions that make a node unhealthy when the NodeRepair feature gate is enabled, as a comma-separated list of <type>=<status>:<toleration duration>. Unhealthy nodes are replaced once a condition has had the status for longer than its toleration duration.")
+	fs.IntVar(&o.NodeRepairMaxPercentage, "node-repair-max-percentage", env.WithDefaultInt("NODE_REPAIR_MAX_PERCENTAGE", 20), "The maximum percentage of the nodes in the cluster that can be repaired at once.")
+	fs.StringVar(&o.BinPackingStrategy, "bin-packing-strategy", env.WithDefaultString("BIN_PACKING_STRATEGY", BinPackingStrategyFirstFitDecreasing), "The strategy that the scheduler uses to pack pods onto nodes. Can be one of 'FirstFitDecreasing', 'BestFit', or 'CostAware'. NodePools can override this with spec.binPackingStrategy.")
+	fs.IntVar(&o.ExpendablePodsPriorityCutoff, "expendable-pods-priority-cutoff", env.WithDefaultInt("EXPENDABLE_PODS_PRIORITY_CUTOFF", -10), "Pods with a priority below the cutoff are expendable. When the scheduler expects pending pods to preempt expendable pods, it doesn't launch capacity for the preempted pods.")
+	fs.StringVar(&o.FeatureGates.inputStr, "feature-gates", env.WithDefaultString("FEATURE_GATES", "Drift=false,SpotToSpotConsolidation=false,NodeRepair=false"), "Optional features can be enabled / disabled using feature gates. Current options are: Drift, SpotToSpotConsolidation, NodeRepair")
 }
 
 func (o *Options) Parse(fs *FlagSet, args ...string) error {
@@ -110,6 +144,23 @@ func (o *Options) Parse(fs *FlagSet, args ...string) error {
 	if !lo.Contains(validLogLevels, o.LogLevel) {
 		return fmt.Errorf("validating cli flags / env vars, invalid log level %q", o.LogLevel)
 	}
+	if o.MaxConcurrentDisruptions < 1 {
+		return fmt.Errorf("validating cli flags / env vars, max-concurrent-disruptions must be greater than 0, got %d", o.MaxConcurrentDisruptions)
+	}
+	if o.DisruptionRecordTTL <= 0 {
+		return fmt.Errorf("validating cli flags / env vars, disruption-record-ttl must be greater than 0, got %s", o.DisruptionRecordTTL)
+	}
+	if o.NodeRepairMaxPercentage < 0 || o.NodeRepairMaxPercentage > 100 {
+		return fmt.Errorf("validating cli flags / env vars, node-repair-max-percentage must be between 0 and 100, got %d", o.NodeRepairMaxPercentage)
+	}
+	if !lo.Contains(validBinPackingStrategies, o.BinPackingStrategy) {
+		return fmt.Errorf("validating cli flags / env vars, invalid bin-packing-strategy %q", o.BinPackingStrategy)
+	}
+	conditions, err := ParseNodeRepairConditions(o.nodeRepairConditionsStr)
+	if err != nil {
+		return fmt.Errorf("parsing node repair conditions, %w", err)
+	}
+	o.NodeRepairConditions = conditions
 	gates, err := ParseFeatureGates(o.FeatureGates.inputStr)
 	if err != nil {
 		return fmt.Errorf("parsing feature gates, %w", err)
@@ -160,10 +211,51 @@ func ParseFeatureGates(gateStr string) (FeatureGates, error) {
 	if val, ok := gateMap["Drift"]; ok {
 		gates.Drift = val
 	}
+	if val, ok := gateMap["SpotToSpotConsolidation"]; ok {
+		gates.SpotToSpotConsolidation = val
+	}
+	if val, ok := gateMap["NodeRepair"]; ok {
+		gates.NodeRepair = val
+	}
 
 	return gates, nil
 }
 
+// ParseNodeRepairConditions parses a comma-separated list of node repair conditions in the form
+// <type>=<status>:<toleration duration>, e.g. Ready=False:30m
+func ParseNodeRepairConditions(conditionStr string) ([]NodeRepairCondition, error) {
+	var conditions []NodeRepairCondition
+	for _, s := range strings.Split(conditionStr, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		conditionType, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q must be in the form <type>=<status>:<toleration duration>", s)
+		}
+		status, duration, ok := strings.Cut(rest, ":")
+		if !ok {
+			return nil, fmt.Errorf("%q must be in the form <type>=<status>:<toleration duration>", s)
+		}
+		if !lo.Contains([]v1.ConditionStatus{v1.ConditionTrue, v1.ConditionFalse, v1.ConditionUnknown}, v1.ConditionStatus(status)) {
+			return nil, fmt.Errorf("%q has an invalid status %q, must be one of True, False or Unknown", s, status)
+		}
+		tolerationDuration, err := time.ParseDuration(duration)
+		if err != nil {
+			return nil, fmt.Errorf("%q has an invalid toleration duration, %w", s, err)
+		}
+		if tolerationDuration < 0 {
+			return nil, fmt.Errorf("%q has a negative toleration duration", s)
+		}
+		conditions = append(conditions, NodeRepairCondition{
+			Type:               v1.NodeConditionType(conditionType),
+			Status:             v1.ConditionStatus(status),
+			TolerationDuration: tolerationDuration,
+		})
+	}
+	return conditions, nil
+}
+
 func ToContext(ctx context.Context, opts *Options) context.Context {
 	return context.WithValue(ctx, optionsKey{}, opts)
 }
@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
 	. "knative.dev/pkg/logging/testing"
 
 	"github.com/aws/karpenter-core/pkg/apis/settings"
@@ -57,6 +58,13 @@ var _ = Describe("Options", func() {
 		"LOG_LEVEL",
 		"BATCH_MAX_DURATION",
 		"BATCH_IDLE_DURATION",
+		"MAX_CONCURRENT_DISRUPTIONS",
+		"DISRUPTION_DRY_RUN",
+		"DISRUPTION_RECORD_TTL",
+		"NODE_REPAIR_CONDITIONS",
+		"NODE_REPAIR_MAX_PERCENTAGE",
+		"BIN_PACKING_STRATEGY",
+		"EXPENDABLE_PODS_PRIORITY_CUTOFF",
 		"FEATURE_GATES",
 	}
 
@@ -99,6 +107,59 @@ var _ = Describe("Options", func() {
 			Entry("with whitespace", "Drift\t= false", false),
 			Entry("multiple values", "Hello=true,Drift=false,World=true", false),
 		)
+		DescribeTable(
+			"should successfully parse the SpotToSpotConsolidation feature gate",
+			func(str string, spotToSpotVal bool) {
+				gates, err := options.ParseFeatureGates(str)
+				Expect(err).To(BeNil())
+				Expect(gates.SpotToSpotConsolidation).To(Equal(spotToSpotVal))
+			},
+			Entry("basic true", "SpotToSpotConsolidation=true", true),
+			Entry("basic false", "SpotToSpotConsolidation=false", false),
+			Entry("unset", "Drift=true", false),
+			Entry("multiple values", "Drift=false,SpotToSpotConsolidation=true", true),
+		)
+		DescribeTable(
+			"should successfully parse the NodeRepair feature gate",
+			func(str string, nodeRepairVal bool) {
+				gates, err := options.ParseFeatureGates(str)
+				Expect(err).To(BeNil())
+				Expect(gates.NodeRepair).To(Equal(nodeRepairVal))
+			},
+			Entry("basic true", "NodeRepair=true", true),
+			Entry("basic false", "NodeRepair=false", false),
+			Entry("unset", "Drift=true", false),
+			Entry("multiple values", "Drift=false,NodeRepair=true", true),
+		)
+	})
+
+	Context("NodeRepairConditions", func() {
+		It("should successfully parse well formed node repair conditions", func() {
+			conditions, err := options.ParseNodeRepairConditions("Ready=False:10m, Ready=Unknown:15m,KernelDeadlock=True:0s")
+			Expect(err).To(BeNil())
+			Expect(conditions).To(Equal([]options.NodeRepairCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionFalse, TolerationDuration: 10 * time.Minute},
+				{Type: v1.NodeReady, Status: v1.ConditionUnknown, TolerationDuration: 15 * time.Minute},
+				{Type: "KernelDeadlock", Status: v1.ConditionTrue, TolerationDuration: 0},
+			}))
+		})
+		It("should successfully parse empty node repair conditions", func() {
+			conditions, err := options.ParseNodeRepairConditions("")
+			Expect(err).To(BeNil())
+			Expect(conditions).To(BeEmpty())
+		})
+		DescribeTable(
+			"should fail to parse malformed node repair conditions",
+			func(str string) {
+				_, err := options.ParseNodeRepairConditions(str)
+				Expect(err).ToNot(BeNil())
+			},
+			Entry("missing status", "Ready:10m"),
+			Entry("missing toleration duration", "Ready=False"),
+			Entry("invalid status", "Ready=Maybe:10m"),
+			Entry("invalid toleration duration", "Ready=False:ten"),
+			Entry("negative toleration duration", "Ready=False:-10m"),
+		)
 	})
 
 	Context("Parse", func() {
@@ -106,22 +167,34 @@ var _ = Describe("Options", func() {
 			err := opts.Parse(fs)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:          lo.ToPtr(""),
-				DisableWebhook:       lo.ToPtr(false),
-				WebhookPort:          lo.ToPtr(8443),
-				MetricsPort:          lo.ToPtr(8000),
-				WebhookMetricsPort:   lo.ToPtr(8001),
-				HealthProbePort:      lo.ToPtr(8081),
-				KubeClientQPS:        lo.ToPtr(200),
-				KubeClientBurst:      lo.ToPtr(300),
-				EnableProfiling:      lo.ToPtr(false),
-				EnableLeaderElection: lo.ToPtr(true),
-				MemoryLimit:          lo.ToPtr[int64](-1),
-				LogLevel:             lo.ToPtr(""),
-				BatchMaxDuration:     lo.ToPtr(10 * time.Second),
-				BatchIdleDuration:    lo.ToPtr(time.Second),
+				ServiceName:              lo.ToPtr(""),
+				DisableWebhook:           lo.ToPtr(false),
+				WebhookPort:              lo.ToPtr(8443),
+				MetricsPort:              lo.ToPtr(8000),
+				WebhookMetricsPort:       lo.ToPtr(8001),
+				HealthProbePort:          lo.ToPtr(8081),
+				KubeClientQPS:            lo.ToPtr(200),
+				KubeClientBurst:          lo.ToPtr(300),
+				EnableProfiling:          lo.ToPtr(false),
+				EnableLeaderElection:     lo.ToPtr(true),
+				MemoryLimit:              lo.ToPtr[int64](-1),
+				LogLevel:                 lo.ToPtr(""),
+				BatchMaxDuration:         lo.ToPtr(10 * time.Second),
+				BatchIdleDuration:        lo.ToPtr(time.Second),
+				MaxConcurrentDisruptions: lo.ToPtr(10),
+				DisruptionDryRun:         lo.ToPtr(false),
+				DisruptionRecordTTL:      lo.ToPtr(168 * time.Hour),
+				NodeRepairConditions: []options.NodeRepairCondition{
+					{Type: v1.NodeReady, Status: v1.ConditionFalse, TolerationDuration: 30 * time.Minute},
+					{Type: v1.NodeReady, Status: v1.ConditionUnknown, TolerationDuration: 30 * time.Minute},
+				},
+				NodeRepairMaxPercentage:      lo.ToPtr(20),
+				BinPackingStrategy:           lo.ToPtr("FirstFitDecreasing"),
+				ExpendablePodsPriorityCutoff: lo.ToPtr(-10),
 				FeatureGates: test.FeatureGates{
-					Drift: lo.ToPtr(false),
+					Drift:                   lo.ToPtr(false),
+					SpotToSpotConsolidation: lo.ToPtr(false),
+					NodeRepair:              lo.ToPtr(false),
 				},
 			}))
 		})
@@ -143,26 +216,44 @@ var _ = Describe("Options", func() {
 				"--log-level", "debug",
 				"--batch-max-duration", "5s",
 				"--batch-idle-duration", "5s",
-				"--feature-gates", "Drift=true",
+				"--max-concurrent-disruptions", "5",
+				"--disruption-dry-run",
+				"--disruption-record-ttl", "24h",
+				"--node-repair-conditions", "Ready=False:5m",
+				"--node-repair-max-percentage", "50",
+				"--bin-packing-strategy", "BestFit",
+				"--expendable-pods-priority-cutoff", "-100",
+				"--feature-gates", "Drift=true,SpotToSpotConsolidation=true,NodeRepair=true",
 			)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:          lo.ToPtr("cli"),
-				DisableWebhook:       lo.ToPtr(true),
-				WebhookPort:          lo.ToPtr(0),
-				MetricsPort:          lo.ToPtr(0),
-				WebhookMetricsPort:   lo.ToPtr(0),
-				HealthProbePort:      lo.ToPtr(0),
-				KubeClientQPS:        lo.ToPtr(0),
-				KubeClientBurst:      lo.ToPtr(0),
-				EnableProfiling:      lo.ToPtr(true),
-				EnableLeaderElection: lo.ToPtr(false),
-				MemoryLimit:          lo.ToPtr[int64](0),
-				LogLevel:             lo.ToPtr("debug"),
-				BatchMaxDuration:     lo.ToPtr(5 * time.Second),
-				BatchIdleDuration:    lo.ToPtr(5 * time.Second),
+				ServiceName:              lo.ToPtr("cli"),
+				DisableWebhook:           lo.ToPtr(true),
+				WebhookPort:              lo.ToPtr(0),
+				MetricsPort:              lo.ToPtr(0),
+				WebhookMetricsPort:       lo.ToPtr(0),
+				HealthProbePort:          lo.ToPtr(0),
+				KubeClientQPS:            lo.ToPtr(0),
+				KubeClientBurst:          lo.ToPtr(0),
+				EnableProfiling:          lo.ToPtr(true),
+				EnableLeaderElection:     lo.ToPtr(false),
+				MemoryLimit:              lo.ToPtr[int64](0),
+				LogLevel:                 lo.ToPtr("debug"),
+				BatchMaxDuration:         lo.ToPtr(5 * time.Second),
+				BatchIdleDuration:        lo.ToPtr(5 * time.Second),
+				MaxConcurrentDisruptions: lo.ToPtr(5),
+				DisruptionDryRun:         lo.ToPtr(true),
+				DisruptionRecordTTL:      lo.ToPtr(24 * time.Hour),
+				NodeRepairConditions: []options.NodeRepairCondition{
+					{Type: v1.NodeReady, Status: v1.ConditionFalse, TolerationDuration: 5 * time.Minute},
+				},
+				NodeRepairMaxPercentage:      lo.ToPtr(50),
+				BinPackingStrategy:           lo.ToPtr("BestFit"),
+				ExpendablePodsPriorityCutoff: lo.ToPtr(-100),
 				FeatureGates: test.FeatureGates{
-					Drift: lo.ToPtr(true),
+					Drift:                   lo.ToPtr(true),
+					SpotToSpotConsolidation: lo.ToPtr(true),
+					NodeRepair:              lo.ToPtr(true),
 				},
 			}))
 		})
@@ -182,7 +273,14 @@ var _ = Describe("Options", func() {
 			os.Setenv("LOG_LEVEL", "debug")
 			os.Setenv("BATCH_MAX_DURATION", "5s")
 			os.Setenv("BATCH_IDLE_DURATION", "5s")
-			os.Setenv("FEATURE_GATES", "Drift=true")
+			os.Setenv("MAX_CONCURRENT_DISRUPTIONS", "5")
+			os.Setenv("DISRUPTION_DRY_RUN", "true")
+			os.Setenv("DISRUPTION_RECORD_TTL", "24h")
+			os.Setenv("NODE_REPAIR_CONDITIONS", "Ready=False:5m")
+			os.Setenv("NODE_REPAIR_MAX_PERCENTAGE", "50")
+			os.Setenv("BIN_PACKING_STRATEGY", "BestFit")
+			os.Setenv("EXPENDABLE_PODS_PRIORITY_CUTOFF", "-100")
+			os.Setenv("FEATURE_GATES", "Drift=true,SpotToSpotConsolidation=true,NodeRepair=true")
 			fs = &options.FlagSet{
 				FlagSet: flag.NewFlagSet("karpenter", flag.ContinueOnError),
 			}
@@ -190,22 +288,33 @@ var _ = Describe("Options", func() {
 			err := opts.Parse(fs)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:          lo.ToPtr("env"),
-				DisableWebhook:       lo.ToPtr(true),
-				WebhookPort:          lo.ToPtr(0),
-				MetricsPort:          lo.ToPtr(0),
-				WebhookMetricsPort:   lo.ToPtr(0),
-				HealthProbePort:      lo.ToPtr(0),
-				KubeClientQPS:        lo.ToPtr(0),
-				KubeClientBurst:      lo.ToPtr(0),
-				EnableProfiling:      lo.ToPtr(true),
-				EnableLeaderElection: lo.ToPtr(false),
-				MemoryLimit:          lo.ToPtr[int64](0),
-				LogLevel:             lo.ToPtr("debug"),
-				BatchMaxDuration:     lo.ToPtr(5 * time.Second),
-				BatchIdleDuration:    lo.ToPtr(5 * time.Second),
+				ServiceName:              lo.ToPtr("env"),
+				DisableWebhook:           lo.ToPtr(true),
+				WebhookPort:              lo.ToPtr(0),
+				MetricsPort:              lo.ToPtr(0),
+				WebhookMetricsPort:       lo.ToPtr(0),
+				HealthProbePort:          lo.ToPtr(0),
+				KubeClientQPS:            lo.ToPtr(0),
+				KubeClientBurst:          lo.ToPtr(0),
+				EnableProfiling:          lo.ToPtr(true),
+				EnableLeaderElection:     lo.ToPtr(false),
+				MemoryLimit:              lo.ToPtr[int64](0),
+				LogLevel:                 lo.ToPtr("debug"),
+				BatchMaxDuration:         lo.ToPtr(5 * time.Second),
+				BatchIdleDuration:        lo.ToPtr(5 * time.Second),
+				MaxConcurrentDisruptions: lo.ToPtr(5),
+				DisruptionDryRun:         lo.ToPtr(true),
+				DisruptionRecordTTL:      lo.ToPtr(24 * time.Hour),
+				NodeRepairConditions: []options.NodeRepairCondition{
+					{Type: v1.NodeReady, Status: v1.ConditionFalse, TolerationDuration: 5 * time.Minute},
+				},
+				NodeRepairMaxPercentage:      lo.ToPtr(50),
+				BinPackingStrategy:           lo.ToPtr("BestFit"),
+				ExpendablePodsPriorityCutoff: lo.ToPtr(-100),
 				FeatureGates: test.FeatureGates{
-					Drift: lo.ToPtr(true),
+					Drift:                   lo.ToPtr(true),
+					SpotToSpotConsolidation: lo.ToPtr(true),
+					NodeRepair:              lo.ToPtr(true),
 				},
 			}))
 		})
@@ -223,7 +332,14 @@ var _ = Describe("Options", func() {
 			os.Setenv("LOG_LEVEL", "debug")
 			os.Setenv("BATCH_MAX_DURATION", "5s")
 			os.Setenv("BATCH_IDLE_DURATION", "5s")
-			os.Setenv("FEATURE_GATES", "Drift=true")
+			os.Setenv("MAX_CONCURRENT_DISRUPTIONS", "5")
+			os.Setenv("DISRUPTION_DRY_RUN", "true")
+			os.Setenv("DISRUPTION_RECORD_TTL", "24h")
+			os.Setenv("NODE_REPAIR_CONDITIONS", "Ready=False:5m")
+			os.Setenv("NODE_REPAIR_MAX_PERCENTAGE", "50")
+			os.Setenv("BIN_PACKING_STRATEGY", "BestFit")
+			os.Setenv("EXPENDABLE_PODS_PRIORITY_CUTOFF", "-100")
+			os.Setenv("FEATURE_GATES", "Drift=true,SpotToSpotConsolidation=true,NodeRepair=true")
 			fs = &options.FlagSet{
 				FlagSet: flag.NewFlagSet("karpenter", flag.ContinueOnError),
 			}
@@ -235,22 +351,33 @@ var _ = Describe("Options", func() {
 			)
 			Expect(err).To(BeNil())
 			expectOptionsMatch(opts, test.Options(test.OptionsFields{
-				ServiceName:          lo.ToPtr("cli"),
-				DisableWebhook:       lo.ToPtr(true),
-				WebhookPort:          lo.ToPtr(0),
-				MetricsPort:          lo.ToPtr(0),
-				WebhookMetricsPort:   lo.ToPtr(0),
-				HealthProbePort:      lo.ToPtr(0),
-				KubeClientQPS:        lo.ToPtr(0),
-				KubeClientBurst:      lo.ToPtr(0),
-				EnableProfiling:      lo.ToPtr(true),
-				EnableLeaderElection: lo.ToPtr(false),
-				MemoryLimit:          lo.ToPtr[int64](0),
-				LogLevel:             lo.ToPtr("debug"),
-				BatchMaxDuration:     lo.ToPtr(5 * time.Second),
-				BatchIdleDuration:    lo.ToPtr(5 * time.Second),
+				ServiceName:              lo.ToPtr("cli"),
+				DisableWebhook:           lo.ToPtr(true),
+				WebhookPort:              lo.ToPtr(0),
+				MetricsPort:              lo.ToPtr(0),
+				WebhookMetricsPort:       lo.ToPtr(0),
+				HealthProbePort:          lo.ToPtr(0),
+				KubeClientQPS:            lo.ToPtr(0),
+				KubeClientBurst:          lo.ToPtr(0),
+				EnableProfiling:          lo.ToPtr(true),
+				EnableLeaderElection:     lo.ToPtr(false),
+				MemoryLimit:              lo.ToPtr[int64](0),
+				LogLevel:                 lo.ToPtr("debug"),
+				BatchMaxDuration:         lo.ToPtr(5 * time.Second),
+				BatchIdleDuration:        lo.ToPtr(5 * time.Second),
+				MaxConcurrentDisruptions: lo.ToPtr(5),
+				DisruptionDryRun:         lo.ToPtr(true),
+				DisruptionRecordTTL:      lo.ToPtr(24 * time.Hour),
+				NodeRepairConditions: []options.NodeRepairCondition{
+					{Type: v1.NodeReady, Status: v1.ConditionFalse, TolerationDuration: 5 * time.Minute},
+				},
+				NodeRepairMaxPercentage:      lo.ToPtr(50),
+				BinPackingStrategy:           lo.ToPtr("BestFit"),
+				ExpendablePodsPriorityCutoff: lo.ToPtr(-100),
 				FeatureGates: test.FeatureGates{
-					Drift: lo.ToPtr(true),
+					Drift:                   lo.ToPtr(true),
+					SpotToSpotConsolidation: lo.ToPtr(true),
+					NodeRepair:              lo.ToPtr(true),
 				},
 			}))
 		})
@@ -320,6 +447,36 @@ var _ = Describe("Options", func() {
 			err := opts.Parse(fs, "--log-level", "hello")
 			Expect(err).ToNot(BeNil())
 		})
+		It("should error with a max-concurrent-disruptions less than 1", func() {
+			err := opts.Parse(fs, "--max-concurrent-disruptions", "0")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a non-positive disruption-record-ttl", func() {
+			err := opts.Parse(fs, "--disruption-record-ttl", "0s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a node-repair-max-percentage outside of 0 to 100", func() {
+			err := opts.Parse(fs, "--node-repair-max-percentage", "101")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with malformed node-repair-conditions", func() {
+			err := opts.Parse(fs, "--node-repair-conditions", "Ready")
+			Expect(err).ToNot(BeNil())
+		})
+		DescribeTable(
+			"should parse valid bin-packing strategies successfully",
+			func(strategy string) {
+				err := opts.Parse(fs, "--bin-packing-strategy", strategy)
+				Expect(err).To(BeNil())
+			},
+			Entry("FirstFitDecreasing", "FirstFitDecreasing"),
+			Entry("BestFit", "BestFit"),
+			Entry("CostAware", "CostAware"),
+		)
+		It("should error with an invalid bin-packing-strategy", func() {
+			err := opts.Parse(fs, "--bin-packing-strategy", "WorstFit")
+			Expect(err).ToNot(BeNil())
+		})
 	})
 })
 
@@ -344,5 +501,14 @@ func expectOptionsMatch(optsA, optsB *options.Options) {
 	Expect(optsA.LogLevel).To(Equal(optsB.LogLevel))
 	Expect(optsA.BatchMaxDuration).To(Equal(optsB.BatchMaxDuration))
 	Expect(optsA.BatchIdleDuration).To(Equal(optsB.BatchIdleDuration))
+	Expect(optsA.MaxConcurrentDisruptions).To(Equal(optsB.MaxConcurrentDisruptions))
+	Expect(optsA.DisruptionDryRun).To(Equal(optsB.DisruptionDryRun))
+	Expect(optsA.DisruptionRecordTTL).To(Equal(optsB.DisruptionRecordTTL))
+	Expect(optsA.NodeRepairConditions).To(Equal(optsB.NodeRepairConditions))
+	Expect(optsA.NodeRepairMaxPercentage).To(Equal(optsB.NodeRepairMaxPercentage))
+	Expect(optsA.BinPackingStrategy).To(Equal(optsB.BinPackingStrategy))
+	Expect(optsA.ExpendablePodsPriorityCutoff).To(Equal(optsB.ExpendablePodsPriorityCutoff))
 	Expect(optsA.FeatureGates.Drift).To(Equal(optsB.FeatureGates.Drift))
+	Expect(optsA.FeatureGates.SpotToSpotConsolidation).To(Equal(optsB.FeatureGates.SpotToSpotConsolidation))
+	Expect(optsA.FeatureGates.NodeRepair).To(Equal(optsB.FeatureGates.NodeRepair))
 }
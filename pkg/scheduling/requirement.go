@@ -34,9 +34,17 @@ type Requirement struct {
 	values      sets.Set[string]
 	greaterThan *int
 	lessThan    *int
+	// MinValues is the minimum number of unique values of the key that must remain available
+	MinValues *int
 }
 
 func NewRequirement(key string, operator v1.NodeSelectorOperator, values ...string) *Requirement {
+	return NewRequirementWithFlexibility(key, operator, nil, values...)
+}
+
+// NewRequirementWithFlexibility constructs a requirement that requires at least minValues unique values of the key
+// to remain available
+func NewRequirementWithFlexibility(key string, operator v1.NodeSelectorOperator, minValues *int, values ...string) *Requirement {
 	if normalized, ok := v1beta1.NormalizedLabels[key]; ok {
 		key = normalized
 	}
@@ -51,6 +59,7 @@ func NewRequirement(key string, operator v1.NodeSelectorOperator, values ...stri
 			Key:        key,
 			values:     s,
 			complement: false,
+			MinValues:  minValues,
 		}
 	}
 
@@ -58,6 +67,7 @@ func NewRequirement(key string, operator v1.NodeSelectorOperator, values ...stri
 		Key:        key,
 		values:     sets.New[string](),
 		complement: true,
+		MinValues:  minValues,
 	}
 	if operator == v1.NodeSelectorOpIn || operator == v1.NodeSelectorOpDoesNotExist {
 		r.complement = false
@@ -155,7 +165,7 @@ func (r *Requirement) Intersection(requirement *Requirement) *Requirement {
 		greaterThan, lessThan = nil, nil
 	}
 
-	return &Requirement{Key: r.Key, values: values, complement: complement, greaterThan: greaterThan, lessThan: lessThan}
+	return &Requirement{Key: r.Key, values: values, complement: complement, greaterThan: greaterThan, lessThan: lessThan, MinValues: maxIntPtr(r.MinValues, requirement.MinValues)}
 }
 
 func (r *Requirement) Any() string {
@@ -230,6 +240,9 @@ func (r *Requirement) String() string {
 	if r.lessThan != nil {
 		s += fmt.Sprintf(" <%d", *r.lessThan)
 	}
+	if r.MinValues != nil {
+		s += fmt.Sprintf(" minValues %d", *r.MinValues)
+	}
 	return s
 }
 
@@ -51,6 +51,15 @@ func NewNodeSelectorRequirements(requirements ...v1.NodeSelectorRequirement) Req
 	return r
 }
 
+// NewNodeSelectorRequirementsWithMinValues constructs requirements from NodeSelectorRequirementWithMinValues
+func NewNodeSelectorRequirementsWithMinValues(requirements ...v1beta1.NodeSelectorRequirementWithMinValues) Requirements {
+	r := NewRequirements()
+	for _, requirement := range requirements {
+		r.Add(NewRequirementWithFlexibility(requirement.Key, requirement.Operator, requirement.MinValues, requirement.Values...))
+	}
+	return r
+}
+
 // NewLabelRequirements constructs requirements from labels
 func NewLabelRequirements(labels map[string]string) Requirements {
 	requirements := NewRequirements()
@@ -107,9 +116,19 @@ func HasPreferredNodeAffinity(p *v1.Pod) bool {
 	return p.Spec.Affinity != nil && p.Spec.Affinity.NodeAffinity != nil && len(p.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution) > 0
 }
 
-func (r Requirements) NodeSelectorRequirements() []v1.NodeSelectorRequirement {
-	return lo.Map(lo.Values(r), func(req *Requirement, _ int) v1.NodeSelectorRequirement {
-		return req.NodeSelectorRequirement()
+func (r Requirements) NodeSelectorRequirements() []v1beta1.NodeSelectorRequirementWithMinValues {
+	return lo.Map(lo.Values(r), func(req *Requirement, _ int) v1beta1.NodeSelectorRequirementWithMinValues {
+		return v1beta1.NodeSelectorRequirementWithMinValues{
+			NodeSelectorRequirement: req.NodeSelectorRequirement(),
+			MinValues:               req.MinValues,
+		}
+	})
+}
+
+// HasMinValues returns true if any of the requirements require a minimum number of unique values
+func (r Requirements) HasMinValues() bool {
+	return lo.ContainsBy(lo.Values(r), func(req *Requirement) bool {
+		return req.MinValues != nil
 	})
 }
 
@@ -172,6 +191,18 @@ func (r Requirements) Compatible(requirements Requirements, options ...functiona
 	return multierr.Append(errs, r.Intersects(requirements))
 }
 
+// IncompatibleKeys returns the sorted keys of the requirements that make them incompatible, as reported by Compatible
+func (r Requirements) IncompatibleKeys(requirements Requirements, options ...functional.Option[CompatabilityOptions]) []string {
+	var keys []string
+	for key := range requirements {
+		if err := r.Compatible(NewRequirements(requirements[key]), options...); err != nil {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
 // editDistance is an implementation of edit distance from Algorithms/DPV
 func editDistance(s, t string) int {
 	min := func(a, b, c int) int {
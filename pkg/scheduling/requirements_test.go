@@ -19,7 +19,10 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
 )
 
 var _ = Describe("Requirements", func() {
@@ -533,6 +536,20 @@ var _ = Describe("Requirements", func() {
 			Expect(lessThan9.Compatible(lessThan1)).To(Succeed())
 			Expect(lessThan9.Compatible(lessThan9)).To(Succeed())
 		})
+		It("should return the keys of the incompatible requirements", func() {
+			requirements := NewRequirements(
+				NewRequirement(v1.LabelTopologyZone, v1.NodeSelectorOpIn, "A"),
+				NewRequirement(v1.LabelArchStable, v1.NodeSelectorOpIn, "amd64"),
+			)
+			incoming := NewRequirements(
+				NewRequirement(v1.LabelTopologyZone, v1.NodeSelectorOpIn, "B"),
+				NewRequirement(v1.LabelArchStable, v1.NodeSelectorOpIn, "amd64"),
+				NewRequirement(v1.LabelInstanceTypeStable, v1.NodeSelectorOpIn, "large"),
+				NewRequirement("custom", v1.NodeSelectorOpExists),
+			)
+			Expect(requirements.IncompatibleKeys(incoming, AllowUndefinedWellKnownLabels)).To(Equal([]string{"custom", v1.LabelTopologyZone}))
+			Expect(requirements.IncompatibleKeys(requirements, AllowUndefinedWellKnownLabels)).To(BeEmpty())
+		})
 	})
 	Context("Error Messages", func() {
 		DescribeTable("should detect well known label truncations", func(badLabel, expectedError string) {
@@ -599,7 +616,9 @@ var _ = Describe("Requirements", func() {
 				lessThan1,
 				lessThan9,
 			)
-			Expect(reqs.NodeSelectorRequirements()).To(ContainElements(
+			Expect(lo.Map(reqs.NodeSelectorRequirements(), func(r v1beta1.NodeSelectorRequirementWithMinValues, _ int) v1.NodeSelectorRequirement {
+				return r.NodeSelectorRequirement
+			})).To(ContainElements(
 				v1.NodeSelectorRequirement{Key: "exists", Operator: v1.NodeSelectorOpExists},
 				v1.NodeSelectorRequirement{Key: "doesNotExist", Operator: v1.NodeSelectorOpDoesNotExist},
 				v1.NodeSelectorRequirement{Key: "inA", Operator: v1.NodeSelectorOpIn, Values: []string{"A"}},
@@ -617,6 +636,39 @@ var _ = Describe("Requirements", func() {
 			))
 			Expect(reqs.NodeSelectorRequirements()).To(HaveLen(14))
 		})
+		It("should convert to and from NodeSelectorRequirementWithMinValues", func() {
+			reqs := NewNodeSelectorRequirementsWithMinValues(
+				v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "inAB", Operator: v1.NodeSelectorOpIn, Values: []string{"A", "B"}}, MinValues: lo.ToPtr(2)},
+				v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "exists", Operator: v1.NodeSelectorOpExists}},
+			)
+			Expect(reqs.HasMinValues()).To(BeTrue())
+			Expect(reqs.NodeSelectorRequirements()).To(ConsistOf(
+				v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "inAB", Operator: v1.NodeSelectorOpIn, Values: []string{"A", "B"}}, MinValues: lo.ToPtr(2)},
+				v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: "exists", Operator: v1.NodeSelectorOpExists}},
+			))
+		})
+	})
+	Context("MinValues", func() {
+		It("should not have minValues by default", func() {
+			reqs := NewRequirements(NewRequirement("inAB", v1.NodeSelectorOpIn, "A", "B"))
+			Expect(reqs.HasMinValues()).To(BeFalse())
+		})
+		It("should keep the largest minValues when requirements are added", func() {
+			reqs := NewRequirements(NewRequirementWithFlexibility("key", v1.NodeSelectorOpIn, lo.ToPtr(2), "A", "B", "C"))
+			reqs.Add(NewRequirementWithFlexibility("key", v1.NodeSelectorOpIn, lo.ToPtr(3), "A", "B", "C", "D"))
+			Expect(reqs.Get("key").MinValues).To(Equal(lo.ToPtr(3)))
+			Expect(reqs.Get("key").Values()).To(ConsistOf("A", "B", "C"))
+		})
+		It("should keep minValues when intersecting with a requirement without minValues", func() {
+			reqs := NewRequirements(NewRequirementWithFlexibility("key", v1.NodeSelectorOpExists, lo.ToPtr(2)))
+			reqs.Add(NewRequirement("key", v1.NodeSelectorOpIn, "A", "B"))
+			Expect(reqs.Get("key").MinValues).To(Equal(lo.ToPtr(2)))
+			Expect(reqs.HasMinValues()).To(BeTrue())
+		})
+		It("should print minValues", func() {
+			reqs := NewRequirements(NewRequirementWithFlexibility("inAB", v1.NodeSelectorOpIn, lo.ToPtr(2), "A", "B"))
+			Expect(reqs.String()).To(Equal("inAB In [A B] minValues 2"))
+		})
 	})
 	Context("Stringify Requirements", func() {
 		It("should print Requirements in the same order", func() {
@@ -198,6 +198,8 @@ func ExpectCleanedUp(ctx context.Context, c client.Client) {
 		&storagev1.StorageClass{},
 		&v1beta1.NodePool{},
 		&v1beta1.NodeClaim{},
+		&v1beta1.DisruptionRecord{},
+		&v1beta1.InstanceTypeOverride{},
 	} {
 		for _, namespace := range namespaces.Items {
 			wg.Add(1)
@@ -524,6 +526,13 @@ func ExpectNodeClaims(ctx context.Context, c client.Client) []*v1beta1.NodeClaim
 	return lo.ToSlicePtr(nodeClaims.Items)
 }
 
+func ExpectDisruptionRecords(ctx context.Context, c client.Client) []*v1beta1.DisruptionRecord {
+	GinkgoHelper()
+	records := &v1beta1.DisruptionRecordList{}
+	Expect(c.List(ctx, records)).To(Succeed())
+	return lo.ToSlicePtr(records.Items)
+}
+
 func ExpectStateNodeExists(cluster *state.Cluster, node *v1.Node) *state.StateNode {
 	GinkgoHelper()
 	var ret *state.StateNode
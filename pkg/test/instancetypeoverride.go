@@ -0,0 +1,45 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"fmt"
+
+	"github.com/imdario/mergo"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+)
+
+// InstanceTypeOverride creates a test InstanceTypeOverride with defaults that can be overridden by overrides.
+// Overrides are applied in order, with a last write wins semantic.
+func InstanceTypeOverride(overrides ...v1beta1.InstanceTypeOverride) *v1beta1.InstanceTypeOverride {
+	override := v1beta1.InstanceTypeOverride{}
+	for _, opts := range overrides {
+		if err := mergo.Merge(&override, opts, mergo.WithOverride); err != nil {
+			panic(fmt.Sprintf("failed to merge: %v", err))
+		}
+	}
+	if override.Name == "" {
+		override.Name = RandomName()
+	}
+	if override.Spec.Requirements == nil {
+		override.Spec.Requirements = []v1.NodeSelectorRequirement{{Key: v1.LabelInstanceTypeStable, Operator: v1.NodeSelectorOpExists}}
+	}
+	return &v1beta1.InstanceTypeOverride{
+		ObjectMeta: ObjectMeta(override.ObjectMeta),
+		Spec:       override.Spec,
+	}
+}
@@ -20,31 +20,41 @@ import (
 
 	"github.com/imdario/mergo"
 	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/aws/karpenter-core/pkg/operator/options"
 )
 
 type OptionsFields struct {
 	// Vendor Neutral
-	ServiceName          *string
-	DisableWebhook       *bool
-	WebhookPort          *int
-	MetricsPort          *int
-	WebhookMetricsPort   *int
-	HealthProbePort      *int
-	KubeClientQPS        *int
-	KubeClientBurst      *int
-	EnableProfiling      *bool
-	EnableLeaderElection *bool
-	MemoryLimit          *int64
-	LogLevel             *string
-	BatchMaxDuration     *time.Duration
-	BatchIdleDuration    *time.Duration
-	FeatureGates         FeatureGates
+	ServiceName                  *string
+	DisableWebhook               *bool
+	WebhookPort                  *int
+	MetricsPort                  *int
+	WebhookMetricsPort           *int
+	HealthProbePort              *int
+	KubeClientQPS                *int
+	KubeClientBurst              *int
+	EnableProfiling              *bool
+	EnableLeaderElection         *bool
+	MemoryLimit                  *int64
+	LogLevel                     *string
+	BatchMaxDuration             *time.Duration
+	BatchIdleDuration            *time.Duration
+	MaxConcurrentDisruptions     *int
+	DisruptionDryRun             *bool
+	DisruptionRecordTTL          *time.Duration
+	NodeRepairConditions         []options.NodeRepairCondition
+	NodeRepairMaxPercentage      *int
+	BinPackingStrategy           *string
+	ExpendablePodsPriorityCutoff *int
+	FeatureGates                 FeatureGates
 }
 
 type FeatureGates struct {
-	Drift *bool
+	Drift                   *bool
+	SpotToSpotConsolidation *bool
+	NodeRepair              *bool
 }
 
 func Options(overrides ...OptionsFields) *options.Options {
@@ -56,22 +66,34 @@ func Options(overrides ...OptionsFields) *options.Options {
 	}
 
 	return &options.Options{
-		ServiceName:          lo.FromPtrOr(opts.ServiceName, ""),
-		DisableWebhook:       lo.FromPtrOr(opts.DisableWebhook, false),
-		WebhookPort:          lo.FromPtrOr(opts.WebhookPort, 8443),
-		MetricsPort:          lo.FromPtrOr(opts.MetricsPort, 8000),
-		WebhookMetricsPort:   lo.FromPtrOr(opts.WebhookMetricsPort, 8001),
-		HealthProbePort:      lo.FromPtrOr(opts.HealthProbePort, 8081),
-		KubeClientQPS:        lo.FromPtrOr(opts.KubeClientQPS, 200),
-		KubeClientBurst:      lo.FromPtrOr(opts.KubeClientBurst, 300),
-		EnableProfiling:      lo.FromPtrOr(opts.EnableProfiling, false),
-		EnableLeaderElection: lo.FromPtrOr(opts.EnableLeaderElection, true),
-		MemoryLimit:          lo.FromPtrOr(opts.MemoryLimit, -1),
-		LogLevel:             lo.FromPtrOr(opts.LogLevel, ""),
-		BatchMaxDuration:     lo.FromPtrOr(opts.BatchMaxDuration, 10*time.Second),
-		BatchIdleDuration:    lo.FromPtrOr(opts.BatchIdleDuration, time.Second),
+		ServiceName:              lo.FromPtrOr(opts.ServiceName, ""),
+		DisableWebhook:           lo.FromPtrOr(opts.DisableWebhook, false),
+		WebhookPort:              lo.FromPtrOr(opts.WebhookPort, 8443),
+		MetricsPort:              lo.FromPtrOr(opts.MetricsPort, 8000),
+		WebhookMetricsPort:       lo.FromPtrOr(opts.WebhookMetricsPort, 8001),
+		HealthProbePort:          lo.FromPtrOr(opts.HealthProbePort, 8081),
+		KubeClientQPS:            lo.FromPtrOr(opts.KubeClientQPS, 200),
+		KubeClientBurst:          lo.FromPtrOr(opts.KubeClientBurst, 300),
+		EnableProfiling:          lo.FromPtrOr(opts.EnableProfiling, false),
+		EnableLeaderElection:     lo.FromPtrOr(opts.EnableLeaderElection, true),
+		MemoryLimit:              lo.FromPtrOr(opts.MemoryLimit, -1),
+		LogLevel:                 lo.FromPtrOr(opts.LogLevel, ""),
+		BatchMaxDuration:         lo.FromPtrOr(opts.BatchMaxDuration, 10*time.Second),
+		BatchIdleDuration:        lo.FromPtrOr(opts.BatchIdleDuration, time.Second),
+		MaxConcurrentDisruptions: lo.FromPtrOr(opts.MaxConcurrentDisruptions, 10),
+		DisruptionDryRun:         lo.FromPtrOr(opts.DisruptionDryRun, false),
+		DisruptionRecordTTL:      lo.FromPtrOr(opts.DisruptionRecordTTL, 7*24*time.Hour),
+		NodeRepairConditions: lo.Ternary(opts.NodeRepairConditions != nil, opts.NodeRepairConditions, []options.NodeRepairCondition{
+			{Type: v1.NodeReady, Status: v1.ConditionFalse, TolerationDuration: 30 * time.Minute},
+			{Type: v1.NodeReady, Status: v1.ConditionUnknown, TolerationDuration: 30 * time.Minute},
+		}),
+		NodeRepairMaxPercentage:      lo.FromPtrOr(opts.NodeRepairMaxPercentage, 20),
+		BinPackingStrategy:           lo.FromPtrOr(opts.BinPackingStrategy, options.BinPackingStrategyFirstFitDecreasing),
+		ExpendablePodsPriorityCutoff: lo.FromPtrOr(opts.ExpendablePodsPriorityCutoff, -10),
 		FeatureGates: options.FeatureGates{
-			Drift: lo.FromPtrOr(opts.FeatureGates.Drift, false),
+			Drift:                   lo.FromPtrOr(opts.FeatureGates.Drift, false),
+			SpotToSpotConsolidation: lo.FromPtrOr(opts.FeatureGates.SpotToSpotConsolidation, false),
+			NodeRepair:              lo.FromPtrOr(opts.FeatureGates.NodeRepair, false),
 		},
 	}
 }
@@ -0,0 +1,92 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruptionrecord
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+)
+
+type TransitionOption func(*v1beta1.DisruptionRecordStatus)
+
+// WithMessage sets the message describing the outcome of the command
+func WithMessage(message string) TransitionOption {
+	return func(s *v1beta1.DisruptionRecordStatus) {
+		s.Message = message
+	}
+}
+
+// WithReplacementNodeClaims sets the names of the NodeClaims that were created for the command's replacements
+func WithReplacementNodeClaims(names ...string) TransitionOption {
+	return func(s *v1beta1.DisruptionRecordStatus) {
+		s.ReplacementNodeClaims = names
+	}
+}
+
+// Create creates the DisruptionRecord and transitions it to its initial phase
+func Create(ctx context.Context, c client.Client, record *v1beta1.DisruptionRecord, phase v1beta1.DisruptionPhase, now time.Time) error {
+	if err := c.Create(ctx, record); err != nil {
+		return err
+	}
+	if err := Transition(ctx, c, record.Name, phase, now); err != nil {
+		return fmt.Errorf("transitioning to %s, %w", phase, err)
+	}
+	return nil
+}
+
+// Transition moves the DisruptionRecord into the passed phase and records the time of the transition. Records that
+// are already in the phase or have finished aren't transitioned.
+func Transition(ctx context.Context, c client.Client, name string, phase v1beta1.DisruptionPhase, now time.Time, opts ...TransitionOption) error {
+	record, err := Get(ctx, c, name)
+	if err != nil {
+		return err
+	}
+	if record.Status.Phase == phase || record.IsFinished() {
+		return nil
+	}
+	stored := record.DeepCopy()
+	record.Status.Phase = phase
+	record.Status.PhaseTransitions = append(record.Status.PhaseTransitions, v1beta1.DisruptionPhaseTransition{
+		Phase: phase,
+		Time:  metav1.NewTime(now),
+	})
+	for _, opt := range opts {
+		opt(&record.Status)
+	}
+	return c.Status().Patch(ctx, record, client.MergeFrom(stored))
+}
+
+func Get(ctx context.Context, c client.Client, name string) (*v1beta1.DisruptionRecord, error) {
+	record := &v1beta1.DisruptionRecord{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func List(ctx context.Context, c client.Client, opts ...client.ListOption) (*v1beta1.DisruptionRecordList, error) {
+	recordList := &v1beta1.DisruptionRecordList{}
+	if err := c.List(ctx, recordList, opts...); err != nil {
+		return nil, err
+	}
+	return recordList, nil
+}
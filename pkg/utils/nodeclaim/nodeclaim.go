@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/samber/lo"
@@ -30,6 +31,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/aws/karpenter-core/pkg/apis/v1beta1"
+	"github.com/aws/karpenter-core/pkg/cloudprovider"
 	"github.com/aws/karpenter-core/pkg/metrics"
 	"github.com/aws/karpenter-core/pkg/scheduling"
 )
@@ -170,6 +172,35 @@ func AllNodesForNodeClaim(ctx context.Context, c client.Client, nodeClaim *v1bet
 	return lo.ToSlicePtr(nodeList.Items), nil
 }
 
+// ExpirationTime returns the time that a NodeClaim expires based on the ExpireAfter of its NodePool. It returns false
+// if the NodePool doesn't expire its NodeClaims.
+func ExpirationTime(nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim, node *v1.Node) (time.Time, bool) {
+	if nodePool.Spec.Disruption.ExpireAfter.Duration == nil {
+		return time.Time{}, false
+	}
+	// We do the expiration check in this way since there is still a migration path for creating Machines from Nodes
+	// In this case, we need to make sure that we take the older of the two for expiration
+	// TODO @joinnis: This check that takes the minimum between the Node and Machine CreationTimestamps can be removed
+	// once machine migration is ripped out, which should happen when apis and Karpenter are promoted to v1
+	if node == nil || nodeClaim.CreationTimestamp.Before(&node.CreationTimestamp) {
+		return nodeClaim.CreationTimestamp.Add(*nodePool.Spec.Disruption.ExpireAfter.Duration), true
+	}
+	return node.CreationTimestamp.Add(*nodePool.Spec.Disruption.ExpireAfter.Duration), true
+}
+
+// TerminationTime returns the time by which an expired NodeClaim must be terminated, regardless of PodDisruptionBudgets
+// or do-not-disrupt pods. It returns false if the NodePool doesn't set a TerminationGracePeriod or doesn't expire its NodeClaims.
+func TerminationTime(nodePool *v1beta1.NodePool, nodeClaim *v1beta1.NodeClaim, node *v1.Node) (time.Time, bool) {
+	if nodePool.Spec.Disruption.TerminationGracePeriod == nil {
+		return time.Time{}, false
+	}
+	expirationTime, ok := ExpirationTime(nodePool, nodeClaim, node)
+	if !ok {
+		return time.Time{}, false
+	}
+	return expirationTime.Add(nodePool.Spec.Disruption.TerminationGracePeriod.Duration), true
+}
+
 // NewFromNode converts a node into a pseudo-NodeClaim using known values from the node
 // Deprecated: This NodeClaim generator function can be removed when v1beta1 migration has completed.
 func NewFromNode(node *v1.Node) *v1beta1.NodeClaim {
@@ -273,9 +304,10 @@ func DisruptedCounter(nodeClaim *v1beta1.NodeClaim, disruptionType string) prome
 	})
 }
 
-func DriftedCounter(nodeClaim *v1beta1.NodeClaim, driftType string) prometheus.Counter {
-	return metrics.NodeClaimsDisruptedCounter.With(prometheus.Labels{
-		metrics.TypeLabel:     driftType,
+func DriftedCounter(nodeClaim *v1beta1.NodeClaim, drift *cloudprovider.Drift) prometheus.Counter {
+	return metrics.NodeClaimsDriftedCounter.With(prometheus.Labels{
+		metrics.TypeLabel:     string(drift.Reason),
+		metrics.FieldLabel:    drift.Field,
 		metrics.NodePoolLabel: nodeClaim.Labels[v1beta1.NodePoolLabelKey],
 	})
 }
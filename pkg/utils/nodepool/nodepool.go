@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -40,9 +41,11 @@ func New(provisioner *v1alpha5.Provisioner) *v1beta1.NodePool {
 				Spec: v1beta1.NodeClaimSpec{
 					Taints:        provisioner.Spec.Taints,
 					StartupTaints: provisioner.Spec.StartupTaints,
-					Requirements:  provisioner.Spec.Requirements,
-					Kubelet:       NewKubeletConfiguration(provisioner.Spec.KubeletConfiguration),
-					NodeClassRef:  NewNodeClassReference(provisioner.Spec.ProviderRef),
+					Requirements: lo.Map(provisioner.Spec.Requirements, func(r v1.NodeSelectorRequirement, _ int) v1beta1.NodeSelectorRequirementWithMinValues {
+						return v1beta1.NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: r}
+					}),
+					Kubelet:      NewKubeletConfiguration(provisioner.Spec.KubeletConfiguration),
+					NodeClassRef: NewNodeClassReference(provisioner.Spec.ProviderRef),
 				},
 			},
 			Weight: provisioner.Spec.Weight,
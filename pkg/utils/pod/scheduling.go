@@ -48,6 +48,13 @@ func IsPreempting(pod *v1.Pod) bool {
 	return pod.Status.NominatedNodeName != ""
 }
 
+// IsHeadroom returns true if the pod is a virtual pod that represents the headroom of a NodePool. These pods are only
+// scheduled in memory and never exist in the cluster.
+func IsHeadroom(pod *v1.Pod) bool {
+	_, ok := pod.Annotations[v1beta1.HeadroomAnnotationKey]
+	return ok
+}
+
 func IsTerminal(pod *v1.Pod) bool {
 	return pod.Status.Phase == v1.PodFailed || pod.Status.Phase == v1.PodSucceeded
 }
@@ -15,6 +15,7 @@ limitations under the License.
 package resources
 
 import (
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 
@@ -93,23 +94,59 @@ func Subtract(lhs, rhs v1.ResourceList) v1.ResourceList {
 	return result
 }
 
-// Ceiling calculates the max between the sum of container resources and max of initContainers
+// Ceiling calculates the resources of the pod the same way that kube-scheduler does. The containers run alongside the
+// sidecars, which are init containers that are always restarted, so their resources are summed. The other init
+// containers run one at a time before the containers, alongside the sidecars that were started before them, so the pod
+// needs the max of the two. The pod overhead of the RuntimeClass is added to the requests and to the limits that are set.
 func Ceiling(pod *v1.Pod) v1.ResourceRequirements {
 	var resources v1.ResourceRequirements
 	for _, container := range pod.Spec.Containers {
-		resources.Requests = MergeInto(resources.Requests, MergeResourceLimitsIntoRequests(container))
+		resources.Requests = MergeInto(resources.Requests, containerRequests(pod, container))
 		resources.Limits = MergeInto(resources.Limits, container.Resources.Limits)
 	}
+	var initRequests, initLimits, sidecarRequests, sidecarLimits v1.ResourceList
 	for _, container := range pod.Spec.InitContainers {
-		resources.Requests = MaxResources(resources.Requests, MergeResourceLimitsIntoRequests(container))
-		resources.Limits = MaxResources(resources.Limits, container.Resources.Limits)
+		requests, limits := MergeResourceLimitsIntoRequests(container), container.Resources.Limits
+		if lo.FromPtr(container.RestartPolicy) == v1.ContainerRestartPolicyAlways {
+			resources.Requests = MergeInto(resources.Requests, requests)
+			resources.Limits = MergeInto(resources.Limits, limits)
+			sidecarRequests = Merge(sidecarRequests, requests)
+			sidecarLimits = Merge(sidecarLimits, limits)
+			requests, limits = sidecarRequests, sidecarLimits
+		} else {
+			requests, limits = Merge(requests, sidecarRequests), Merge(limits, sidecarLimits)
+		}
+		initRequests = MaxResources(initRequests, requests)
+		initLimits = MaxResources(initLimits, limits)
+	}
+	if len(pod.Spec.InitContainers) > 0 {
+		resources.Requests = MaxResources(resources.Requests, initRequests)
+		resources.Limits = MaxResources(resources.Limits, initLimits)
 	}
-	if pod.Spec.Overhead != nil {
-		resources.Requests = MergeInto(resources.Requests, pod.Spec.Overhead)
+	for resourceName, quantity := range pod.Spec.Overhead {
+		resources.Requests = MergeInto(resources.Requests, v1.ResourceList{resourceName: quantity})
+		if _, ok := resources.Limits[resourceName]; ok {
+			resources.Limits = MergeInto(resources.Limits, v1.ResourceList{resourceName: quantity})
+		}
 	}
 	return resources
 }
 
+// containerRequests returns the requests of the container. While the container is resized in place, kubelet keeps the
+// resources that it allocated to the container until the resize completes, so the pod needs the max of the two. If the
+// resize is infeasible, it won't complete, so only the allocated resources count.
+func containerRequests(pod *v1.Pod, container v1.Container) v1.ResourceList {
+	requests := MergeResourceLimitsIntoRequests(container)
+	status, ok := lo.Find(pod.Status.ContainerStatuses, func(s v1.ContainerStatus) bool { return s.Name == container.Name })
+	if !ok || status.AllocatedResources == nil {
+		return requests
+	}
+	if pod.Status.Resize == v1.PodResizeStatusInfeasible {
+		return status.AllocatedResources.DeepCopy()
+	}
+	return MaxResources(requests, status.AllocatedResources)
+}
+
 // MaxResources returns the maximum quantities for a given list of resources
 func MaxResources(resources ...v1.ResourceList) v1.ResourceList {
 	resourceList := v1.ResourceList{}
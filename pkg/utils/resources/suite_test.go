@@ -0,0 +1,149 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/aws/karpenter-core/pkg/utils/resources"
+)
+
+func TestResources(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Resources")
+}
+
+func container(name, cpu string) v1.Container {
+	return v1.Container{Name: name, Resources: v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+	}}
+}
+
+func sidecar(name, cpu string) v1.Container {
+	c := container(name, cpu)
+	c.RestartPolicy = lo.ToPtr(v1.ContainerRestartPolicyAlways)
+	return c
+}
+
+func cpu(list v1.ResourceList) string {
+	return list.Cpu().String()
+}
+
+var _ = Describe("Ceiling", func() {
+	It("should use the max of the sum of the containers and the largest init container", func() {
+		pod := &v1.Pod{Spec: v1.PodSpec{
+			Containers:     []v1.Container{container("a", "1"), container("b", "1")},
+			InitContainers: []v1.Container{container("init-a", "3"), container("init-b", "1")},
+		}}
+		Expect(cpu(resources.Ceiling(pod).Requests)).To(Equal("3"))
+	})
+	It("should use requests from limits if requests aren't set", func() {
+		pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "a", Resources: v1.ResourceRequirements{
+			Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+		}}}}}
+		Expect(cpu(resources.Ceiling(pod).Requests)).To(Equal("2"))
+		Expect(cpu(resources.Ceiling(pod).Limits)).To(Equal("2"))
+	})
+	It("should add sidecars to the containers", func() {
+		pod := &v1.Pod{Spec: v1.PodSpec{
+			Containers:     []v1.Container{container("a", "1")},
+			InitContainers: []v1.Container{sidecar("sidecar", "500m")},
+		}}
+		Expect(cpu(resources.Ceiling(pod).Requests)).To(Equal("1500m"))
+	})
+	It("should add the sidecars that started before an init container to the init container", func() {
+		pod := &v1.Pod{Spec: v1.PodSpec{
+			Containers: []v1.Container{container("a", "1")},
+			InitContainers: []v1.Container{
+				container("init-a", "1"),
+				sidecar("sidecar", "1"),
+				container("init-b", "2"),
+			},
+		}}
+		// init-b runs alongside the sidecar, which needs more than the containers and the sidecar
+		Expect(cpu(resources.Ceiling(pod).Requests)).To(Equal("3"))
+	})
+	It("should add the pod overhead to the requests and to the limits that are set", func() {
+		pod := &v1.Pod{Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "a", Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+				Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			}}},
+			Overhead: v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m"), v1.ResourceMemory: resource.MustParse("128Mi")},
+		}}
+		ceiling := resources.Ceiling(pod)
+		Expect(ceiling.Requests.Cpu().String()).To(Equal("1250m"))
+		Expect(ceiling.Requests.Memory().String()).To(Equal("1152Mi"))
+		Expect(ceiling.Limits.Cpu().String()).To(Equal("2250m"))
+		Expect(ceiling.Limits).ToNot(HaveKey(v1.ResourceMemory))
+	})
+	It("should use the allocated resources of a container that is resized to fewer resources", func() {
+		pod := &v1.Pod{
+			Spec: v1.PodSpec{Containers: []v1.Container{container("a", "1")}},
+			Status: v1.PodStatus{
+				Resize: v1.PodResizeStatusInProgress,
+				ContainerStatuses: []v1.ContainerStatus{{
+					Name:               "a",
+					AllocatedResources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")},
+				}},
+			},
+		}
+		Expect(cpu(resources.Ceiling(pod).Requests)).To(Equal("3"))
+	})
+	It("should use the requests of a container that is resized to more resources", func() {
+		pod := &v1.Pod{
+			Spec: v1.PodSpec{Containers: []v1.Container{container("a", "3")}},
+			Status: v1.PodStatus{
+				Resize: v1.PodResizeStatusProposed,
+				ContainerStatuses: []v1.ContainerStatus{{
+					Name:               "a",
+					AllocatedResources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				}},
+			},
+		}
+		Expect(cpu(resources.Ceiling(pod).Requests)).To(Equal("3"))
+	})
+	It("should only use the allocated resources if the resize is infeasible", func() {
+		pod := &v1.Pod{
+			Spec: v1.PodSpec{Containers: []v1.Container{container("a", "8")}},
+			Status: v1.PodStatus{
+				Resize: v1.PodResizeStatusInfeasible,
+				ContainerStatuses: []v1.ContainerStatus{{
+					Name:               "a",
+					AllocatedResources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				}},
+			},
+		}
+		Expect(cpu(resources.Ceiling(pod).Requests)).To(Equal("1"))
+	})
+})
+
+var _ = Describe("RequestsForPods", func() {
+	It("should sum the requests of the pods and count them", func() {
+		pods := []*v1.Pod{
+			{Spec: v1.PodSpec{Containers: []v1.Container{container("a", "1")}, Overhead: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")}}},
+			{Spec: v1.PodSpec{Containers: []v1.Container{container("a", "1")}, InitContainers: []v1.Container{sidecar("sidecar", "1")}}},
+		}
+		requests := resources.RequestsForPods(pods...)
+		Expect(requests.Cpu().String()).To(Equal("3500m"))
+		Expect(requests.Pods().String()).To(Equal("2"))
+	})
+})